@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"runtime"
@@ -255,13 +256,28 @@ func (t *Type) ListenAndServe() error {
 		"Listening for HTTP requests at: %v\n",
 		"http://"+t.conf.Address,
 	)
+	addr := t.conf.Address
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return t.Serve(ln)
+}
+
+// Serve accepts connections on a listener and serves the API over them,
+// blocking until the server closes or fails. When TLS is configured the API is
+// served over HTTPS, where clients that support HTTP/2 negotiate it via ALPN.
+func (t *Type) Serve(ln net.Listener) error {
 	if t.server.TLSConfig != nil {
-		return t.server.ListenAndServeTLS("", "")
+		return t.server.ServeTLS(ln, "", "")
 	}
 	if t.conf.CertFile != "" {
-		return t.server.ListenAndServeTLS(t.conf.CertFile, t.conf.KeyFile)
+		return t.server.ServeTLS(ln, t.conf.CertFile, t.conf.KeyFile)
 	}
-	return t.server.ListenAndServe()
+	return t.server.Serve(ln)
 }
 
 // Shutdown attempts to close the http server.
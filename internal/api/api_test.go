@@ -1,9 +1,15 @@
 package api_test
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -183,3 +189,50 @@ func TestAPIBasicAuth(t *testing.T) {
 		}(tc))
 	}
 }
+
+func TestAPIServeHTTP2OverTLS(t *testing.T) {
+	// Borrow the certificate of a test server, which is valid for 127.0.0.1.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(certServer.Certificate())
+
+	conf := api.NewConfig()
+	s, err := api.New("", "", conf, nil, log.Noop(), metrics.Noop(), api.OptWithTLS(&tls.Config{
+		Certificates: certServer.TLS.Certificates,
+	}))
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Serve(ln)
+	}()
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+		defer done()
+		require.NoError(t, s.Shutdown(ctx))
+		assert.ErrorIs(t, <-serveErr, http.ErrServerClosed)
+	})
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: rootCAs},
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	res, err := client.Get("https://" + ln.Addr().String() + "/ping")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 2, res.ProtoMajor)
+	assert.Equal(t, "pong", string(body))
+}
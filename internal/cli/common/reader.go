@@ -29,6 +29,18 @@ func ReadConfig(c *cli.Context, cliOpts *CLIOpts, streamsMode bool) (mainPath st
 	}
 	if streamsMode {
 		opts = append(opts, config.OptSetStreamPaths(c.Args().Slice()...))
+		if anchorsPath := c.String("anchors"); anchorsPath != "" {
+			opts = append(opts, config.OptSetStreamAnchorsPath(anchorsPath))
+		}
+		if pattern := c.String("glob"); pattern != "" {
+			opts = append(opts, config.OptSetStreamFileGlob(pattern))
+		}
+		if c.IsSet("id-separator") {
+			opts = append(opts, config.OptSetStreamIDSeparator(c.String("id-separator")))
+		}
+		if strategy := c.String("id-collisions"); strategy != "" {
+			opts = append(opts, config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionStrategy(strategy)))
+		}
 	}
 	return path, inferred, config.NewReader(path, c.StringSlice("resources"), opts...)
 }
@@ -78,7 +78,12 @@ func RunService(c *cli.Context, cliOpts *CLIOpts, streamsMode bool) int {
 	watching := c.Bool("watcher")
 	if streamsMode {
 		enableStreamsAPI := !c.Bool("no-api")
-		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager())
+		bootMode, err := strmmgr.ParseBootMode(c.String("boot-mode"))
+		if err != nil {
+			logger.Error(err.Error())
+			return 1
+		}
+		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, bootMode, confReader, stoppableManager.Manager())
 	} else {
 		stoppableStream, dataStreamClosedChan = initNormalMode(cliOpts, conf, strict, watching, confReader, stoppableManager.Manager())
 	}
@@ -113,18 +118,31 @@ func DelayShutdown(ctx context.Context, duration time.Duration) error {
 func initStreamsMode(
 	opts *CLIOpts,
 	strict, watching, enableAPI bool,
+	bootMode strmmgr.BootMode,
 	confReader *config.Reader,
 	mgr *manager.Type,
 ) Stoppable {
 	logger := mgr.Logger()
-	streamMgr := strmmgr.New(mgr, strmmgr.OptAPIEnabled(enableAPI))
-
+	streamMgr := strmmgr.New(mgr,
+		strmmgr.OptAPIEnabled(enableAPI),
+		strmmgr.OptSetBuildInfo(opts.Version, opts.DateBuilt),
+		strmmgr.OptSetStreamReaderOpts(confReader.StreamOpts()...),
+	)
+
+	// Stream configs are read leniently in both boot modes in order to report
+	// every config that fails to load at once.
 	streamConfs := map[string]stream.Config{}
-	lints, err := confReader.ReadStreams(streamConfs)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Stream configuration file read error: %v\n", err)
+	lints, loadErr := confReader.ReadStreamsLenient(streamConfs)
+	if loadErr != nil && bootMode == strmmgr.BootModeStrict {
+		fmt.Fprintf(os.Stderr, "Stream configuration file read error: %v\n", loadErr)
 		os.Exit(1)
 	}
+	for path, id := range confReader.ResolvedStreamIDs() {
+		logger.With("path", path, "id", id).Info("Resolved stream ID collision")
+	}
+	for path, by := range confReader.OverriddenStreamPaths() {
+		logger.With("path", path, "overridden_by", by).Info("Stream config overridden")
+	}
 
 	for _, lint := range lints {
 		if strict {
@@ -138,11 +156,15 @@ func initStreamsMode(
 		os.Exit(1)
 	}
 
-	for id, conf := range streamConfs {
-		if err := streamMgr.Create(id, conf); err != nil {
-			logger.Error("Failed to create stream (%v): %v\n", id, err)
+	streamPaths := confReader.StreamConfigPaths()
+	if err := streamMgr.Boot(context.Background(), bootMode, streamConfs, loadErr, func(id string) []strmmgr.StreamOpt {
+		return []strmmgr.StreamOpt{strmmgr.StreamOptOriginDirectory(streamPaths[id])}
+	}); err != nil {
+		if bootMode == strmmgr.BootModeStrict {
+			logger.Error("Shutting down as streams failed to boot: %v\n", err)
 			os.Exit(1)
 		}
+		logger.Error("Continuing with the streams that booted successfully: %v\n", err)
 	}
 	logger.Info(opts.ExecTemplate("Launching {{.ProductName}} in streams mode, use CTRL+C to close"))
 
@@ -152,8 +174,9 @@ func initStreamsMode(
 
 		var updateErr error
 		if newStreamConf != nil {
-			if updateErr = streamMgr.Update(ctx, id, *newStreamConf); updateErr != nil && errors.Is(updateErr, strmmgr.ErrStreamDoesNotExist) {
-				updateErr = streamMgr.Create(id, *newStreamConf)
+			origin := strmmgr.StreamOptOriginDirectory(confReader.StreamConfigPaths()[id])
+			if updateErr = streamMgr.Update(ctx, id, *newStreamConf, origin); updateErr != nil && errors.Is(updateErr, strmmgr.ErrStreamDoesNotExist) {
+				updateErr = streamMgr.Create(id, *newStreamConf, origin)
 			}
 		} else {
 			if updateErr = streamMgr.Delete(ctx, id); updateErr != nil && errors.Is(updateErr, strmmgr.ErrStreamDoesNotExist) {
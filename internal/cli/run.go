@@ -327,6 +327,31 @@ For more information check out the docs at:
 						Value: false,
 						Usage: "Disable the HTTP API for streams mode",
 					},
+					&cli.StringFlag{
+						Name:  "anchors",
+						Value: "",
+						Usage: "A path to a YAML file of shared anchors that may be referenced by aliases within any stream config",
+					},
+					&cli.StringFlag{
+						Name:  "id-collisions",
+						Value: "error",
+						Usage: "How to treat stream config files that are given the same stream ID, one of error, suffix (append a numeric suffix), parent_dir (prefix the name of the parent directory) or override (files from later paths replace those from earlier paths, whereas collisions within a path are rejected)",
+					},
+					&cli.StringFlag{
+						Name:  "glob",
+						Value: "",
+						Usage: "A glob pattern that the base filenames of stream configs found within directories must match in order to be loaded, such as prod-*.yaml",
+					},
+					&cli.StringFlag{
+						Name:  "id-separator",
+						Value: "_",
+						Usage: "The string that replaces path separators when inferring the IDs of stream configs nested within sub-directories",
+					},
+					&cli.StringFlag{
+						Name:  "boot-mode",
+						Value: "strict",
+						Usage: "How to treat stream configs that fail to load at boot, either strict (shut down, reporting every failure) or lenient (run the streams that load successfully, logging those that failed)",
+					},
 					&cli.BoolFlag{
 						Name:  "prefix-stream-endpoints",
 						Value: true,
@@ -30,3 +30,23 @@ type Streamed interface {
 	// shutting down and cleaning up resources.
 	WaitForClose(ctx context.Context) error
 }
+
+// Depth describes how full a buffer is at a point in time.
+type Depth struct {
+	// The number of messages within the buffer, including those that have
+	// been read but not yet acknowledged.
+	Messages int
+
+	// The size in bytes of the messages within the buffer.
+	Bytes int
+
+	// Whether writes to the buffer are blocked as it has reached capacity.
+	Backpressure bool
+}
+
+// DepthReporter is implemented by buffers capable of reporting how full they
+// are. The bool returned is false if the buffer is unable to report its depth,
+// which allows wrappers of buffers to implement the interface on their behalf.
+type DepthReporter interface {
+	BufferDepth() (Depth, bool)
+}
@@ -83,6 +83,15 @@ func NewStream(typeStr string, buffer ReaderWriter, mgr component.Observability)
 	return &m
 }
 
+// BufferDepth returns the depth of the underlying buffer implementation when
+// it implements DepthReporter.
+func (m *Stream) BufferDepth() (Depth, bool) {
+	if dr, ok := m.buffer.(DepthReporter); ok {
+		return dr.BufferDepth()
+	}
+	return Depth{}, false
+}
+
 //------------------------------------------------------------------------------
 
 // inputLoop is an internal loop that brokers incoming messages to the buffer.
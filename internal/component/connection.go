@@ -67,3 +67,19 @@ func ConnectionClosed(o Observability) *ConnectionStatus {
 		Connected: false,
 	}
 }
+
+// ConnectionAddresser is an optional interface that can be implemented by
+// components in order to report the addresses or endpoints of the services
+// that they are connected to.
+type ConnectionAddresser interface {
+	ConnectionAddresses() []string
+}
+
+// ConnectionAddressesOf returns the connection addresses reported by a
+// component when it implements ConnectionAddresser, otherwise nil is returned.
+func ConnectionAddressesOf(c any) []string {
+	if a, ok := c.(ConnectionAddresser); ok {
+		return a.ConnectionAddresses()
+	}
+	return nil
+}
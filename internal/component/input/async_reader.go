@@ -251,6 +251,11 @@ func (r *AsyncReader) ConnectionStatus() component.ConnectionStatuses {
 	}
 }
 
+// ConnectionAddresses returns the addresses reported by the wrapped reader.
+func (r *AsyncReader) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(r.reader)
+}
+
 // TriggerStopConsuming instructs the input to start shutting down resources
 // once all pending messages are delivered and acknowledged. This call does
 // not block.
@@ -58,6 +58,11 @@ func (i *WithPipeline) ConnectionStatus() component.ConnectionStatuses {
 	return i.in.ConnectionStatus()
 }
 
+// ConnectionAddresses returns the addresses reported by the wrapped component.
+func (i *WithPipeline) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(i.in)
+}
+
 //------------------------------------------------------------------------------
 
 // TriggerStopConsuming instructs the input to start shutting down resources
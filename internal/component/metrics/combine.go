@@ -183,6 +183,11 @@ func (c *combinedWrapper) GetGaugeVec(path string, n ...string) StatGaugeVec {
 	}
 }
 
+func (c *combinedWrapper) DeleteLabel(name, value string) {
+	_ = DeleteLabel(c.t1, name, value)
+	_ = DeleteLabel(c.t2, name, value)
+}
+
 func (c *combinedWrapper) HandlerFunc() http.HandlerFunc {
 	if h := c.t1.HandlerFunc(); h != nil {
 		return h
@@ -263,6 +263,33 @@ func (l *Local) GetGaugeVec(path string, k ...string) StatGaugeVec {
 	})
 }
 
+// DeleteLabel removes all counters, gauges and timings where the label of the
+// given name has the given value.
+func (l *Local) DeleteLabel(name, value string) {
+	hasLabel := func(path string) bool {
+		_, tagNames, tagValues := ReverseLabelledPath(path)
+		for i, k := range tagNames {
+			if k == name && tagValues[i] == value {
+				return true
+			}
+		}
+		return false
+	}
+
+	l.mut.Lock()
+	for k := range l.flatCounters {
+		if hasLabel(k) {
+			delete(l.flatCounters, k)
+		}
+	}
+	for k := range l.flatTimings {
+		if hasLabel(k) {
+			delete(l.flatTimings, k)
+		}
+	}
+	l.mut.Unlock()
+}
+
 // HandlerFunc returns nil.
 func (l *Local) HandlerFunc() http.HandlerFunc {
 	return nil
@@ -90,6 +90,26 @@ func TestCounter(t *testing.T) {
 	assert.Equal(t, expTimingAvgs, actTimingAvgs)
 }
 
+func TestLocalDeleteLabel(t *testing.T) {
+	nm := NewLocal()
+
+	nm.GetCounterVec("counterone", "stream").With("foo").Incr(1)
+	nm.GetCounterVec("counterone", "stream").With("bar").Incr(2)
+	nm.GetGaugeVec("gaugeone", "stream", "other").With("foo", "baz").Set(3)
+	nm.GetTimerVec("timerone", "stream").With("foo").Timing(4)
+	nm.GetTimerVec("timerone", "other").With("foo").Timing(5)
+
+	assert.True(t, DeleteLabel(nm, "stream", "foo"))
+
+	assert.Equal(t, map[string]int64{
+		`counterone{stream="bar"}`: 2,
+	}, nm.GetCounters())
+
+	timings := nm.GetTimings()
+	assert.Len(t, timings, 1)
+	assert.Contains(t, timings, `timerone{other="foo"}`)
+}
+
 func TestReverseName(t *testing.T) {
 	tests := map[string]struct {
 		input     string
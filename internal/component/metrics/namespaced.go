@@ -64,6 +64,12 @@ func (n *Namespaced) WithMapping(m *Mapping) *Namespaced {
 
 //------------------------------------------------------------------------------
 
+// DeleteLabel removes all series of the child metrics type where the label of
+// the given name has the given value, when supported by the child.
+func (n *Namespaced) DeleteLabel(name, value string) {
+	_ = DeleteLabel(n.child, name, value)
+}
+
 // Child returns the underlying metrics type.
 func (n *Namespaced) Child() Type {
 	return n.child
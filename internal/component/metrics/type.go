@@ -100,3 +100,26 @@ type Type interface {
 	// Close stops aggregating stats and cleans up resources.
 	Close() error
 }
+
+//------------------------------------------------------------------------------
+
+// LabelDeleter is an optional interface implemented by metrics types that are
+// capable of removing all series carrying a label of a given value, allowing
+// the metrics of a component that no longer exists to be cleaned up.
+type LabelDeleter interface {
+	// DeleteLabel removes all series where the label of the given name has
+	// the given value.
+	DeleteLabel(name, value string)
+}
+
+// DeleteLabel removes all series of a metrics type where the label of the
+// given name has the given value. Returns false when the metrics type does not
+// support the removal of series, in which case the series are left untouched.
+func DeleteLabel(t Type, name, value string) bool {
+	d, ok := t.(LabelDeleter)
+	if !ok {
+		return false
+	}
+	d.DeleteLabel(name, value)
+	return true
+}
@@ -266,6 +266,11 @@ func (w *AsyncWriter) ConnectionStatus() component.ConnectionStatuses {
 	}
 }
 
+// ConnectionAddresses returns the addresses reported by the wrapped writer.
+func (w *AsyncWriter) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(w.writer)
+}
+
 // TriggerCloseNow shuts down the output and stops processing messages.
 func (w *AsyncWriter) TriggerCloseNow() {
 	w.shutSig.TriggerHardStop()
@@ -152,6 +152,10 @@ func (n *notBatchedOutput) ConnectionStatus() component.ConnectionStatuses {
 	return n.out.ConnectionStatus()
 }
 
+func (n *notBatchedOutput) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(n.out)
+}
+
 func (n *notBatchedOutput) TriggerCloseNow() {
 	n.shutSig.TriggerHardStop()
 }
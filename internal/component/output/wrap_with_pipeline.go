@@ -59,6 +59,11 @@ func (i *WithPipeline) ConnectionStatus() component.ConnectionStatuses {
 	return i.out.ConnectionStatus()
 }
 
+// ConnectionAddresses returns the addresses reported by the wrapped component.
+func (i *WithPipeline) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(i.out)
+}
+
 //------------------------------------------------------------------------------
 
 // TriggerCloseNow triggers a closure of this object but does not block.
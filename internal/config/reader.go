@@ -17,6 +17,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/docs"
 	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/log"
 	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/stream"
 )
@@ -29,6 +30,11 @@ const (
 
 type streamFileInfo struct {
 	id string
+
+	// The directory that was targeted in order to find the file, which is
+	// either the directory walked or, when the file was targeted directly,
+	// the directory containing it.
+	parent string
 }
 
 type fileWatcher interface {
@@ -55,10 +61,21 @@ type Reader struct {
 	// Used for linting configs
 	lintConf docs.LintConfig
 
-	mainPath      string
-	resourcePaths []string
-	streamsPaths  []string
-	overrides     []string
+	mainPath          string
+	resourcePaths     []string
+	streamsPaths      []string
+	streamAnchorsPath string
+	streamFileGlob    string
+	overrides         []string
+
+	// Determines how stream ids are inferred from the paths of stream files.
+	streamIDSeparator string
+	streamIDFn        func(relPath string) (string, error)
+
+	// Determines how stream files given the same inferred id are treated.
+	streamIDCollisions    StreamIDCollisionStrategy
+	resolvedStreamIDs     map[string]string
+	overriddenStreamPaths map[string]string
 
 	modTimeLastRead map[string]time.Time
 
@@ -84,6 +101,8 @@ type Reader struct {
 	changeFlushPeriod  time.Duration
 	changeDelayPeriod  time.Duration
 	filesRefreshPeriod time.Duration
+
+	logger log.Modular
 }
 
 // NewReader creates a new config reader.
@@ -93,17 +112,21 @@ func NewReader(mainPath string, resourcePaths []string, opts ...OptFunc) *Reader
 	}
 	r := &Reader{
 		testSuffix:         "_bento_test",
+		streamIDSeparator:  "_",
 		fs:                 ifs.OS(),
 		lintConf:           docs.NewLintConfig(bundle.GlobalEnvironment),
 		mainPath:           mainPath,
 		resourcePaths:      resourcePaths,
 		modTimeLastRead:    map[string]time.Time{},
 		streamFileInfo:     map[string]streamFileInfo{},
+		streamIDCollisions: StreamIDCollisionError,
+		resolvedStreamIDs:  map[string]string{},
 		resourceFileInfo:   map[string]resourceFileInfo{},
 		resourceSources:    newResourceSourceInfo(),
 		changeFlushPeriod:  defaultChangeFlushPeriod,
 		changeDelayPeriod:  defaultChangeDelayPeriod,
 		filesRefreshPeriod: defaultFilesRefreshPeriod,
+		logger:             log.Noop(),
 
 		specFullConfig:    Spec(),
 		specStreamOnly:    stream.Spec(),
@@ -163,6 +186,68 @@ func OptSetStreamPaths(streamsPaths ...string) OptFunc {
 	}
 }
 
+// OptSetStreamAnchorsPath sets the path of a shared YAML file that is made
+// available to every stream config file as a preamble, allowing anchors that
+// are defined within it once to be referenced by aliases from any stream
+// config. The top level fields of the shared file are not themselves included
+// in stream configs, and a stream config that redefines an anchor from the
+// shared file is rejected.
+func OptSetStreamAnchorsPath(path string) OptFunc {
+	return func(r *Reader) {
+		r.streamAnchorsPath = filepath.Clean(path)
+	}
+}
+
+// OptSetStreamFileGlob restricts the stream config files that are read from
+// within directories to those with a base filename matching a glob pattern,
+// such as `prod-*.yaml`, with other files being skipped. Files targeted directly
+// by a stream path are read regardless of the pattern. The ids of matched files
+// are inferred as usual.
+func OptSetStreamFileGlob(pattern string) OptFunc {
+	return func(r *Reader) {
+		r.streamFileGlob = pattern
+	}
+}
+
+// OptSetStreamIDSeparator sets the string that replaces the path separators of
+// stream config files nested within sub-directories in order to infer their
+// stream ids, which is an underscore by default. For example, with a separator
+// of `.` the file `foo/bar.yaml` is given the id `foo.bar`.
+func OptSetStreamIDSeparator(sep string) OptFunc {
+	return func(r *Reader) {
+		r.streamIDSeparator = sep
+	}
+}
+
+// OptSetStreamIDFunc sets a func that computes the stream id of a config file
+// from its path, relative to the directory it was found within, in place of
+// the default inference. The relative path includes the file extension, and is
+// the base name for files targeted directly. Collisions between the computed
+// ids are treated according to the collision strategy.
+func OptSetStreamIDFunc(fn func(relPath string) (string, error)) OptFunc {
+	return func(r *Reader) {
+		r.streamIDFn = fn
+	}
+}
+
+// OptSetStreamIDCollisionStrategy sets how stream config files that are given
+// the same inferred stream id are treated, which by default results in an
+// error.
+func OptSetStreamIDCollisionStrategy(strategy StreamIDCollisionStrategy) OptFunc {
+	return func(r *Reader) {
+		r.streamIDCollisions = strategy
+	}
+}
+
+// OptSetLogger sets the logger used for reporting events that occur whilst
+// reading configs, such as stream configs being overridden. By default these
+// events are not logged.
+func OptSetLogger(logger log.Modular) OptFunc {
+	return func(r *Reader) {
+		r.logger = logger
+	}
+}
+
 // OptUseFS sets the ifs.FS implementation for the reader to use. By default the
 // OS filesystem is used, and when overridden it is no longer possible to use
 // BeginFileWatching.
@@ -172,6 +257,31 @@ func OptUseFS(fs ifs.FS) OptFunc {
 	}
 }
 
+// StreamOpts returns options that configure another reader to read stream
+// configs in the same way as this one, including how stream ids are inferred
+// from paths, which files are read from directories and the shared anchors
+// made available to them, but without the stream paths themselves. This allows
+// stream paths to be read again, such as when reloading them, with the same
+// ids that they were first read with.
+func (r *Reader) StreamOpts() []OptFunc {
+	opts := []OptFunc{
+		OptUseFS(r.fs),
+		OptTestSuffix(r.testSuffix),
+		OptSetStreamIDSeparator(r.streamIDSeparator),
+		OptSetStreamIDCollisionStrategy(r.streamIDCollisions),
+	}
+	if r.streamAnchorsPath != "" {
+		opts = append(opts, OptSetStreamAnchorsPath(r.streamAnchorsPath))
+	}
+	if r.streamFileGlob != "" {
+		opts = append(opts, OptSetStreamFileGlob(r.streamFileGlob))
+	}
+	if r.streamIDFn != nil {
+		opts = append(opts, OptSetStreamIDFunc(r.streamIDFn))
+	}
+	return opts
+}
+
 //------------------------------------------------------------------------------
 
 func (r *Reader) lintCtx() docs.LintContext {
@@ -198,7 +308,34 @@ func (r *Reader) Read() (conf Type, pConf *docs.ParsedConfig, lints []string, er
 // Stream configs are extracted and added to a provided map, where the id is
 // derived from the path of the stream config file.
 func (r *Reader) ReadStreams(confs map[string]stream.Config) (lints []string, err error) {
-	return r.readStreamFiles(confs)
+	return r.ReadStreamsCtx(context.Background(), confs)
+}
+
+// ReadStreamsCtx attempts to read Bento stream configs in the same way as
+// ReadStreams, but aborts walking directories and reading files once the
+// provided context is cancelled or its deadline passes, which prevents slow or
+// unresponsive filesystems from blocking indefinitely. When aborted the error
+// of the context is returned, and any streams read before then are retained
+// within the provided map.
+func (r *Reader) ReadStreamsCtx(ctx context.Context, confs map[string]stream.Config) (lints []string, err error) {
+	return r.readStreamFiles(ctx, confs, false)
+}
+
+// ReadStreamsLenient attempts to read Bento stream configs from one or more
+// paths in the same way as ReadStreams, but rather than aborting on the first
+// file that fails to load it continues on to the remaining files. All streams
+// that were successfully parsed are added to the provided map, and the returned
+// error (when not nil) names each file that failed along with the reason, at
+// which point it is up to the caller whether to proceed with a partial set.
+func (r *Reader) ReadStreamsLenient(confs map[string]stream.Config) (lints []string, err error) {
+	return r.ReadStreamsLenientCtx(context.Background(), confs)
+}
+
+// ReadStreamsLenientCtx attempts to read Bento stream configs in the same way
+// as ReadStreamsLenient, but aborts once the provided context is cancelled or
+// its deadline passes in the same way as ReadStreamsCtx.
+func (r *Reader) ReadStreamsLenientCtx(ctx context.Context, confs map[string]stream.Config) (lints []string, err error) {
+	return r.readStreamFiles(ctx, confs, true)
 }
 
 // MainUpdateFunc is a closure function called whenever a main config has been
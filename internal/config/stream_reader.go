@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -16,6 +17,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/config/test"
 	"github.com/warpstreamlabs/bento/internal/docs"
 	ifilepath "github.com/warpstreamlabs/bento/internal/filepath"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
 	"github.com/warpstreamlabs/bento/internal/stream"
 )
 
@@ -23,8 +25,9 @@ import (
 // containing directory. If the dir field is non-empty then the identifier will
 // include all sub-directories in the path as an id prefix, this means loading
 // streams with the same file name from different branches are still given
-// unique names.
-func inferStreamID(dir, path string) (string, error) {
+// unique names. Path separators are replaced with the configured separator,
+// which is an underscore by default, unless an id func has been configured.
+func (r *Reader) inferStreamID(dir, path string) (string, error) {
 	var id string
 	if dir != "" {
 		var err error
@@ -36,18 +39,210 @@ func inferStreamID(dir, path string) (string, error) {
 	}
 
 	id = strings.Trim(id, string(filepath.Separator))
+	if r.streamIDFn != nil {
+		return r.streamIDFn(id)
+	}
+
 	id = strings.TrimSuffix(id, ".yaml")
 	id = strings.TrimSuffix(id, ".yml")
-	id = strings.ReplaceAll(id, string(filepath.Separator), "_")
+	id = strings.ReplaceAll(id, string(filepath.Separator), r.streamIDSeparator)
 
 	return id, nil
 }
 
-func (r *Reader) readStreamFileConfig(path string) (conf stream.Config, lints []string, err error) {
+// StreamConfigFileName returns the name of a file that, when placed at the
+// root of a directory of stream configs, is given the provided stream id. This
+// is the reverse of the id inferred from a file path, where the nesting of
+// sub-directories is flattened, and therefore the file is always placed at the
+// root rather than nested by the underscores of the id.
+func StreamConfigFileName(id string) string {
+	return id + ".yaml"
+}
+
+// StreamIDCollisionStrategy determines how stream config files that are given
+// the same inferred stream id are treated.
+type StreamIDCollisionStrategy string
+
+// Strategies for resolving stream id collisions.
+const (
+	// StreamIDCollisionError rejects stream config files with colliding ids.
+	StreamIDCollisionError StreamIDCollisionStrategy = "error"
+
+	// StreamIDCollisionSuffix keeps the id of the first colliding file, in
+	// lexical order, and appends a numeric suffix to the ids of the others,
+	// starting from _2.
+	StreamIDCollisionSuffix StreamIDCollisionStrategy = "suffix"
+
+	// StreamIDCollisionParentDir prefixes the ids of all colliding files with
+	// the name of the directory they were found within, which is either the
+	// directory walked or, when a file is targeted directly, the directory
+	// containing it.
+	StreamIDCollisionParentDir StreamIDCollisionStrategy = "parent_dir"
+
+	// StreamIDCollisionOverride keeps only the last colliding file in the
+	// order that the stream paths are given, allowing the streams of a base
+	// directory to be overridden by those of a later directory. Files that
+	// collide within the same directory are still rejected.
+	StreamIDCollisionOverride StreamIDCollisionStrategy = "override"
+)
+
+// StreamConfigPaths returns a map of stream ids to the paths of the config
+// files that they were read from, excluding files that are overridden by a
+// later file with the same stream id.
+func (r *Reader) StreamConfigPaths() map[string]string {
+	paths := make(map[string]string, len(r.streamFileInfo))
+	for path, info := range r.streamFileInfo {
+		if _, overridden := r.overriddenStreamPaths[path]; !overridden {
+			paths[info.id] = path
+		}
+	}
+	return paths
+}
+
+// ResolvedStreamIDs returns a map of stream config file paths to the ids they
+// were given in place of their inferred ids in order to resolve a collision.
+func (r *Reader) ResolvedStreamIDs() map[string]string {
+	resolved := make(map[string]string, len(r.resolvedStreamIDs))
+	for k, v := range r.resolvedStreamIDs {
+		resolved[k] = v
+	}
+	return resolved
+}
+
+// OverriddenStreamPaths returns a map of stream config file paths that were
+// ignored due to a collision with a later file, when the collision strategy is
+// to override, to the paths of the files that override them.
+func (r *Reader) OverriddenStreamPaths() map[string]string {
+	overridden := make(map[string]string, len(r.overriddenStreamPaths))
+	for k, v := range r.overriddenStreamPaths {
+		overridden[k] = v
+	}
+	return overridden
+}
+
+// resolveStreamIDCollisions gives unique ids to stream config files that would
+// otherwise collide, according to the configured strategy. Files that do not
+// collide keep their inferred ids, and when the strategy is to error the
+// collisions are left to be rejected as the files are read.
+func (r *Reader) resolveStreamIDCollisions(paths []string) error {
+	switch r.streamIDCollisions {
+	case StreamIDCollisionError:
+		return nil
+	case StreamIDCollisionSuffix, StreamIDCollisionParentDir, StreamIDCollisionOverride:
+	default:
+		return fmt.Errorf("stream id collision strategy '%v' not recognised, expected one of: %v, %v, %v, %v", r.streamIDCollisions, StreamIDCollisionError, StreamIDCollisionSuffix, StreamIDCollisionParentDir, StreamIDCollisionOverride)
+	}
+
+	collisions := map[string][]string{}
+	for _, path := range paths {
+		id := r.streamFileInfo[path].id
+		collisions[id] = append(collisions[id], path)
+	}
+
+	if r.streamIDCollisions == StreamIDCollisionOverride {
+		r.overriddenStreamPaths = map[string]string{}
+		for id, colliding := range collisions {
+			if id == "" || len(colliding) < 2 {
+				continue
+			}
+			parents := map[string]string{}
+			for _, path := range colliding {
+				parent := r.streamFileInfo[path].parent
+				if other, exists := parents[parent]; exists {
+					return fmt.Errorf("stream id (%v) collision between files %v and %v could not be resolved as both were found within %v", id, other, path, parent)
+				}
+				parents[parent] = path
+			}
+			for _, path := range colliding[:len(colliding)-1] {
+				r.overriddenStreamPaths[path] = colliding[len(colliding)-1]
+			}
+		}
+		return nil
+	}
+
+	taken := map[string]struct{}{}
+	for id := range collisions {
+		taken[id] = struct{}{}
+	}
+
+	for _, path := range paths {
+		info := r.streamFileInfo[path]
+		colliding := collisions[info.id]
+		if info.id == "" || len(colliding) < 2 {
+			continue
+		}
+
+		var id string
+		switch r.streamIDCollisions {
+		case StreamIDCollisionSuffix:
+			if colliding[0] == path {
+				continue
+			}
+			for n := 2; ; n++ {
+				id = fmt.Sprintf("%v_%v", info.id, n)
+				if _, exists := taken[id]; !exists {
+					break
+				}
+			}
+		case StreamIDCollisionParentDir:
+			parent := filepath.Base(info.parent)
+			if parent == "." || parent == string(filepath.Separator) {
+				return fmt.Errorf("stream id (%v) collision from file %v could not be resolved as it has no parent directory name", info.id, path)
+			}
+			id = parent + "_" + info.id
+			if _, exists := taken[id]; exists {
+				return fmt.Errorf("stream id (%v) collision from file %v could not be resolved as the id %v is also taken", info.id, path, id)
+			}
+		}
+
+		taken[id] = struct{}{}
+		info.id = id
+		r.streamFileInfo[path] = info
+		r.resolvedStreamIDs[path] = id
+	}
+	return nil
+}
+
+// readFileEnvSwapCtx reads a file in the same way as ReadFileEnvSwap, but
+// returns early with the error of the context when it is cancelled before the
+// read completes. A read that has been abandoned in this way continues in the
+// background until the underlying filesystem call returns. Contexts that can
+// never be cancelled are read from directly.
+func (r *Reader) readFileEnvSwapCtx(ctx context.Context, path string) (configBytes []byte, lints []docs.Lint, modTime time.Time, err error) {
+	if ctx.Done() == nil {
+		return ReadFileEnvSwap(r.fs, path, os.LookupEnv)
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	type readResult struct {
+		configBytes []byte
+		lints       []docs.Lint
+		modTime     time.Time
+		err         error
+	}
+	resChan := make(chan readResult, 1)
+	go func() {
+		var res readResult
+		res.configBytes, res.lints, res.modTime, res.err = ReadFileEnvSwap(r.fs, path, os.LookupEnv)
+		resChan <- res
+	}()
+
+	select {
+	case res := <-resChan:
+		return res.configBytes, res.lints, res.modTime, res.err
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+}
+
+func (r *Reader) readStreamFileConfig(ctx context.Context, path string) (conf stream.Config, lints []string, err error) {
 	var confBytes []byte
 	var dLints []docs.Lint
 	var modTime time.Time
-	if confBytes, dLints, modTime, err = ReadFileEnvSwap(r.fs, path, os.LookupEnv); err != nil {
+	if confBytes, dLints, modTime, err = r.readFileEnvSwapCtx(ctx, path); err != nil {
 		return
 	}
 	for _, l := range dLints {
@@ -56,7 +251,10 @@ func (r *Reader) readStreamFileConfig(path string) (conf stream.Config, lints []
 	r.modTimeLastRead[path] = modTime
 
 	var rawNode *yaml.Node
-	if rawNode, err = docs.UnmarshalYAML(confBytes); err != nil {
+	if rawNode, err = r.unmarshalStreamYAML(confBytes); err != nil {
+		return
+	}
+	if _, err = stream.MigrateYAML(rawNode); err != nil {
 		return
 	}
 
@@ -81,7 +279,81 @@ func (r *Reader) readStreamFileConfig(path string) (conf stream.Config, lints []
 	return
 }
 
-func (r *Reader) readStreamFile(id, path string, confs map[string]stream.Config) ([]string, error) {
+func walkYAMLNodes(node *yaml.Node, fn func(n *yaml.Node) error) error {
+	if err := fn(node); err != nil {
+		return err
+	}
+	for _, child := range node.Content {
+		if err := walkYAMLNodes(child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalStreamYAML parses a stream config, and when a shared anchors file
+// is configured the contents of that file are prepended to the config so that
+// aliases within the config are able to reference the anchors it defines. The
+// fields of the shared file are then removed from the parsed config, and line
+// numbers are restored so that they are relative to the stream config itself.
+func (r *Reader) unmarshalStreamYAML(confBytes []byte) (*yaml.Node, error) {
+	if r.streamAnchorsPath == "" {
+		return docs.UnmarshalYAML(confBytes)
+	}
+
+	anchorBytes, _, _, err := ReadFileEnvSwap(r.fs, r.streamAnchorsPath, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared anchors file: %w", err)
+	}
+
+	anchorsNode, err := docs.UnmarshalYAML(anchorBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shared anchors file: %w", err)
+	}
+	if anchorsNode.Kind == 0 {
+		return docs.UnmarshalYAML(confBytes)
+	}
+	if anchorsNode.Kind != yaml.MappingNode {
+		return nil, errors.New("shared anchors file must contain an object")
+	}
+
+	sharedAnchors := map[string]struct{}{}
+	_ = walkYAMLNodes(anchorsNode, func(n *yaml.Node) error {
+		if n.Anchor != "" {
+			sharedAnchors[n.Anchor] = struct{}{}
+		}
+		return nil
+	})
+
+	if !bytes.HasSuffix(anchorBytes, []byte("\n")) {
+		anchorBytes = append(anchorBytes, '\n')
+	}
+	lineOffset := bytes.Count(anchorBytes, []byte("\n"))
+
+	rawNode, err := docs.UnmarshalYAML(append(anchorBytes, confBytes...))
+	if err != nil {
+		return nil, err
+	}
+	if rawNode.Kind != yaml.MappingNode || len(rawNode.Content) < len(anchorsNode.Content) {
+		return nil, errors.New("stream config must contain an object")
+	}
+	rawNode.Content = rawNode.Content[len(anchorsNode.Content):]
+
+	if err := walkYAMLNodes(rawNode, func(n *yaml.Node) error {
+		if _, exists := sharedAnchors[n.Anchor]; exists {
+			return fmt.Errorf("line %v: anchor '%v' is already defined in the shared anchors file", n.Line-lineOffset, n.Anchor)
+		}
+		if n.Line > lineOffset {
+			n.Line -= lineOffset
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rawNode, nil
+}
+
+func (r *Reader) readStreamFile(ctx context.Context, id, path string, confs map[string]stream.Config) ([]string, error) {
 	if id == "" {
 		return nil, fmt.Errorf("stream id could not be inferred from file: %v", path)
 	}
@@ -89,7 +361,7 @@ func (r *Reader) readStreamFile(id, path string, confs map[string]stream.Config)
 		return nil, fmt.Errorf("stream id (%v) collision from file: %v", id, path)
 	}
 
-	conf, lints, err := r.readStreamFileConfig(path)
+	conf, lints, err := r.readStreamFileConfig(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -98,26 +370,37 @@ func (r *Reader) readStreamFile(id, path string, confs map[string]stream.Config)
 	return lints, nil
 }
 
-func (r *Reader) streamPathsExpanded() ([]string, error) {
+// streamPathsExpanded resolves the configured stream paths into the stream
+// config files they target, walking any directories. The walk is aborted with
+// the error of the context when it is cancelled.
+func (r *Reader) streamPathsExpanded(ctx context.Context) ([]string, error) {
 	streamsPaths, err := ifilepath.Globs(r.fs, r.streamsPaths)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve stream glob pattern: %w", err)
 	}
+	if r.streamFileGlob != "" {
+		if _, err := filepath.Match(r.streamFileGlob, ""); err != nil {
+			return nil, fmt.Errorf("failed to parse stream file glob pattern: %w", err)
+		}
+	}
 
 	var paths []string
 	for _, target := range streamsPaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		target = filepath.Clean(target)
 
 		if info, err := r.fs.Stat(target); err != nil {
 			return nil, err
 		} else if !info.IsDir() {
-			id, err := inferStreamID("", target)
+			id, err := r.inferStreamID("", target)
 			if err != nil {
 				return nil, err
 			}
 
 			if _, exists := r.streamFileInfo[target]; !exists {
-				r.streamFileInfo[target] = streamFileInfo{id: id}
+				r.streamFileInfo[target] = streamFileInfo{id: id, parent: filepath.Dir(target)}
 			}
 			paths = append(paths, target)
 			continue
@@ -127,13 +410,22 @@ func (r *Reader) streamPathsExpanded() ([]string, error) {
 			if werr != nil {
 				return werr
 			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			if info.IsDir() ||
 				(!strings.HasSuffix(info.Name(), ".yaml") &&
 					!strings.HasSuffix(info.Name(), ".yml")) {
 				return nil
 			}
+			if r.streamFileGlob != "" {
+				// The pattern has already been validated.
+				if matched, _ := filepath.Match(r.streamFileGlob, info.Name()); !matched {
+					return nil
+				}
+			}
 
-			id, err := inferStreamID(target, path)
+			id, err := r.inferStreamID(target, path)
 			if err != nil {
 				return err
 			}
@@ -145,8 +437,11 @@ func (r *Reader) streamPathsExpanded() ([]string, error) {
 			}
 
 			path = filepath.Clean(path)
+			if path == r.streamAnchorsPath {
+				return nil
+			}
 			if _, exists := r.streamFileInfo[path]; !exists {
-				r.streamFileInfo[path] = streamFileInfo{id: id}
+				r.streamFileInfo[path] = streamFileInfo{id: id, parent: target}
 			}
 			paths = append(paths, path)
 			return nil
@@ -157,19 +452,37 @@ func (r *Reader) streamPathsExpanded() ([]string, error) {
 	return paths, nil
 }
 
-func (r *Reader) readStreamFiles(streamMap map[string]stream.Config) (pathLints []string, err error) {
+func (r *Reader) readStreamFiles(ctx context.Context, streamMap map[string]stream.Config, lenient bool) (pathLints []string, err error) {
 	var streamsPaths []string
-	if streamsPaths, err = r.streamPathsExpanded(); err != nil {
+	if streamsPaths, err = r.streamPathsExpanded(ctx); err != nil {
+		return nil, err
+	}
+	if err = r.resolveStreamIDCollisions(streamsPaths); err != nil {
 		return nil, err
 	}
 
+	var fileErrs []error
 	for _, target := range streamsPaths {
-		tmpPathLints, err := r.readStreamFile(r.streamFileInfo[target].id, target, streamMap)
+		if by, overridden := r.overriddenStreamPaths[target]; overridden {
+			r.logger.Info("Stream %v config from %v is overridden by %v", r.streamFileInfo[target].id, target, by)
+			continue
+		}
+		tmpPathLints, err := r.readStreamFile(ctx, r.streamFileInfo[target].id, target, streamMap)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// Streams read before the context was cancelled are kept.
+			return pathLints, errors.Join(append(fileErrs, ctxErr)...)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to load config '%v': %v", target, err)
+			err = fmt.Errorf("failed to load config '%v': %v", target, err)
+			if !lenient {
+				return nil, err
+			}
+			fileErrs = append(fileErrs, err)
+			continue
 		}
 		pathLints = append(pathLints, tmpPathLints...)
 	}
+	err = errors.Join(fileErrs...)
 	return
 }
 
@@ -183,13 +496,18 @@ func (r *Reader) findStreamPathWalkedDir(streamPath string) (dir string) {
 }
 
 // TriggerStreamUpdate attempts to re-read a stream configuration file, and
-// trigger the provided stream update func.
+// trigger the provided stream update func. Changes to files that are
+// overridden by a later file with the same stream id are ignored.
 func (r *Reader) TriggerStreamUpdate(mgr bundle.NewManagement, strict bool, path string) error {
 	if r.streamUpdateFn == nil {
 		return nil
 	}
+	if by, overridden := r.overriddenStreamPaths[path]; overridden {
+		mgr.Logger().Debug("Ignoring change to stream config %v as it is overridden by %v", path, by)
+		return nil
+	}
 
-	conf, lints, err := r.readStreamFileConfig(path)
+	conf, lints, err := r.readStreamFileConfig(context.Background(), path)
 	if errors.Is(err, fs.ErrNotExist) {
 		info, exists := r.streamFileInfo[path]
 		if !exists {
@@ -213,11 +531,11 @@ func (r *Reader) TriggerStreamUpdate(mgr bundle.NewManagement, strict bool, path
 	if exists {
 		mgr.Logger().Info("Stream %v config updated, attempting to update stream.", info.id)
 	} else {
-		id, err := inferStreamID(r.findStreamPathWalkedDir(path), path)
+		id, err := r.inferStreamID(r.findStreamPathWalkedDir(path), path)
 		if err != nil {
 			return err
 		}
-		info = streamFileInfo{id: id}
+		info = streamFileInfo{id: id, parent: r.findStreamPathWalkedDir(path)}
 		r.streamFileInfo[path] = info
 		mgr.Logger().Info("Stream %v config added, attempting to create stream.", info.id)
 	}
@@ -238,3 +556,54 @@ func (r *Reader) TriggerStreamUpdate(mgr bundle.NewManagement, strict bool, path
 	mgr.Logger().Info("Updated stream %v config from file.", info.id)
 	return nil
 }
+
+// LoadStreamConfigsFromDirectories reads the stream configs found within a
+// list of directories, walking each in order. Where files from different
+// directories are given the same stream id the file from the later directory
+// overrides the earlier one, allowing a base set of streams to be layered with
+// overlays, whereas files given the same stream id within one directory, such
+// as a/b.yaml and a_b.yaml, result in an error. Returns the resulting map of
+// stream configs along with any linting errors.
+func LoadStreamConfigsFromDirectories(dirs []string, opts ...OptFunc) (map[string]stream.Config, []string, error) {
+	return LoadStreamConfigsFromDirectoriesCtx(context.Background(), dirs, opts...)
+}
+
+// LoadStreamConfigsFromDirectoriesCtx reads the stream configs found within a
+// list of directories in the same way as LoadStreamConfigsFromDirectories, but
+// aborts once the provided context is cancelled or its deadline passes in the
+// same way as ReadStreamsCtx.
+func LoadStreamConfigsFromDirectoriesCtx(ctx context.Context, dirs []string, opts ...OptFunc) (map[string]stream.Config, []string, error) {
+	return loadStreamConfigs(ctx, dirs, opts...)
+}
+
+// LoadStreamConfigsFromFS reads the stream configs found within a directory of
+// the provided filesystem, such as one embedded within a binary, in the same
+// way as LoadStreamConfigsFromDirectories reads them from the OS. Paths are
+// slash separated and relative to the root of the filesystem, where a dir of
+// "." walks the whole filesystem. Returns the resulting map of stream configs
+// along with any linting errors.
+func LoadStreamConfigsFromFS(fsys fs.FS, dir string, opts ...OptFunc) (map[string]stream.Config, []string, error) {
+	return LoadStreamConfigsFromFSCtx(context.Background(), fsys, dir, opts...)
+}
+
+// LoadStreamConfigsFromFSCtx reads the stream configs found within a directory
+// of the provided filesystem in the same way as LoadStreamConfigsFromFS, but
+// aborts once the provided context is cancelled or its deadline passes in the
+// same way as ReadStreamsCtx.
+func LoadStreamConfigsFromFSCtx(ctx context.Context, fsys fs.FS, dir string, opts ...OptFunc) (map[string]stream.Config, []string, error) {
+	return loadStreamConfigs(ctx, []string{dir}, append([]OptFunc{OptUseFS(ifs.ReadOnly(fsys))}, opts...)...)
+}
+
+func loadStreamConfigs(ctx context.Context, dirs []string, opts ...OptFunc) (map[string]stream.Config, []string, error) {
+	opts = append([]OptFunc{
+		OptSetStreamPaths(dirs...),
+		OptSetStreamIDCollisionStrategy(StreamIDCollisionOverride),
+	}, opts...)
+
+	confs := map[string]stream.Config{}
+	lints, err := NewReader("", nil, opts...).ReadStreamsCtx(ctx, confs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return confs, lints, nil
+}
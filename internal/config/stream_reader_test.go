@@ -1,15 +1,22 @@
 package config_test
 
 import (
+	"context"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
 	"github.com/warpstreamlabs/bento/internal/stream"
 
 	_ "github.com/warpstreamlabs/bento/public/components/pure"
@@ -116,3 +123,564 @@ pipeline:
 	assert.Equal(t, `root = "second"`, gabs.Wrap(testConfToAny(t, streamConfs["inner_second"])).S("pipeline", "processors", "0", "bloblang").Data())
 	assert.Equal(t, `root = "third"`, gabs.Wrap(testConfToAny(t, streamConfs["inner_third"])).S("pipeline", "processors", "0", "bloblang").Data())
 }
+
+func TestStreamsDirectoryWalkLenient(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first.yaml"), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "first"'
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second.yaml"), []byte(`
+pipeline:
+  processors: [ this is not valid
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "third.yaml"), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "third"'
+`), 0o644))
+
+	rdr := config.NewReader("", nil, config.OptSetStreamPaths(dir))
+
+	_, _, _, err := rdr.Read()
+	require.NoError(t, err)
+
+	streamConfs := map[string]stream.Config{}
+	_, err = rdr.ReadStreams(streamConfs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "second.yaml")
+
+	streamConfs = map[string]stream.Config{}
+	_, err = rdr.ReadStreamsLenient(streamConfs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "second.yaml")
+	assert.NotContains(t, err.Error(), "first.yaml")
+	assert.NotContains(t, err.Error(), "third.yaml")
+
+	require.Len(t, streamConfs, 2)
+	assert.Contains(t, streamConfs, "first")
+	assert.Contains(t, streamConfs, "third")
+}
+
+func TestStreamsSharedAnchors(t *testing.T) {
+	dir := t.TempDir()
+
+	anchorsPath := filepath.Join(dir, "anchors.yaml")
+	require.NoError(t, os.WriteFile(anchorsPath, []byte(`
+shared_input: &shared_input
+  generate:
+    mapping: 'root = "shared"'
+`), 0o644))
+
+	streamsDir := filepath.Join(dir, "streams")
+	require.NoError(t, os.MkdirAll(streamsDir, 0o755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(streamsDir, "first.yaml"), []byte(`
+input: *shared_input
+output:
+  drop: {}
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(streamsDir, "second.yaml"), []byte(`
+input: *shared_input
+pipeline:
+  processors:
+    - &local_proc
+      bloblang: 'root = "second"'
+    - *local_proc
+output:
+  drop: {}
+nope: {}
+`), 0o644))
+
+	rdr := config.NewReader("", nil,
+		config.OptSetStreamPaths(streamsDir),
+		config.OptSetStreamAnchorsPath(anchorsPath),
+	)
+
+	_, _, _, err := rdr.Read()
+	require.NoError(t, err)
+
+	streamConfs := map[string]stream.Config{}
+	lints, err := rdr.ReadStreams(streamConfs)
+	require.NoError(t, err)
+
+	require.Len(t, lints, 1)
+	assert.Contains(t, lints[0], "/second.yaml(10,1) field nope not recognised")
+
+	require.Len(t, streamConfs, 2)
+
+	assert.Equal(t, "generate", streamConfs["first"].Input.Type)
+	assert.Equal(t, "generate", streamConfs["second"].Input.Type)
+	assert.Equal(t, `root = "shared"`, gabs.Wrap(testConfToAny(t, streamConfs["first"])).S("input", "generate", "mapping").Data())
+	assert.Nil(t, gabs.Wrap(testConfToAny(t, streamConfs["first"])).S("shared_input").Data())
+
+	secondAny := gabs.Wrap(testConfToAny(t, streamConfs["second"]))
+	assert.Equal(t, `root = "second"`, secondAny.S("pipeline", "processors", "1", "bloblang").Data())
+
+	require.NoError(t, os.WriteFile(filepath.Join(streamsDir, "third.yaml"), []byte(`
+input: &shared_input
+  generate:
+    mapping: 'root = "redefined"'
+`), 0o644))
+
+	_, err = rdr.ReadStreams(map[string]stream.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "anchor 'shared_input' is already defined")
+	assert.Contains(t, err.Error(), "third.yaml")
+}
+
+func TestStreamsIDCollisions(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "one"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "two"), 0o755))
+
+	streamOnePath := filepath.Join(dir, "one", "stream.yaml")
+	require.NoError(t, os.WriteFile(streamOnePath, []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "one"'
+`), 0o644))
+
+	streamTwoPath := filepath.Join(dir, "two", "stream.yaml")
+	require.NoError(t, os.WriteFile(streamTwoPath, []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "two"'
+`), 0o644))
+
+	mappingOf := func(conf stream.Config) any {
+		return gabs.Wrap(testConfToAny(t, conf)).S("pipeline", "processors", "0", "bloblang").Data()
+	}
+
+	tests := []struct {
+		name        string
+		opts        []config.OptFunc
+		errContains string
+		ids         map[string]string
+		resolved    map[string]string
+	}{
+		{
+			name:        "default errors",
+			errContains: "stream id (stream) collision",
+		},
+		{
+			name:        "error",
+			opts:        []config.OptFunc{config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionError)},
+			errContains: "stream id (stream) collision",
+		},
+		{
+			name: "suffix",
+			opts: []config.OptFunc{config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionSuffix)},
+			ids: map[string]string{
+				"stream":   `root = "one"`,
+				"stream_2": `root = "two"`,
+			},
+			resolved: map[string]string{
+				streamTwoPath: "stream_2",
+			},
+		},
+		{
+			name: "parent dir",
+			opts: []config.OptFunc{config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionParentDir)},
+			ids: map[string]string{
+				"one_stream": `root = "one"`,
+				"two_stream": `root = "two"`,
+			},
+			resolved: map[string]string{
+				streamOnePath: "one_stream",
+				streamTwoPath: "two_stream",
+			},
+		},
+		{
+			name:        "unknown strategy",
+			opts:        []config.OptFunc{config.OptSetStreamIDCollisionStrategy("nope")},
+			errContains: "stream id collision strategy 'nope' not recognised",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]config.OptFunc{config.OptSetStreamPaths(streamOnePath, streamTwoPath)}, test.opts...)
+			rdr := config.NewReader("", nil, opts...)
+
+			streamConfs := map[string]stream.Config{}
+			_, err := rdr.ReadStreams(streamConfs)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, streamConfs, len(test.ids))
+			for id, mapping := range test.ids {
+				require.Contains(t, streamConfs, id)
+				assert.Equal(t, mapping, mappingOf(streamConfs[id]))
+			}
+			assert.Equal(t, test.resolved, rdr.ResolvedStreamIDs())
+		})
+	}
+}
+
+func TestStreamsIDCollisionsDirectoryWalk(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo_bar"), 0o755))
+
+	// Both files are given the id foo_bar_baz when walked.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo", "bar_baz.yaml"), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "first"'
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo_bar", "baz.yaml"), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "second"'
+`), 0o644))
+
+	rdr := config.NewReader("", nil,
+		config.OptSetStreamPaths(dir),
+		config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionSuffix),
+	)
+
+	streamConfs := map[string]stream.Config{}
+	_, err := rdr.ReadStreams(streamConfs)
+	require.NoError(t, err)
+
+	require.Len(t, streamConfs, 2)
+	assert.Equal(t, `root = "first"`, gabs.Wrap(testConfToAny(t, streamConfs["foo_bar_baz"])).S("pipeline", "processors", "0", "bloblang").Data())
+	assert.Equal(t, `root = "second"`, gabs.Wrap(testConfToAny(t, streamConfs["foo_bar_baz_2"])).S("pipeline", "processors", "0", "bloblang").Data())
+
+	// The parent directory is the walked directory, which is shared by both
+	// files and therefore cannot resolve the collision.
+	rdr = config.NewReader("", nil,
+		config.OptSetStreamPaths(dir),
+		config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionParentDir),
+	)
+	_, err = rdr.ReadStreams(map[string]stream.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is also taken")
+}
+
+func TestLoadStreamConfigsFromDirectories(t *testing.T) {
+	baseDir, overlayDir := t.TempDir(), t.TempDir()
+
+	writeStream := func(dir, name, mapping string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`
+pipeline:
+  processors:
+    - bloblang: '%v'
+`, mapping)), 0o644))
+		return path
+	}
+
+	baseFooPath := writeStream(baseDir, "foo.yaml", `root = "base foo"`)
+	writeStream(baseDir, "bar.yaml", `root = "base bar"`)
+	overlayFooPath := writeStream(overlayDir, "foo.yaml", `root = "overlay foo"`)
+	writeStream(overlayDir, "baz.yaml", `root = "overlay baz"`)
+
+	confs, lints, err := config.LoadStreamConfigsFromDirectories([]string{baseDir, overlayDir})
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+
+	mappings := map[string]any{}
+	for id, conf := range confs {
+		mappings[id] = gabs.Wrap(testConfToAny(t, conf)).S("pipeline", "processors", "0", "bloblang").Data()
+	}
+	assert.Equal(t, map[string]any{
+		"foo": `root = "overlay foo"`,
+		"bar": `root = "base bar"`,
+		"baz": `root = "overlay baz"`,
+	}, mappings)
+
+	rdr := config.NewReader("", nil,
+		config.OptSetStreamPaths(baseDir, overlayDir),
+		config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionOverride),
+	)
+	_, err = rdr.ReadStreams(map[string]stream.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{baseFooPath: overlayFooPath}, rdr.OverriddenStreamPaths())
+	assert.Equal(t, map[string]string{
+		"foo": overlayFooPath,
+		"bar": filepath.Join(baseDir, "bar.yaml"),
+		"baz": filepath.Join(overlayDir, "baz.yaml"),
+	}, rdr.StreamConfigPaths())
+}
+
+func TestLoadStreamConfigsFromDirectoriesSameDirCollision(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a"), 0o755))
+
+	// Both files are given the id a_b when walked.
+	for _, name := range []string{filepath.Join("a", "b.yaml"), "a_b.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "ab"'
+`), 0o644))
+	}
+
+	_, _, err := config.LoadStreamConfigsFromDirectories([]string{dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream id (a_b) collision")
+
+	_, _, err = config.LoadStreamConfigsFromDirectories([]string{t.TempDir(), dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream id (a_b) collision")
+}
+
+func TestLoadStreamConfigsFromDirectoriesGlob(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "eu"), 0o755))
+
+	for _, name := range []string{
+		"prod-foo.yaml", "prod-bar.yml", "staging-foo.yaml", "dev-baz.yaml",
+		filepath.Join("eu", "prod-baz.yaml"), filepath.Join("eu", "staging-baz.yaml"),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "`+name+`"'
+`), 0o644))
+	}
+
+	confs, lints, err := config.LoadStreamConfigsFromDirectories([]string{dir}, config.OptSetStreamFileGlob("prod-*"))
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+
+	var ids []string
+	for id := range confs {
+		ids = append(ids, id)
+	}
+	assert.ElementsMatch(t, []string{"prod-foo", "prod-bar", "eu_prod-baz"}, ids)
+
+	_, _, err = config.LoadStreamConfigsFromDirectories([]string{dir}, config.OptSetStreamFileGlob("prod-[*"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse stream file glob pattern")
+}
+
+func TestLoadStreamConfigsFromFS(t *testing.T) {
+	streamFile := func(mapping string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(fmt.Sprintf(`
+pipeline:
+  processors:
+    - bloblang: '%v'
+`, mapping))}
+	}
+
+	fsys := fstest.MapFS{
+		"streams/foo.yaml":   streamFile(`root = "foo"`),
+		"streams/eu/bar.yml": streamFile(`root = "eu bar"`),
+		"streams/README.md":  &fstest.MapFile{Data: []byte("not a config")},
+		"elsewhere/baz.yaml": streamFile(`root = "baz"`),
+	}
+
+	confs, lints, err := config.LoadStreamConfigsFromFS(fsys, "streams")
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+
+	mappings := map[string]any{}
+	for id, conf := range confs {
+		mappings[id] = gabs.Wrap(testConfToAny(t, conf)).S("pipeline", "processors", "0", "bloblang").Data()
+	}
+	assert.Equal(t, map[string]any{
+		"foo":    `root = "foo"`,
+		"eu_bar": `root = "eu bar"`,
+	}, mappings)
+
+	confs, _, err = config.LoadStreamConfigsFromFS(fsys, ".", config.OptSetStreamFileGlob("ba*"))
+	require.NoError(t, err)
+
+	var ids []string
+	for id := range confs {
+		ids = append(ids, id)
+	}
+	assert.ElementsMatch(t, []string{"streams_eu_bar", "elsewhere_baz"}, ids)
+
+	_, _, err = config.LoadStreamConfigsFromFS(fsys, "missing")
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = config.LoadStreamConfigsFromFSCtx(ctx, fsys, "streams")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = config.LoadStreamConfigsFromDirectoriesCtx(ctx, []string{t.TempDir()})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStreamIDSeparator(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo", "bar"), 0o755))
+
+	for _, name := range []string{
+		"foo_bar.yaml",
+		filepath.Join("foo", "bar.yaml"),
+		filepath.Join("foo", "bar", "baz.yml"),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "`+name+`"'
+`), 0o644))
+	}
+
+	streamIDs := func(opts ...config.OptFunc) ([]string, error) {
+		confs := map[string]stream.Config{}
+		_, err := config.NewReader("", nil, append([]config.OptFunc{config.OptSetStreamPaths(dir)}, opts...)...).ReadStreams(confs)
+		var ids []string
+		for id := range confs {
+			ids = append(ids, id)
+		}
+		return ids, err
+	}
+
+	// The default separator results in a collision between foo_bar.yaml and
+	// foo/bar.yaml.
+	_, err := streamIDs()
+	require.Error(t, err)
+
+	ids, err := streamIDs(config.OptSetStreamIDSeparator("."))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo_bar", "foo.bar", "foo.bar.baz"}, ids)
+
+	// The stream options of a reader reproduce the same ids.
+	rdr := config.NewReader("", nil, config.OptSetStreamPaths(dir), config.OptSetStreamIDSeparator("."))
+	ids, err = streamIDs(rdr.StreamOpts()...)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo_bar", "foo.bar", "foo.bar.baz"}, ids)
+
+	ids, err = streamIDs(config.OptSetStreamIDFunc(func(relPath string) (string, error) {
+		return strings.ToUpper(filepath.ToSlash(relPath)), nil
+	}))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"FOO_BAR.YAML", "FOO/BAR.YAML", "FOO/BAR/BAZ.YML"}, ids)
+
+	// Collisions are detected on the computed ids.
+	_, err = streamIDs(config.OptSetStreamIDFunc(func(relPath string) (string, error) {
+		return "same", nil
+	}))
+	require.Error(t, err)
+
+	ids, err = streamIDs(
+		config.OptSetStreamIDFunc(func(relPath string) (string, error) { return "same", nil }),
+		config.OptSetStreamIDCollisionStrategy(config.StreamIDCollisionSuffix),
+	)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"same", "same_2", "same_3"}, ids)
+}
+
+// blockingFS is a filesystem where opening a specific file blocks until the
+// filesystem is released, emulating an unresponsive network mount.
+type blockingFS struct {
+	ifs.FS
+	blockPath string
+	release   chan struct{}
+}
+
+func (b *blockingFS) Open(name string) (fs.File, error) {
+	if filepath.Clean(name) == b.blockPath {
+		<-b.release
+	}
+	return b.FS.Open(name)
+}
+
+func TestStreamsDirectoryWalkCancelled(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.yaml", "b.yaml", "c.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "`+name+`"'
+`), 0o644))
+	}
+
+	bFS := &blockingFS{
+		FS:        ifs.OS(),
+		blockPath: filepath.Join(dir, "b.yaml"),
+		release:   make(chan struct{}),
+	}
+	t.Cleanup(func() { close(bFS.release) })
+
+	rdr := config.NewReader("", nil, config.OptSetStreamPaths(dir), config.OptUseFS(bFS))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		cancel()
+	}()
+
+	streamConfs := map[string]stream.Config{}
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := rdr.ReadStreamsCtx(ctx, streamConfs)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for cancelled read to return")
+	}
+
+	assert.Len(t, streamConfs, 1)
+	assert.Contains(t, streamConfs, "a")
+
+	// A context that is already cancelled aborts the directory walk.
+	lints, err := rdr.ReadStreamsCtx(ctx, map[string]stream.Config{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, lints)
+}
+
+func TestLoadStreamConfigsSchemaMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.yaml"), []byte(`
+schema_version: 1
+processors:
+  - bloblang: 'root = "old"'
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "current.yaml"), []byte(`
+pipeline:
+  processors:
+    - bloblang: 'root = "current"'
+`), 0o644))
+
+	confs, lints, err := config.LoadStreamConfigsFromDirectories([]string{dir})
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+
+	require.Contains(t, confs, "old")
+	require.Len(t, confs["old"].Pipeline.Processors, 1)
+	assert.Equal(t, "bloblang", confs["old"].Pipeline.Processors[0].Type)
+
+	// The raw source of a migrated config is of the current layout.
+	oldConf := confs["old"]
+	rawSource, _ := oldConf.GetRawSource().(map[string]any)
+	assert.NotContains(t, rawSource, "schema_version")
+	assert.NotContains(t, rawSource, "processors")
+	assert.Contains(t, rawSource, "pipeline")
+
+	require.Contains(t, confs, "current")
+	assert.Len(t, confs["current"].Pipeline.Processors, 1)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "future.yaml"), []byte(`
+schema_version: 99
+`), 0o644))
+	_, _, err = config.LoadStreamConfigsFromDirectories([]string{dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99 is newer than the latest version supported")
+}
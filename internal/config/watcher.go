@@ -3,6 +3,7 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"path/filepath"
 	"time"
@@ -110,7 +111,7 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 			}
 		}
 
-		streamsPaths, err := r.streamPathsExpanded()
+		streamsPaths, err := r.streamPathsExpanded(context.Background())
 		if err != nil {
 			return err
 		}
@@ -131,6 +131,8 @@ type SanitiseConfig struct {
 	RemoveDeprecated bool
 	ScrubSecrets     bool
 	ForExample       bool
+	RemoveDefaults   bool
+	SortMapKeys      bool
 	Filter           FieldFilter
 	DocsProvider     Provider
 }
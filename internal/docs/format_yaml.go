@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 
@@ -314,6 +316,9 @@ func SanitiseYAML(cType Type, node *yaml.Node, conf SanitiseConfig) error {
 			if err := spec.SanitiseYAML(node.Content[i+1], conf); err != nil {
 				return err
 			}
+			if conf.RemoveDefaults && spec.yamlIsDefault(node.Content[i+1]) {
+				continue
+			}
 			newNodes = append(newNodes, node.Content[i], node.Content[i+1])
 		}
 	}
@@ -328,6 +333,10 @@ func SanitiseYAML(cType Type, node *yaml.Node, conf SanitiseConfig) error {
 func (f FieldSpec) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 	node = unwrapDocumentNode(node)
 
+	if conf.SortMapKeys && f.Kind == KindMap && node.Kind == yaml.MappingNode {
+		sortYAMLMappingKeys(node)
+	}
+
 	if coreType, isCore := f.Type.IsCoreComponent(); isCore {
 		switch f.Kind {
 		case Kind2DArray:
@@ -459,6 +468,9 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 		if err := field.SanitiseYAML(value, conf); err != nil {
 			return err
 		}
+		if conf.RemoveDefaults && field.yamlIsDefault(value) {
+			continue
+		}
 		var keyNode yaml.Node
 		if err := keyNode.Encode(field.Name); err != nil {
 			return err
@@ -469,6 +481,53 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 	return nil
 }
 
+// yamlIsDefault returns whether a field value is equivalent to the value the
+// field takes when it is omitted from a config. Objects are considered default
+// once all of their fields have been removed, unless the object is optional, in
+// which case its presence alone is meaningful.
+func (f FieldSpec) yamlIsDefault(node *yaml.Node) bool {
+	if len(f.Children) > 0 && f.Kind == KindScalar {
+		return f.Default == nil && !f.IsOptional && node.Kind == yaml.MappingNode && len(node.Content) == 0
+	}
+	if f.Default == nil && f.IsOptional {
+		return false
+	}
+
+	defValue, err := getDefault(f.Name, f)
+	if err != nil {
+		return false
+	}
+
+	// Encode the default as YAML first so that both values are decoded into
+	// the same types.
+	var defNode yaml.Node
+	if err := defNode.Encode(defValue); err != nil {
+		return false
+	}
+	var defV, v any
+	if err := defNode.Decode(&defV); err != nil {
+		return false
+	}
+	if err := node.Decode(&v); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(defV, v)
+}
+
+func sortYAMLMappingKeys(node *yaml.Node) {
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i][0].Value < pairs[j][0].Value
+	})
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p[0], p[1])
+	}
+}
+
 //------------------------------------------------------------------------------
 
 func lintYAMLFromOmit(parentSpec FieldSpecs, lintTargetSpec FieldSpec, parent, node *yaml.Node) []Lint {
@@ -1406,3 +1406,80 @@ processors:
 		})
 	}
 }
+
+func TestYAMLSanitationRemoveDefaults(t *testing.T) {
+	fields := docs.FieldSpecs{
+		docs.FieldString("a", "").HasDefault("foo"),
+		docs.FieldInt("b", "").HasDefault(5),
+		docs.FieldString("c", "").Array(),
+		docs.FieldString("d", "").Map(),
+		docs.FieldObject("e", "").WithChildren(
+			docs.FieldBool("f", "").HasDefault(false),
+		),
+		docs.FieldObject("g", "").WithChildren(
+			docs.FieldBool("h", "").HasDefault(false),
+		).Optional(),
+		docs.FieldString("i", ""),
+	}
+
+	for _, test := range []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{
+			name: "all defaults",
+			input: `
+a: foo
+b: 5
+c: []
+d: {}
+e:
+  f: false
+g:
+  h: false
+i: bar
+`,
+			output: `g: {}
+i: bar
+`,
+		},
+		{
+			name: "no defaults",
+			input: `
+i: bar
+d:
+  z: 1
+  y: 2
+e:
+  f: true
+b: 6
+a: foo2
+`,
+			output: `a: foo2
+b: 6
+d:
+    y: 2
+    z: 1
+e:
+    f: true
+i: bar
+`,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(test.input), &node))
+
+			sanitConf := docs.NewSanitiseConfig(bundle.GlobalEnvironment)
+			sanitConf.RemoveDefaults = true
+			sanitConf.SortMapKeys = true
+			require.NoError(t, fields.SanitiseYAML(&node, sanitConf))
+
+			resBytes, err := yaml.Marshal(node.Content[0])
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(resBytes))
+		})
+	}
+}
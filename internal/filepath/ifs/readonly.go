@@ -0,0 +1,42 @@
+package ifs
+
+import (
+	"io/fs"
+	"os"
+)
+
+// ReadOnly returns an FS that reads from the provided fs.FS, such as an
+// embedded filesystem, and rejects any calls that would modify it.
+func ReadOnly(f fs.FS) FS {
+	if r, ok := f.(*readOnly); ok {
+		return r
+	}
+	return &readOnly{f: f}
+}
+
+type readOnly struct {
+	f fs.FS
+}
+
+func (r *readOnly) Open(name string) (fs.File, error) {
+	return r.f.Open(name)
+}
+
+func (r *readOnly) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return r.f.Open(name)
+}
+
+func (r *readOnly) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(r.f, name)
+}
+
+func (r *readOnly) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (r *readOnly) MkdirAll(path string, perm fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrPermission}
+}
@@ -358,6 +358,28 @@ func (p *Metrics) HandlerFunc() http.HandlerFunc {
 	}
 }
 
+// DeleteLabel removes all series where the label of the given name has the
+// given value.
+func (p *Metrics) DeleteLabel(name, value string) {
+	labels := prometheus.Labels{name: value}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	for _, v := range p.counters {
+		v.ctr.DeletePartialMatch(labels)
+	}
+	for _, v := range p.gauges {
+		v.ctr.DeletePartialMatch(labels)
+	}
+	for _, v := range p.timers {
+		v.sum.DeletePartialMatch(labels)
+	}
+	for _, v := range p.timersHist {
+		v.sum.DeletePartialMatch(labels)
+	}
+}
+
 func (p *Metrics) NewCounterCtor(path string, labelNames ...string) service.MetricsExporterCounterCtor {
 	if !model.IsValidMetricName(model.LabelValue(path)) {
 		p.log.Errorf("Ignoring metric '%v' due to invalid name", path)
@@ -158,6 +158,24 @@ func TestPrometheusMetrics(t *testing.T) {
 	assert.Contains(t, body, "\ngaugethree 10.452")
 }
 
+func TestPrometheusDeleteLabel(t *testing.T) {
+	nm, handler := getTestProm(t)
+
+	ctr := nm.NewCounterCtor("counterone", "stream")
+	ctr("foo").Incr(10)
+	ctr("bar").Incr(11)
+
+	nm.NewGaugeCtor("gaugeone", "stream", "other")("foo", "baz").Set(12)
+	nm.NewTimerCtor("timerone", "stream")("foo").Timing(13)
+
+	nm.DeleteLabel("stream", "foo")
+
+	body := getPage(t, handler)
+
+	assert.Contains(t, body, "\ncounterone{stream=\"bar\"} 11")
+	assert.NotContains(t, body, "stream=\"foo\"")
+}
+
 func TestPrometheusHistMetrics(t *testing.T) {
 	nm := promFromYAML(t, `
 use_histogram_timing: true
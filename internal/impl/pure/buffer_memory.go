@@ -7,6 +7,7 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/batch/policy"
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/docs"
 	"github.com/warpstreamlabs/bento/public/service"
 )
@@ -99,8 +100,12 @@ type measuredBatch struct {
 }
 
 type memoryBuffer struct {
-	batches []measuredBatch
-	bytes   int
+	batches  []measuredBatch
+	bytes    int
+	messages int
+
+	// The number of writes blocked waiting for capacity.
+	blockedWrites int
 
 	cap        int
 	cond       *sync.Cond
@@ -167,7 +172,7 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 	var batchSources []measuredBatch
 
 	// The size of the batches that formed our output batch
-	var outSize int
+	var outSize, outMessages int
 
 	for {
 		if m.closed {
@@ -179,6 +184,7 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 
 		for len(m.batches) > 0 && !batchReady {
 			outSize += m.batches[0].size
+			outMessages += len(m.batches[0].b)
 			for _, msg := range m.batches[0].b {
 				batchReady = m.batcher.Add(msg.Copy())
 			}
@@ -213,6 +219,7 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 		defer m.cond.L.Unlock()
 		if err == nil {
 			m.bytes -= outSize
+			m.messages -= outMessages
 		} else {
 			m.batches = append(batchSources, m.batches...)
 		}
@@ -250,7 +257,9 @@ func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageB
 	}
 
 	for (m.bytes + extraBytes) > m.cap {
+		m.blockedWrites++
 		m.cond.Wait()
+		m.blockedWrites--
 		if m.closed {
 			return component.ErrTypeClosed
 		}
@@ -261,11 +270,22 @@ func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageB
 		size: extraBytes,
 	})
 	m.bytes += extraBytes
+	m.messages += len(msgBatch)
 
 	m.cond.Broadcast()
 	return nil
 }
 
+func (m *memoryBuffer) BufferDepth() (buffer.Depth, bool) {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	return buffer.Depth{
+		Messages:     m.messages,
+		Bytes:        m.bytes,
+		Backpressure: m.blockedWrites > 0,
+	}, true
+}
+
 func (m *memoryBuffer) EndOfInput() {
 	go func() {
 		m.cond.L.Lock()
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
@@ -67,6 +68,50 @@ limit: 100000
 	}
 }
 
+func TestMemoryBufferDepth(t *testing.T) {
+	ctx := context.Background()
+	block := memBufFromConf(t, `
+limit: 10
+`)
+	defer block.Close(ctx)
+
+	noopAck := func(ctx context.Context, err error) error { return nil }
+
+	require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("hello")),
+		service.NewMessage([]byte("foo")),
+	}, noopAck))
+
+	depth, ok := block.BufferDepth()
+	require.True(t, ok)
+	assert.Equal(t, buffer.Depth{Messages: 2, Bytes: 8}, depth)
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte("world")),
+		}, noopAck)
+	}()
+
+	assert.Eventually(t, func() bool {
+		depth, _ := block.BufferDepth()
+		return depth.Backpressure
+	}, time.Second, time.Millisecond*10)
+
+	_, ackFn, err := block.ReadBatch(ctx)
+	require.NoError(t, err)
+
+	// Messages that are read remain within the buffer until acknowledged.
+	depth, _ = block.BufferDepth()
+	assert.Equal(t, 2, depth.Messages)
+
+	require.NoError(t, ackFn(ctx, nil))
+	require.NoError(t, <-writeErr)
+
+	depth, _ = block.BufferDepth()
+	assert.Equal(t, buffer.Depth{Messages: 1, Bytes: 5}, depth)
+}
+
 func TestMemoryOwnership(t *testing.T) {
 	ctx := context.Background()
 	block := memBufFromConf(t, `
@@ -0,0 +1,119 @@
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelOverride is a log level that can be set and cleared at runtime. Loggers
+// derived with WithLevelOverride emit log events at the overridden level while
+// it is set, and at the level of the logger they were derived from otherwise.
+type LevelOverride struct {
+	// Holds the overridden logrus level plus one, with zero meaning unset.
+	level atomic.Uint32
+}
+
+// NewLevelOverride returns a level override that is initially unset.
+func NewLevelOverride() *LevelOverride {
+	return &LevelOverride{}
+}
+
+// Set the overridden level, which must be one of the levels accepted by the
+// logger config (OFF, FATAL, ERROR, WARN, INFO, DEBUG, TRACE or ALL).
+func (o *LevelOverride) Set(level string) error {
+	l, ok := logrusLevel(level)
+	if !ok {
+		return fmt.Errorf("log level '%v' not recognized", level)
+	}
+	o.level.Store(uint32(l) + 1)
+	return nil
+}
+
+// Clear the overridden level, reverting to the level of the original logger.
+func (o *LevelOverride) Clear() {
+	o.level.Store(0)
+}
+
+// Get returns the overridden level and whether it is set.
+func (o *LevelOverride) Get() (string, bool) {
+	l := o.level.Load()
+	if l == 0 {
+		return "", false
+	}
+	switch logrus.Level(l - 1) {
+	case logrus.PanicLevel:
+		return "OFF", true
+	case logrus.FatalLevel:
+		return "FATAL", true
+	case logrus.ErrorLevel:
+		return "ERROR", true
+	case logrus.WarnLevel:
+		return "WARN", true
+	case logrus.InfoLevel:
+		return "INFO", true
+	case logrus.DebugLevel:
+		return "DEBUG", true
+	}
+	return "TRACE", true
+}
+
+func (o *LevelOverride) enabled(level logrus.Level, fallback *logrus.Logger) bool {
+	if l := o.level.Load(); l != 0 {
+		return level <= logrus.Level(l-1)
+	}
+	return fallback.IsLevelEnabled(level)
+}
+
+// levelOverrider is implemented by loggers that support level overrides.
+type levelOverrider interface {
+	WithLevelOverride(o *LevelOverride) Modular
+}
+
+// WithLevelOverride returns a variant of a logger where the level of emitted
+// log events is determined by the provided override whilst it is set. Loggers
+// that do not support overrides are returned unchanged.
+func WithLevelOverride(l Modular, o *LevelOverride) Modular {
+	if lo, ok := l.(levelOverrider); ok {
+		return lo.WithLevelOverride(o)
+	}
+	return l
+}
+
+// WithLevelOverride returns a variant of the logger that emits log events at
+// the level of the provided override whilst it is set, independent of the level
+// of this logger.
+func (l *Logger) WithLevelOverride(o *LevelOverride) Modular {
+	base := l.entry.Logger
+	if l.override != nil {
+		base = l.fallback
+	}
+
+	// Log events are filtered by the override before reaching logrus, and
+	// therefore the logrus logger must accept all levels.
+	unfiltered := &logrus.Logger{
+		Out:          base.Out,
+		Hooks:        base.Hooks,
+		Formatter:    base.Formatter,
+		ReportCaller: base.ReportCaller,
+		Level:        logrus.TraceLevel,
+		ExitFunc:     base.ExitFunc,
+		BufferPool:   base.BufferPool,
+	}
+
+	newLogger := *l
+	newLogger.entry = unfiltered.WithFields(l.entry.Data)
+	newLogger.override = o
+	newLogger.fallback = base
+	return &newLogger
+}
+
+// WithLevelOverride returns a variant of the logger where both of the tee'd
+// loggers are given the level override.
+func (t *teeLogger) WithLevelOverride(o *LevelOverride) Modular {
+	return &teeLogger{
+		a: WithLevelOverride(t.a, o),
+		b: WithLevelOverride(t.b, o),
+	}
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+)
+
+func TestLoggerLevelOverride(t *testing.T) {
+	loggerConfig := NewConfig()
+	loggerConfig.AddTimeStamp = false
+	loggerConfig.StaticFields = nil
+	loggerConfig.Format = "logfmt"
+	loggerConfig.LogLevel = "INFO"
+
+	var buf bytes.Buffer
+
+	logger, err := New(&buf, ifs.OS(), loggerConfig)
+	require.NoError(t, err)
+
+	override := NewLevelOverride()
+	overridden := WithLevelOverride(logger.WithFields(map[string]string{"foo": "bar"}), override)
+
+	overridden.Debug("debug before")
+	overridden.Info("info before")
+
+	require.NoError(t, override.Set("debug"))
+	level, ok := override.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "DEBUG", level)
+
+	overridden.Debug("debug during")
+	overridden.Trace("trace during")
+	logger.Debug("debug root")
+
+	override.Clear()
+	_, ok = override.Get()
+	assert.False(t, ok)
+
+	overridden.Debug("debug after")
+	overridden.Info("info after")
+
+	require.NoError(t, override.Set("OFF"))
+	overridden.Error("error off")
+
+	assert.Error(t, override.Set("nope"))
+
+	expected := `level=info msg="info before" foo=bar
+level=debug msg="debug during" foo=bar
+level=info msg="info after" foo=bar
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestTeeLoggerLevelOverride(t *testing.T) {
+	loggerConfig := NewConfig()
+	loggerConfig.AddTimeStamp = false
+	loggerConfig.StaticFields = nil
+	loggerConfig.Format = "logfmt"
+	loggerConfig.LogLevel = "WARN"
+
+	var bufA, bufB bytes.Buffer
+
+	loggerA, err := New(&bufA, ifs.OS(), loggerConfig)
+	require.NoError(t, err)
+
+	loggerB, err := New(&bufB, ifs.OS(), loggerConfig)
+	require.NoError(t, err)
+
+	override := NewLevelOverride()
+	require.NoError(t, override.Set("INFO"))
+
+	WithLevelOverride(TeeLogger(loggerA, loggerB), override).Info("hello world")
+
+	assert.Equal(t, "level=info msg=\"hello world\"\n", bufA.String())
+	assert.Equal(t, "level=info msg=\"hello world\"\n", bufB.String())
+}
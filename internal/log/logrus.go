@@ -16,6 +16,11 @@ import (
 // Logger is an object with support for levelled logging and modular components.
 type Logger struct {
 	entry *logrus.Entry
+
+	// When set the level of log events is determined by the override, falling
+	// back to the level of the original logrus logger.
+	override *LevelOverride
+	fallback *logrus.Logger
 }
 
 // New returns a new logger from a config, or returns an error if the config
@@ -72,22 +77,8 @@ func New(stream io.Writer, fs ifs.FS, config Config) (Modular, error) {
 		return nil, fmt.Errorf("log format '%v' not recognized", config.Format)
 	}
 
-	switch strings.ToUpper(config.LogLevel) {
-	case "OFF", "NONE":
-		logger.Level = logrus.PanicLevel
-	case "FATAL":
-		logger.Level = logrus.FatalLevel
-	case "ERROR":
-		logger.Level = logrus.ErrorLevel
-	case "WARN":
-		logger.Level = logrus.WarnLevel
-	case "INFO":
-		logger.Level = logrus.InfoLevel
-	case "DEBUG":
-		logger.Level = logrus.DebugLevel
-	case "TRACE", "ALL":
-		logger.Level = logrus.TraceLevel
-		logger.Level = logrus.TraceLevel
+	if level, ok := logrusLevel(config.LogLevel); ok {
+		logger.Level = level
 	}
 
 	sFields := logrus.Fields{}
@@ -99,6 +90,26 @@ func New(stream io.Writer, fs ifs.FS, config Config) (Modular, error) {
 	return &Logger{entry: logEntry}, nil
 }
 
+func logrusLevel(level string) (logrus.Level, bool) {
+	switch strings.ToUpper(level) {
+	case "OFF", "NONE":
+		return logrus.PanicLevel, true
+	case "FATAL":
+		return logrus.FatalLevel, true
+	case "ERROR":
+		return logrus.ErrorLevel, true
+	case "WARN":
+		return logrus.WarnLevel, true
+	case "INFO":
+		return logrus.InfoLevel, true
+	case "DEBUG":
+		return logrus.DebugLevel, true
+	case "TRACE", "ALL":
+		return logrus.TraceLevel, true
+	}
+	return 0, false
+}
+
 //------------------------------------------------------------------------------
 
 // Noop creates and returns a new logger object that writes nothing.
@@ -142,30 +153,48 @@ func (l *Logger) With(keyValues ...any) Modular {
 
 // Fatal prints a fatal message to the console. Does NOT cause panic.
 func (l *Logger) Fatal(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.FatalLevel, l.fallback) {
+		return
+	}
 	l.entry.Fatalf(strings.TrimSuffix(format, "\n"), v...)
 }
 
 // Error prints an error message to the console.
 func (l *Logger) Error(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.ErrorLevel, l.fallback) {
+		return
+	}
 	l.entry.Errorf(strings.TrimSuffix(format, "\n"), v...)
 }
 
 // Warn prints a warning message to the console.
 func (l *Logger) Warn(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.WarnLevel, l.fallback) {
+		return
+	}
 	l.entry.Warnf(strings.TrimSuffix(format, "\n"), v...)
 }
 
 // Info prints an information message to the console.
 func (l *Logger) Info(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.InfoLevel, l.fallback) {
+		return
+	}
 	l.entry.Infof(strings.TrimSuffix(format, "\n"), v...)
 }
 
 // Debug prints a debug message to the console.
 func (l *Logger) Debug(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.DebugLevel, l.fallback) {
+		return
+	}
 	l.entry.Debugf(strings.TrimSuffix(format, "\n"), v...)
 }
 
 // Trace prints a trace message to the console.
 func (l *Logger) Trace(format string, v ...any) {
+	if l.override != nil && !l.override.enabled(logrus.TraceLevel, l.fallback) {
+		return
+	}
 	l.entry.Tracef(strings.TrimSuffix(format, "\n"), v...)
 }
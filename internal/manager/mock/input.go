@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/message"
@@ -11,7 +12,7 @@ import (
 // Input provides a mocked input implementation.
 type Input struct {
 	TChan     chan message.Transaction
-	closed    bool
+	closed    atomic.Bool
 	closeOnce sync.Once
 }
 
@@ -31,7 +32,7 @@ func NewInput(batches []message.Batch) *Input {
 
 // ConnectionStatus returns the current connection activity.
 func (f *Input) ConnectionStatus() component.ConnectionStatuses {
-	if f.closed {
+	if f.closed.Load() {
 		return component.ConnectionStatuses{
 			component.ConnectionClosed(component.NoopObservability()),
 		}
@@ -50,7 +51,7 @@ func (f *Input) TransactionChan() <-chan message.Transaction {
 func (f *Input) TriggerStopConsuming() {
 	f.closeOnce.Do(func() {
 		close(f.TChan)
-		f.closed = true
+		f.closed.Store(true)
 	})
 }
 
@@ -58,7 +59,7 @@ func (f *Input) TriggerStopConsuming() {
 func (f *Input) TriggerCloseNow() {
 	f.closeOnce.Do(func() {
 		close(f.TChan)
-		f.closed = true
+		f.closed.Store(true)
 	})
 }
 
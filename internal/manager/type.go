@@ -323,6 +323,15 @@ func (t *Type) forStream(id string) *Type {
 	return &newT
 }
 
+// ForStreamWithMetricsLabel returns a variant of this manager to be used by a
+// particular stream identifier, as with ForStream, but where metrics are
+// labelled with the provided value instead of the stream identifier.
+func (t *Type) ForStreamWithMetricsLabel(id, metricsLabel string) bundle.NewManagement {
+	newT := t.forStream(id)
+	newT.stats = t.stats.WithLabels("stream", metricsLabel)
+	return newT
+}
+
 func (t *Type) forLabel(name string) *Type {
 	newT := *t
 	newT.label = name
@@ -373,6 +382,31 @@ func (t *Type) WithAddedMetrics(m metrics.Type) bundle.NewManagement {
 	return &newT
 }
 
+// WithAddedLogger returns a modified version of the manager where log events
+// are emitted to both the current logger as well as the provided one.
+func (t *Type) WithAddedLogger(l log.Modular) bundle.NewManagement {
+	newT := *t
+	newT.logger = log.TeeLogger(t.logger, l)
+	return &newT
+}
+
+// WithLogLevelOverride returns a modified version of the manager where the
+// level of emitted log events is determined by the provided override whilst it
+// is set.
+func (t *Type) WithLogLevelOverride(o *log.LevelOverride) bundle.NewManagement {
+	newT := *t
+	newT.logger = log.WithLevelOverride(t.logger, o)
+	return &newT
+}
+
+// WithTracer returns a modified version of the manager where components trace
+// messages with the provided tracer provider.
+func (t *Type) WithTracer(tracer trace.TracerProvider) bundle.NewManagement {
+	newT := *t
+	newT.tracer = tracer
+	return &newT
+}
+
 //------------------------------------------------------------------------------
 
 // RegisterEndpoint registers a server wide HTTP endpoint.
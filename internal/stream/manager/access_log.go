@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// OptSetAPIAccessLog sets whether a log line is emitted for each request made
+// to the stream manager endpoints, containing the method, path, response status
+// and duration of the request, along with the id of the affected stream for
+// mutations. Request bodies are never logged. This is disabled by default.
+func OptSetAPIAccessLog(b bool) func(*Type) {
+	return func(t *Type) {
+		t.apiAccessLog = b
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (m *Type) wrapAccessLog(h http.HandlerFunc) http.HandlerFunc {
+	if !m.apiAccessLog {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		h(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		fields := map[string]string{
+			"method":   r.Method,
+			"path":     r.URL.Path,
+			"status":   strconv.Itoa(status),
+			"duration": time.Since(start).String(),
+		}
+		if id := mux.Vars(r)["id"]; id != "" && r.Method != "GET" && r.Method != "HEAD" {
+			fields["stream"] = id
+		}
+		m.manager.Logger().WithFields(fields).Info("Handled API request\n")
+	}
+}
@@ -1,17 +1,24 @@
 package manager
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 
 	"github.com/warpstreamlabs/bento/internal/component/cache"
@@ -26,8 +33,23 @@ import (
 	"github.com/warpstreamlabs/bento/public/bloblang"
 )
 
+// Router returns a router serving all of the stream manager endpoints, which
+// allows the API to be served independently of the service wide HTTP server.
+// The endpoints are included regardless of OptAPIEnabled, which only controls
+// whether they are registered with the service wide HTTP server.
+func (m *Type) Router() *mux.Router {
+	return m.routerForMethods(nil)
+}
+
 func (m *Type) registerEndpoints(enableCrud bool) {
-	m.manager.RegisterEndpoint(
+	m.registerEndpointsTo(m.manager.RegisterEndpoint, enableCrud)
+}
+
+func (m *Type) registerEndpointsTo(register func(path, desc string, h http.HandlerFunc), enableCrud bool) {
+	registerEndpoint := func(path, desc string, h http.HandlerFunc) {
+		register(path, desc, m.wrapAccessLog(m.wrapCORS(m.wrapMiddleware(m.wrapTracing(path, m.wrapResponseHeaders(path, m.wrapPretty(h))))).ServeHTTP))
+	}
+	registerEndpoint(
 		"/ready",
 		"Returns 200 OK if the inputs and outputs of all running streams are connected, otherwise a 503 is returned. If there are no active streams 200 is returned.",
 		m.HandleStreamReady,
@@ -35,36 +57,247 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 	if !enableCrud {
 		return
 	}
-	m.manager.RegisterEndpoint(
+	registerEndpoint(
+		"/version",
+		"Returns the service version and build info, along with the features supported by the streams API.",
+		m.HandleVersion,
+	)
+	registerEndpoint(
 		"/resources/{type}/{id}",
-		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
+		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`. DELETE: Remove a resource, which is rejected with 409 Conflict when the resource is referenced by any streams.",
 		m.HandleResourceCRUD,
 	)
-	m.manager.RegisterEndpoint(
+	registerEndpoint(
+		"/streams/schema",
+		"GET a JSON Schema describing the structure of stream configs, including the component types available.",
+		m.HandleStreamSchema,
+	)
+	registerEndpoint(
+		"/streams/normalize",
+		"POST a stream config in order to receive it in a canonical YAML form, where fields are ordered, map keys are sorted and fields matching their default values are omitted.",
+		m.HandleStreamNormalize,
+	)
+	registerEndpoint(
+		"/streams/components",
+		"GET a list of the input, buffer, processor and output types available to stream configs along with the fields of their configs, which can be limited to a type of component with the query parameter type, e.g. type=input.",
+		m.HandleStreamComponents,
+	)
+	registerEndpoint(
+		"/streams/export",
+		"GET a snapshot of all stream configs as a single document keyed by stream ids, which is YAML by default or JSON with the query parameter format=json or an Accept header of application/json. With the query parameter format=tar or an Accept header of application/x-tar the configs are exported as a tar archive of one file per stream, which can be extracted into a directory of stream configs. Snapshots are gzip compressed when requested with the header Accept-Encoding: gzip.",
+		m.HandleStreamsExport,
+	)
+	registerEndpoint(
+		"/streams/import",
+		"POST a snapshot of stream configs obtained from /streams/export, all streams will be replaced by this new set once every config has been validated. Snapshots compressed with gzip are accepted with the header Content-Encoding: gzip.",
+		m.HandleStreamsImport,
+	)
+	registerEndpoint(
+		"/streams/events",
+		"GET a feed of Server-Sent Events describing streams being created, updated, deleted or changing health state. The header Last-Event-ID can be provided in order to resume a feed.",
+		m.HandleStreamEvents,
+	)
+	registerEndpoint(
+		"/streams/reloads",
+		"GET the number of times that the set of streams has been reconciled against a full set of stream configs, along with the time, duration and counts of streams created, updated, deleted, unchanged and failed by the most recent reconcile.",
+		m.HandleStreamReloads,
+	)
+	registerEndpoint(
+		"/streams/maintenance",
+		"GET the maintenance status of the manager, or POST an object of the form {\"enabled\":true} in order to toggle maintenance mode. Whilst in maintenance mode requests to create, update or delete streams are accepted with 202 Accepted and queued, up to a maximum queue size, and are applied in the order they were received once maintenance is lifted.",
+		m.HandleStreamMaintenance,
+	)
+	registerEndpoint(
+		"/streams/templates",
+		"GET a list of the built-in stream templates, which are skeleton stream configs that can be used as a starting point for new streams.",
+		m.HandleStreamTemplates,
+	)
+	registerEndpoint(
+		"/streams/templates/{name}",
+		"GET the skeleton config of a built-in stream template, which is YAML by default or JSON when requested with the Accept header.",
+		m.HandleStreamTemplate,
+	)
+	registerEndpoint(
+		"/streams/pause",
+		"POST to pause the consumption of messages by all streams, where streams remain registered and those already paused remain so. A pause with the query parameter duration resumes the streams automatically once it elapses. Responds with the outcome for each stream.",
+		m.HandleStreamsPause,
+	)
+	registerEndpoint(
+		"/streams/resume",
+		"POST to resume the consumption of messages by all paused streams. Responds with the outcome for each stream.",
+		m.HandleStreamsResume,
+	)
+	registerEndpoint(
+		"/streams/timeout",
+		"GET or PUT the default timeout of requests that modify streams, which bounds the time spent waiting for a stream to shut down when it is updated or deleted, as an object of the form {\"timeout\":\"30s\"}. Changes apply to subsequent requests without restarting the manager.",
+		m.HandleAPITimeout,
+	)
+	registerEndpoint(
+		"/streams/audit",
+		"GET the recent operations that created, updated or deleted streams via the /streams/{id} endpoint, oldest first, along with the time, stream id, outcome and response status of each.",
+		m.HandleStreamAudit,
+	)
+	registerEndpoint(
+		"/streams/quarantine",
+		"GET the streams that have been quarantined after repeatedly failing to be constructed, such as when they are restarted, along with the reason for the most recent failure, the time they were quarantined and the number of consecutive failures. Quarantined streams are not run until they are released via /streams/{id}/unquarantine.",
+		m.HandleStreamsQuarantine,
+	)
+	registerEndpoint(
+		"/streams/selftest",
+		"POST to verify that messages are processed end to end by running a transient stream that generates a known message, processes it and delivers it to an output before being torn down. Responds with whether the test passed, with a status of 503 when it fails.",
+		m.HandleStreamsSelfTest,
+	)
+	registerEndpoint(
+		"/streams/validate",
+		"POST a set of stream configs, either as an object keyed by stream id or as multiple YAML documents, to validate each as a dry run without creating any streams. Responds with whether each config is valid along with its lint errors or the reason it is invalid, with a status of 400 when any config is invalid.",
+		m.HandleStreamsValidate,
+	)
+	registerEndpoint(
+		"/streams/groups/{group}/{action}",
+		"POST to pause, resume or delete all streams of a group, where streams belong to a group by having the label `group` set to its name and the action is one of `pause`, `resume` or `delete`. A pause with the query parameter duration resumes the streams automatically once it elapses. Responds with the outcome for each stream of the group.",
+		m.HandleStreamGroupAction,
+	)
+	registerEndpoint(
+		"/streams/jobs/{jobid}",
+		"GET the status of an asynchronous create or update operation, which is either pending, succeeded or failed.",
+		m.HandleStreamJob,
+	)
+	// Registered ahead of config sections, which would otherwise match it.
+	registerEndpoint(
+		"/streams/{id}/config/raw",
+		"GET the complete config of a stream as it was submitted, including secrets, bypassing any config sanitizer. This is disabled unless the manager allows raw config access, and otherwise responds with 403 Forbidden. Configs are JSON by default, or YAML with the query parameter format=yaml.",
+		m.HandleStreamRawConfig,
+	)
+	registerEndpoint(
+		"/streams/{id}/config/{section}",
+		"GET or PUT (Update) an individual section of a stream config, which is one of `input`, `buffer`, `pipeline` or `output`. Sections are JSON by default, or YAML with the query parameter format=yaml.",
+		m.HandleStreamConfigSection,
+	)
+	registerEndpoint(
+		"/streams/{id}/logs",
+		"GET the most recent log lines emitted by a stream. Provide the query parameter follow=true in order to receive new lines as Server-Sent Events.",
+		m.HandleStreamLogs,
+	)
+	registerEndpoint(
+		"/streams/{id}/loglevel",
+		"GET, POST or DELETE an override of the level at which a stream emits log events, as an object of the form {\"level\":\"DEBUG\",\"ttl\":\"10m\"}, where the optional ttl is a duration after which the stream reverts to the level of the service logger. Changes take effect without restarting the stream.",
+		m.HandleStreamLogLevel,
+	)
+	registerEndpoint(
+		"/streams/{id}/output",
+		"POST an output config in order to replace the output of a running stream without restarting its input, buffer or pipeline. Messages are sent to the new output immediately whilst the previous output is drained, and any messages that it fails to deliver in time are sent to the new output instead.",
+		m.HandleStreamOutput,
+	)
+	registerEndpoint(
+		"/streams/{id}/reload",
+		"POST to read the config file that a stream was loaded from again and update the stream with it, without reconciling other streams. The new version of the stream is swapped in without downtime where possible. Streams that were not loaded from a file are rejected with 400, and a 404 is returned when the file no longer exists.",
+		m.HandleStreamReload,
+	)
+	registerEndpoint(
+		"/streams/{id}/reset",
+		"POST to reset the automatic restart failures of a stream, starting it again if it was marked as failed after repeatedly failing to become ready.",
+		m.HandleStreamReset,
+	)
+	registerEndpoint(
+		"/streams/{id}/unquarantine",
+		"POST to release a stream from quarantine and attempt to start it again. Responds with 409 when the stream is not quarantined, and the stream remains quarantined when it fails to start again.",
+		m.HandleStreamUnquarantine,
+	)
+	registerEndpoint(
+		"/streams/{id}/pause",
+		"POST to pause the consumption of messages from the input of a stream without stopping it. Provide the query parameter duration, such as duration=5m, in order to resume the stream automatically once the duration has elapsed, unless it is resumed beforehand. Pausing a paused stream replaces any scheduled resume.",
+		m.HandleStreamPause,
+	)
+	registerEndpoint(
+		"/streams/{id}/resume",
+		"POST to resume the consumption of messages from the input of a paused stream.",
+		m.HandleStreamResume,
+	)
+	registerEndpoint(
+		"/streams/{id}/ratelimit",
+		"GET, PUT or DELETE the maximum number of messages per second that a stream consumes from its input, as an object of the form {\"messages_per_second\":10}. Changes take effect without restarting the stream.",
+		m.HandleStreamRateLimit,
+	)
+	registerEndpoint(
+		"/streams/{id}/scale",
+		"GET or PUT the number of threads that the pipeline of a stream processes messages with, as an object of the form {\"threads\":4}, where -1 matches the number of logical CPUs. The new version of the stream is swapped in without downtime where possible.",
+		m.HandleStreamScale,
+	)
+	registerEndpoint(
+		"/streams/{id}/diff",
+		"POST a proposed config for a stream in order to receive the fields that would change were the stream updated with it, as lists of added, removed and changed fields identified by dotted paths. Configs are compared in their sanitized forms.",
+		m.HandleStreamDiff,
+	)
+	registerEndpoint(
 		"/streams/{id}/stats",
 		"GET a structured JSON object containing metrics for the stream.",
 		m.HandleStreamStats,
 	)
-	m.manager.RegisterEndpoint(
+	registerEndpoint(
 		"/streams/{id}",
 		"Perform CRUD operations on streams, supporting POST (Create),"+
 			" GET (Read), PUT (Update), PATCH (Patch update)"+
 			" and DELETE (Delete).",
-		m.HandleStreamCRUD,
+		m.wrapAudit(m.HandleStreamCRUD),
 	)
-	m.manager.RegisterEndpoint(
+	registerEndpoint(
 		"/streams",
-		"GET: List all streams along with their status and uptimes."+
+		"GET: List all streams along with their status and uptimes,"+
+			" which can be filtered by labels with the query parameter"+
+			" label=key:value, or by the component types used anywhere"+
+			" within their configs, including within brokers and"+
+			" switches, with the query parameters input_type,"+
+			" processor_type and output_type. With the query parameter compat=legacy, or"+
+			" the header X-Bento-Compat: legacy, the fields of each status"+
+			" are named as in the legacy schema, e.g. running rather than"+
+			" active. With the query parameter shape=array streams are"+
+			" listed as an array ordered by id, where each status has the"+
+			" id of its stream as the field id, rather than as an object"+
+			" keyed by id. Arrays can instead be ordered with the query"+
+			" parameter sort, which is one of id, uptime or state, and is"+
+			" reversed when prefixed with -, e.g. sort=-uptime lists the"+
+			" longest running streams first."+
 			" POST: Post an object of stream ids to stream configs, all"+
-			" streams will be replaced by this new set.",
+			" streams will be replaced by this new set, responding with the"+
+			" outcome of each stream. With the query parameter partial=true"+
+			" invalid streams are reported as failed rather than rejecting"+
+			" the set, and the response status is 207 when some streams"+
+			" failed."+
+			" DELETE: Remove all streams, which requires the query"+
+			" parameter all=true, responding with the ids of the removed"+
+			" streams.",
 		m.HandleStreamsCRUD,
 	)
 }
 
+// parseLabels parses labels of the form `key:value`, as provided via query
+// parameters.
+func parseLabels(values []string) (map[string]string, error) {
+	labels := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("label '%v' must be of the form key:value", v)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
 type lintErrors struct {
 	LintErrs []string `json:"lint_errors"`
 }
 
+type streamConnections struct {
+	Input  []string `json:"input,omitempty" yaml:"input,omitempty"`
+	Output []string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+type streamBufferDepth struct {
+	Messages int `json:"messages" yaml:"messages"`
+	Bytes    int `json:"bytes" yaml:"bytes"`
+}
+
 func (m *Type) lintCtx() docs.LintContext {
 	lConf := docs.NewLintConfig(m.manager.Environment())
 	lConf.BloblangEnv = bloblang.XWrapEnvironment(m.manager.BloblEnvironment()).Deactivated()
@@ -78,9 +311,59 @@ func (m *Type) lintStreamConfigNode(node *yaml.Node) (lints []string) {
 	return
 }
 
+// canonicalStreamConfig returns a canonical form of a stream config, where
+// configs that are functionally identical but differ cosmetically, such as in
+// the ordering of fields or the inclusion of default values, are equal.
+func (m *Type) canonicalStreamConfig(v any) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return "", err
+	}
+
+	sanitConf := docs.NewSanitiseConfig(m.manager.Environment())
+	sanitConf.RemoveTypeField = true
+	sanitConf.RemoveDefaults = true
+	if err := stream.Spec().SanitiseYAML(&node, sanitConf); err != nil {
+		return "", err
+	}
+
+	// Marshalling the generic form of a config as JSON orders map keys.
+	var generic any
+	if err := node.Decode(&generic); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+	return string(canonical), nil
+}
+
+// duplicateStreamConfigs returns groups of stream ids where the configs of each
+// group are functionally identical, ignoring cosmetic differences such as the
+// ordering of fields, from stream ids grouped by their canonical configs.
+func duplicateStreamConfigs(byConfig map[string][]string) (groups [][]string) {
+	for _, ids := range byConfig {
+		if len(ids) > 1 {
+			ids = append([]string(nil), ids...)
+			sort.Strings(ids)
+			groups = append(groups, ids)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return
+}
+
 // HandleStreamsCRUD is an http.HandleFunc for returning maps of active bento
 // streams by their id, status and uptime or overwriting the entire set of
-// streams.
+// streams. Streams are listed as an array ordered by id, where each status
+// embeds the id of its stream, when the query parameter shape=array is given,
+// or in the order requested with the query parameter sort.
+// The streams listed can be limited to those using a component type anywhere
+// within their config, including within brokers and switches, with the query
+// parameters input_type, processor_type and output_type.
 func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	var serverErr, requestErr error
 	defer func() {
@@ -99,168 +382,501 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	type confInfo struct {
-		Active    bool    `json:"active"`
-		Uptime    float64 `json:"uptime"`
-		UptimeStr string  `json:"uptime_str"`
+	var labelFilter map[string]string
+	if labelFilter, requestErr = parseLabels(r.URL.Query()["label"]); requestErr != nil {
+		return
 	}
-	infos := map[string]confInfo{}
 
-	m.lock.Lock()
-	for id, strInfo := range m.streams {
-		infos[id] = confInfo{
-			Active:    strInfo.IsRunning(),
-			Uptime:    strInfo.Uptime().Seconds(),
-			UptimeStr: strInfo.Uptime().String(),
-		}
+	componentFilter := parseComponentFilter(r)
+
+	var compatMode string
+	if compatMode, requestErr = requestCompatMode(r); requestErr != nil {
+		return
+	}
+
+	var listShape string
+	if listShape, requestErr = requestListShape(r); requestErr != nil {
+		return
+	}
+
+	var sortKey string
+	var sortDesc bool
+	if sortKey, sortDesc, requestErr = requestListSort(r); requestErr != nil {
+		return
+	}
+	if sortKey != "" && listShape != listShapeArray {
+		requestErr = errors.New("the query parameter sort requires the query parameter shape=array")
+		return
+	}
+
+	type confInfo struct {
+		Active      bool              `json:"active"`
+		State       string            `json:"state"`
+		Uptime      float64           `json:"uptime"`
+		UptimeStr   string            `json:"uptime_str"`
+		InputType   string            `json:"input_type"`
+		OutputType  string            `json:"output_type"`
+		Rate        streamRate        `json:"rate"`
+		Labels      map[string]string `json:"labels,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Origin      string            `json:"origin"`
+		OriginPath  string            `json:"origin_path,omitempty"`
 	}
-	m.lock.Unlock()
 
 	switch r.Method {
 	case "GET":
+		// The status of each stream is collected from a snapshot in order to
+		// avoid blocking mutations whilst querying a large number of streams.
+		streams := m.snapshotStreams()
+		infos := make(map[string]confInfo, len(streams))
+		for id, strInfo := range streams {
+			if !strInfo.HasLabels(labelFilter) {
+				continue
+			}
+			conf := strInfo.Config()
+			if len(componentFilter) > 0 {
+				var uses bool
+				if uses, serverErr = m.usesComponents(conf, componentFilter); serverErr != nil {
+					return
+				}
+				if !uses {
+					continue
+				}
+			}
+			uptime := strInfo.Uptime()
+			origin, originPath := strInfo.Origin()
+			infos[id] = confInfo{
+				Active:      strInfo.IsRunning(),
+				State:       strInfo.State(),
+				Uptime:      uptime.Seconds(),
+				UptimeStr:   uptime.String(),
+				InputType:   conf.Input.Type,
+				OutputType:  conf.Output.Type,
+				Rate:        strInfo.rate(),
+				Labels:      strInfo.Labels(),
+				Description: strInfo.Description(),
+				Origin:      origin,
+				OriginPath:  originPath,
+			}
+		}
+
+		var served any = infos
+		if compatMode == compatModeLegacy {
+			legacyInfos := make(map[string]map[string]any, len(infos))
+			for id, info := range infos {
+				if legacyInfos[id], serverErr = withLegacyStatusFields(info); serverErr != nil {
+					return
+				}
+			}
+			served = legacyInfos
+		}
+		if listShape == listShapeArray {
+			var compare func(a, b string) int
+			if sortKey != "" {
+				compare = func(a, b string) (c int) {
+					switch sortKey {
+					case listSortID:
+						c = strings.Compare(a, b)
+					case listSortUptime:
+						c = cmp.Compare(infos[a].Uptime, infos[b].Uptime)
+					case listSortState:
+						c = strings.Compare(infos[a].State, infos[b].State)
+					}
+					if sortDesc {
+						c = -c
+					}
+					return
+				}
+			}
+			if served, serverErr = listAsArray(served, compare); serverErr != nil {
+				return
+			}
+		}
+
 		var resBytes []byte
-		if resBytes, serverErr = json.Marshal(infos); serverErr == nil {
+		if resBytes, serverErr = json.Marshal(served); serverErr == nil {
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write(resBytes)
 		}
-		return
 	case "POST":
+		requestErr = m.setStreams(w, r)
+	case "DELETE":
+		// Removing every stream is destructive enough to require explicit
+		// confirmation.
+		if r.URL.Query().Get("all") != "true" {
+			requestErr = errors.New("deleting all streams requires the query parameter all=true")
+			return
+		}
+
+		ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+		defer done()
+
+		deleted, errs := m.deleteAll(ctx)
+		if deleted == nil {
+			deleted = []string{}
+		}
+
+		body := struct {
+			Deleted []string `json:"deleted"`
+			Errors  []string `json:"errors,omitempty"`
+		}{
+			Deleted: deleted,
+		}
+		for _, err := range errs {
+			m.manager.Logger().Error("Streams CRUD Error: %v\n", err)
+			body.Errors = append(body.Errors, err.Error())
+		}
+
+		resBytes, _ := json.Marshal(body)
+		w.Header().Set("Content-Type", "application/json")
+		if len(errs) > 0 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		_, _ = w.Write(resBytes)
 	default:
 		requestErr = errors.New("method not supported")
-		return
 	}
+}
 
-	var setBytes []byte
-	if setBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+// HandleStreamsExport is an http.HandleFunc for exporting the configs of all
+// streams as a single document keyed by stream identifiers. The document is
+// YAML by default, or JSON when the query parameter format=json is provided or
+// JSON is accepted, and can be restored with HandleStreamsImport. When a tar
+// archive is accepted, or the query parameter format=tar is provided, the
+// configs are instead exported as an archive of one file per stream, which
+// can be extracted into a directory of stream configs. The response is gzip
+// compressed when the request accepts it with the Accept-Encoding header.
+func (m *Type) HandleStreamsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
 		return
 	}
 
-	nodeSet := map[string]yaml.Node{}
-	if requestErr = yaml.Unmarshal(setBytes, &nodeSet); requestErr != nil {
-		return
+	streams := m.snapshotStreams()
+	snapshot := make(map[string]any, len(streams))
+	for id, strInfo := range streams {
+		// Registered streams have no config that could be imported.
+		if strInfo.origin == StreamOriginRegistered {
+			continue
+		}
+		conf, err := m.servedConfig(strInfo.Config())
+		if err != nil {
+			m.manager.Logger().Error("Streams export Error: %v\n", err)
+			http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+			return
+		}
+		snapshot[id] = conf
 	}
 
-	if r.URL.Query().Get("chilled") != "true" {
-		var lints []string
-		for k, n := range nodeSet {
-			for _, l := range m.lintStreamConfigNode(&n) {
-				keyLint := fmt.Sprintf("stream '%v': %v", k, l)
-				lints = append(lints, keyLint)
-				m.manager.Logger().Debug("Streams request linting error: %v\n", keyLint)
-			}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		if acceptsTar(r) {
+			format = exportFormatTar
+		} else {
+			format, _ = responseConfigFormat(r, configFormatYAML)
 		}
-		if len(lints) > 0 {
-			errBytes, _ := json.Marshal(lintErrors{
-				LintErrs: lints,
-			})
+	}
+
+	var resBytes []byte
+	var err error
+	switch format {
+	case exportFormatTar:
+		if resBytes, err = streamsExportTar(snapshot); err == nil {
+			w.Header().Set("Content-Type", "application/x-tar")
+		}
+	case "yaml":
+		if resBytes, err = yaml.Marshal(snapshot); err == nil {
+			w.Header().Set("Content-Type", "application/yaml")
+		}
+	case "json":
+		if resBytes, err = json.Marshal(snapshot); err == nil {
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = w.Write(errBytes)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("Error: unsupported format: %v", format), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		m.manager.Logger().Error("Streams export Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+	_ = writeNegotiatedBody(w, r, resBytes)
+}
+
+// HandleStreamsImport is an http.HandleFunc for restoring the streams of the
+// manager from a document obtained with HandleStreamsExport. As with setting
+// streams via HandleStreamsCRUD, all configs are validated before any streams
+// are modified, and existing streams absent from the document are removed.
+// Documents compressed with gzip are accepted with the Content-Encoding
+// header, up to the maximum decompressed body size of the manager.
+func (m *Type) HandleStreamsImport(w http.ResponseWriter, r *http.Request) {
+	var requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Streams import request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
 			return
 		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
 	}
+	if requestErr = m.decompressRequestBody(r); requestErr != nil {
+		return
+	}
+	if requestErr = m.setStreams(w, r); requestErr != nil && bodyLimitExceeded(r) {
+		http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusRequestEntityTooLarge)
+		requestErr = nil
+	}
+}
 
-	toDelete := []string{}
-	toUpdate := map[string]stream.Config{}
-	toCreate := map[string]stream.Config{}
+// Possible outcomes of each stream within a set of streams.
+const (
+	streamSetCreated   = "created"
+	streamSetUpdated   = "updated"
+	streamSetUnchanged = "unchanged"
+	streamSetDeleted   = "deleted"
+	streamSetFailed    = "failed"
+)
 
+type streamSetOutcome struct {
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// setStreams replaces the set of streams of the manager with a set of stream
+// configs read from the body of a request, keyed by their identifiers.
+// Validation of all configs is completed before any existing streams are
+// modified, unless the query parameter partial=true is provided, in which case
+// streams with invalid configs are reported as failed and the remaining streams
+// are applied on a best-effort basis, leaving any existing streams of failed
+// ids untouched.
+//
+// Streams are validated and parsed as they are decoded from the body, which is
+// decoded incrementally when it is JSON, such that large sets of streams are
+// not held in memory in their raw form. The response body maps each stream id
+// to its outcome, with a status of 207 Multi-Status when some streams failed
+// and others did not.
+func (m *Type) setStreams(w http.ResponseWriter, r *http.Request) (requestErr error) {
+	m.lock.Lock()
+	existing := make(map[string]struct{}, len(m.streams))
+	for id := range m.streams {
+		existing[id] = struct{}{}
+	}
+	m.lock.Unlock()
+
+	partial := r.URL.Query().Get("partial") == "true"
+	chilled := r.URL.Query().Get("chilled") == "true"
+
+	var outcomesMut sync.Mutex
+	outcomes := map[string]streamSetOutcome{}
+	fail := func(id string, err error) {
+		outcomesMut.Lock()
+		outcomes[id] = streamSetOutcome{Outcome: streamSetFailed, Error: err.Error()}
+		outcomesMut.Unlock()
+	}
+
+	// Each stream is validated and parsed as soon as it is decoded, and only
+	// the parsed configs are retained until every stream has been read.
+	setIDs := map[string]struct{}{}
+	toApply := map[string]stream.Config{}
+	byConfig := map[string][]string{}
 	spec := stream.Spec()
 
-	for id := range infos {
-		newConf, exists := nodeSet[id]
-		if !exists {
-			toDelete = append(toDelete, id)
-		} else {
-			var rawSource any
-			if requestErr = newConf.Decode(&rawSource); requestErr != nil {
-				return
+	var lints []string
+	var parseErr error
+	if requestErr = decodeStreamSet(r, func(id string, node *yaml.Node) error {
+		setIDs[id] = struct{}{}
+		if m.maxStreams > 0 && len(setIDs) > m.maxStreams {
+			return errStreamSetTooLarge
+		}
+
+		if _, err := stream.MigrateYAML(node); err != nil {
+			if partial {
+				fail(id, err)
+			} else if parseErr == nil {
+				parseErr = err
 			}
-			var pConf *docs.ParsedConfig
-			if pConf, requestErr = spec.ParsedConfigFromAny(&newConf); requestErr != nil {
-				return
+			return nil
+		}
+
+		if !chilled {
+			var streamLints []string
+			for _, l := range m.lintStreamConfigNode(node) {
+				keyLint := fmt.Sprintf("stream '%v': %v", id, l)
+				streamLints = append(streamLints, l)
+				lints = append(lints, keyLint)
+				m.manager.Logger().Debug("Streams request linting error: %v\n", keyLint)
 			}
-			if toUpdate[id], requestErr = stream.FromParsed(m.manager.Environment(), pConf, rawSource); requestErr != nil {
-				return
+			if len(streamLints) > 0 {
+				if partial {
+					fail(id, fmt.Errorf("lint errors: %v", strings.Join(streamLints, "; ")))
+				}
+				return nil
 			}
 		}
-	}
-	for id, conf := range nodeSet {
-		if _, exists := infos[id]; !exists {
-			var rawSource any
-			if requestErr = conf.Decode(&rawSource); requestErr != nil {
-				return
-			}
+
+		if canonical, err := m.canonicalStreamConfig(node); err == nil {
+			byConfig[canonical] = append(byConfig[canonical], id)
+		}
+
+		var rawSource any
+		err := node.Decode(&rawSource)
+		if err == nil {
 			var pConf *docs.ParsedConfig
-			if pConf, requestErr = spec.ParsedConfigFromAny(&conf); requestErr != nil {
-				return
+			if pConf, err = spec.ParsedConfigFromAny(node); err == nil {
+				toApply[id], err = stream.FromParsed(m.manager.Environment(), pConf, rawSource)
 			}
-			if toCreate[id], requestErr = stream.FromParsed(m.manager.Environment(), pConf, rawSource); requestErr != nil {
-				return
+		}
+		if err != nil {
+			delete(toApply, id)
+			if partial {
+				fail(id, err)
+			} else if parseErr == nil {
+				parseErr = err
 			}
 		}
+		return nil
+	}); requestErr != nil {
+		if errors.Is(requestErr, errStreamSetTooLarge) {
+			http.Error(w, fmt.Sprintf("Stream set exceeds the maximum of %v streams", m.maxStreams), http.StatusTooManyRequests)
+			requestErr = nil
+		}
+		return
+	}
+
+	if len(lints) > 0 && !partial {
+		errBytes, _ := json.Marshal(lintErrors{
+			LintErrs: lints,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(errBytes)
+		return
+	}
+
+	if dupes := duplicateStreamConfigs(byConfig); len(dupes) > 0 {
+		for _, ids := range dupes {
+			m.manager.Logger().Warn("Streams %v have identical configs, which could result in data being processed more than once\n", ids)
+		}
+		if m.strictDuplicates {
+			http.Error(w, fmt.Sprintf("Stream set contains streams with identical configs: %v", dupes), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if parseErr != nil {
+		requestErr = parseErr
+		return
+	}
+
+	toDelete := []string{}
+	for id := range existing {
+		if _, exists := setIDs[id]; !exists {
+			toDelete = append(toDelete, id)
+		}
 	}
 
+	// Deletions are completed before creating new streams so that the new set
+	// is not rejected by a stream limit due to streams that are being removed.
 	wg := sync.WaitGroup{}
 	wg.Add(len(toDelete))
-	wg.Add(len(toUpdate))
-	wg.Add(len(toCreate))
-
-	errDelete := make([]error, len(toDelete))
-	errUpdate := make([]error, len(toUpdate))
-	errCreate := make([]error, len(toCreate))
-
-	for i, id := range toDelete {
-		go func(sid string, j int) {
-			errDelete[j] = m.Delete(r.Context(), sid)
-			wg.Done()
-		}(id, i)
-	}
-	i := 0
-	for id, conf := range toUpdate {
-		newConf := conf
-		go func(sid string, sconf *stream.Config, j int) {
-			errUpdate[j] = m.Update(r.Context(), sid, *sconf)
-			wg.Done()
-		}(id, &newConf, i)
-		i++
-	}
-	i = 0
-	for id, conf := range toCreate {
-		newConf := conf
-		go func(sid string, sconf *stream.Config, j int) {
-			errCreate[j] = m.Create(sid, *sconf)
-			wg.Done()
-		}(id, &newConf, i)
-		i++
+	for _, id := range toDelete {
+		go func(sid string) {
+			defer wg.Done()
+			if err := m.Delete(r.Context(), sid); err != nil {
+				fail(sid, fmt.Errorf("failed to delete stream: %w", err))
+				return
+			}
+			outcomesMut.Lock()
+			outcomes[sid] = streamSetOutcome{Outcome: streamSetDeleted}
+			outcomesMut.Unlock()
+		}(id)
 	}
-
 	wg.Wait()
 
-	errs := []string{}
-	for _, err := range errDelete {
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("failed to delete stream: %v", err))
-		}
+	wg.Add(len(toApply))
+	for id, conf := range toApply {
+		_, wasRunning := existing[id]
+		go func(sid string, sconf stream.Config) {
+			defer wg.Done()
+			changed, err := m.Apply(r.Context(), sid, sconf)
+			if err != nil {
+				if wasRunning {
+					fail(sid, fmt.Errorf("failed to update stream: %w", err))
+				} else {
+					fail(sid, fmt.Errorf("failed to create stream: %w", err))
+				}
+				return
+			}
+			outcome := streamSetUnchanged
+			if !wasRunning {
+				outcome = streamSetCreated
+			} else if changed {
+				outcome = streamSetUpdated
+			}
+			outcomesMut.Lock()
+			outcomes[sid] = streamSetOutcome{Outcome: outcome}
+			outcomesMut.Unlock()
+		}(id, conf)
 	}
-	for _, err := range errUpdate {
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("failed to update stream: %v", err))
+	wg.Wait()
+
+	var failed, succeeded int
+	for id, o := range outcomes {
+		if o.Outcome == streamSetFailed {
+			m.manager.Logger().Error("Failed to set stream '%v': %v\n", id, o.Error)
+			failed++
+		} else {
+			succeeded++
 		}
 	}
-	for _, err := range errCreate {
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("failed to create stream: %v", err))
+
+	status := http.StatusOK
+	if failed > 0 {
+		status = http.StatusBadRequest
+		if succeeded > 0 {
+			status = http.StatusMultiStatus
 		}
 	}
 
-	if len(errs) > 0 {
-		requestErr = errors.New(strings.Join(errs, "\n"))
+	resBytes, err := json.Marshal(outcomes)
+	if err != nil {
+		requestErr = err
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(resBytes)
+	return
 }
 
 // HandleStreamCRUD is an http.HandleFunc for performing CRUD operations on
-// individual streams.
+// individual streams. A POST made with an Idempotency-Key header that repeats
+// a previously successful request receives the original response. Whilst the
+// manager is in maintenance mode mutations are queued rather than applied.
 func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
+	if m.queueMutation(w, r) {
+		return
+	}
+	m.serveStreamCRUD(w, r)
+}
+
+func (m *Type) serveStreamCRUD(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" && m.idempotencyWindow > 0 && r.Header.Get(idempotencyKeyHeader) != "" {
+		m.serveIdempotent(w, r, m.handleStreamCRUD)
+		return
+	}
+	m.handleStreamCRUD(w, r)
+}
+
+func (m *Type) handleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 	var serverErr, requestErr error
 	defer func() {
 		if r.Body != nil {
@@ -284,11 +900,13 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var streamOpts []StreamOpt
 	readConfig := func() (confOut stream.Config, lints []string, err error) {
 		var confBytes []byte
 		if confBytes, err = io.ReadAll(r.Body); err != nil {
 			return
 		}
+		streamOpts = append(streamOpts, streamOptConfigFormat(requestConfigFormat(r, confBytes)))
 
 		ignoreLints := r.URL.Query().Get("chilled") == "true"
 
@@ -302,9 +920,27 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var node *yaml.Node
-		if node, err = docs.UnmarshalYAML(confBytes); err != nil {
+		if tmplName := r.URL.Query().Get("template"); tmplName != "" {
+			var partial *yaml.Node
+			if len(bytes.TrimSpace(confBytes)) > 0 {
+				if partial, err = docs.UnmarshalYAML(confBytes); err != nil {
+					return
+				}
+			}
+			if node, err = m.applyStreamTemplate(tmplName, partial); err != nil {
+				return
+			}
+		} else if node, err = docs.UnmarshalYAML(confBytes); err != nil {
+			return
+		}
+		if _, err = stream.MigrateYAML(node); err != nil {
 			return
 		}
+		if overrides := r.URL.Query()["set"]; len(overrides) > 0 {
+			if err = m.applyConfigOverrides(node, overrides); err != nil {
+				return
+			}
+		}
 
 		if !ignoreLints {
 			lints = m.lintStreamConfigNode(node)
@@ -313,14 +949,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		var rawSource any
-		_ = node.Decode(&rawSource)
-
-		var pConf *docs.ParsedConfig
-		if pConf, err = stream.Spec().ParsedConfigFromAny(node); err != nil {
-			return
-		}
-		confOut, err = stream.FromParsed(m.manager.Environment(), pConf, rawSource)
+		confOut, err = m.streamConfigFromNode(node)
 		return
 	}
 	patchConfig := func(confIn stream.Config) (confOut stream.Config, err error) {
@@ -356,6 +985,70 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+
+	if metricsLabel, exists := r.URL.Query()["metrics_label"]; exists {
+		streamOpts = append(streamOpts, StreamOptMetricsLabel(metricsLabel[0]))
+	}
+	if labelValues, exists := r.URL.Query()["label"]; exists {
+		var labels map[string]string
+		if labels, requestErr = parseLabels(labelValues); requestErr != nil {
+			return
+		}
+		streamOpts = append(streamOpts, StreamOptLabels(labels))
+	}
+	if description, exists := r.URL.Query()["description"]; exists {
+		streamOpts = append(streamOpts, StreamOptDescription(description[0]))
+	}
+	if dependsOn, exists := r.URL.Query()["depends_on"]; exists {
+		streamOpts = append(streamOpts, StreamOptDependsOn(dependsOn...))
+	}
+	if maxUptimeStr := r.URL.Query().Get("max_uptime"); maxUptimeStr != "" {
+		var maxUptime time.Duration
+		if maxUptime, requestErr = time.ParseDuration(maxUptimeStr); requestErr != nil {
+			requestErr = fmt.Errorf("failed to parse max_uptime: %w", requestErr)
+			return
+		}
+		if maxUptime < 0 {
+			requestErr = errors.New("max_uptime must not be negative")
+			return
+		}
+		streamOpts = append(streamOpts, StreamOptMaxUptime(maxUptime))
+	}
+	if stallTimeoutStr := r.URL.Query().Get("stall_timeout"); stallTimeoutStr != "" {
+		var stallTimeout time.Duration
+		if stallTimeout, requestErr = time.ParseDuration(stallTimeoutStr); requestErr != nil {
+			requestErr = fmt.Errorf("failed to parse stall_timeout: %w", requestErr)
+			return
+		}
+		if stallTimeout < 0 {
+			requestErr = errors.New("stall_timeout must not be negative")
+			return
+		}
+		whileInputActive := true
+		if v := r.URL.Query().Get("stall_while_input_active"); v != "" {
+			if whileInputActive, requestErr = strconv.ParseBool(v); requestErr != nil {
+				requestErr = fmt.Errorf("failed to parse stall_while_input_active: %w", requestErr)
+				return
+			}
+		}
+		streamOpts = append(streamOpts, StreamOptStallTimeout(stallTimeout, whileInputActive))
+	}
+	if v := r.URL.Query().Get("global_processors"); v != "" {
+		var enabled bool
+		if enabled, requestErr = strconv.ParseBool(v); requestErr != nil {
+			requestErr = fmt.Errorf("failed to parse global_processors: %w", requestErr)
+			return
+		}
+		streamOpts = append(streamOpts, StreamOptGlobalProcessors(enabled))
+	}
+	if trace.SpanContextFromContext(r.Context()).IsValid() {
+		streamOpts = append(streamOpts, streamOptTraceParent(r.Context()))
+	}
+
+	async := r.URL.Query().Get("async") == "true"
+
 	var conf stream.Config
 	var lints []string
 	switch r.Method {
@@ -372,29 +1065,184 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Create(id, conf)
+		if r.URL.Query().Get("dry_run") == "true" {
+			if err := m.ValidateConfig(conf); err != nil {
+				requestErr = err
+			}
+			return
+		}
+		start := !m.manualStart
+		if startStr := r.URL.Query().Get("start"); startStr != "" {
+			start = startStr == "true"
+		}
+		if r.URL.Query().Get("if_exists") == "update" {
+			var changed bool
+			if changed, serverErr = m.apply(ctx, id, conf, start, streamOpts...); serverErr == nil {
+				resBytes, _ := json.Marshal(struct {
+					Changed bool `json:"changed"`
+				}{
+					Changed: changed,
+				})
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(resBytes)
+			}
+			break
+		}
+		create := func(ctx context.Context) error {
+			return m.create(id, conf, nil, start, streamOpts...)
+		}
+		if timeoutStr := r.URL.Query().Get("connect_timeout"); timeoutStr != "" {
+			var connectTimeout time.Duration
+			if connectTimeout, requestErr = time.ParseDuration(timeoutStr); requestErr != nil {
+				requestErr = fmt.Errorf("failed to parse connect_timeout: %w", requestErr)
+				return
+			}
+			if connectTimeout <= 0 {
+				requestErr = errors.New("connect_timeout must be greater than zero")
+				return
+			}
+			create = func(ctx context.Context) error {
+				return m.createWithConnectTimeout(ctx, id, conf, start, connectTimeout, streamOpts...)
+			}
+		}
+		if async {
+			serverErr = m.runJob(w, id, jobOperationCreate, create)
+			return
+		}
+		serverErr = create(ctx)
 	case "GET":
+		var compatMode string
+		if compatMode, requestErr = requestCompatMode(r); requestErr != nil {
+			return
+		}
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
-			conf := info.Config()
-			sanit := conf.GetRawSource()
+			lastModified := info.LastModified()
+			if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(ims) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
 
-			var bodyBytes []byte
-			if bodyBytes, serverErr = json.Marshal(struct {
-				Active    bool    `json:"active"`
-				Uptime    float64 `json:"uptime"`
-				UptimeStr string  `json:"uptime_str"`
-				Config    any     `json:"config"`
+			var sanit any
+			if sanit, serverErr = m.servedConfig(info.Config()); serverErr != nil {
+				return
+			}
+
+			var connections *streamConnections
+			if inAddrs, outAddrs := info.ConnectionAddresses(); len(inAddrs) > 0 || len(outAddrs) > 0 {
+				connections = &streamConnections{
+					Input:  inAddrs,
+					Output: outAddrs,
+				}
+			}
+
+			// Streams without a buffer capable of reporting its depth report
+			// null for both fields.
+			var bufferDepth *streamBufferDepth
+			var backpressure *bool
+			if depth, ok := info.BufferDepth(); ok {
+				bufferDepth = &streamBufferDepth{
+					Messages: depth.Messages,
+					Bytes:    depth.Bytes,
+				}
+				backpressure = &depth.Backpressure
+			}
+
+			origin, originPath := info.Origin()
+
+			var maxUptime string
+			if d := info.MaxUptime(); d > 0 {
+				maxUptime = d.String()
+			}
+			var stallTimeout string
+			if d, _ := info.StallTimeout(); d > 0 {
+				stallTimeout = d.String()
+			}
+			var nextRestart string
+			if restartAt, ok := info.NextRestart(); ok {
+				nextRestart = restartAt.UTC().Format(time.RFC3339)
+			}
+
+			var pauseRemaining *float64
+			if remaining, ok := info.PauseRemaining(); ok {
+				seconds := remaining.Seconds()
+				pauseRemaining = &seconds
+			}
+
+			body := struct {
+				Active          bool               `json:"active" yaml:"active"`
+				State           string             `json:"state" yaml:"state"`
+				Paused          bool               `json:"paused,omitempty" yaml:"paused,omitempty"`
+				PauseRemaining  *float64           `json:"pause_remaining,omitempty" yaml:"pause_remaining,omitempty"`
+				Uptime          float64            `json:"uptime" yaml:"uptime"`
+				UptimeStr       string             `json:"uptime_str" yaml:"uptime_str"`
+				RestartFailures int                `json:"restart_failures,omitempty" yaml:"restart_failures,omitempty"`
+				RestartCount    int                `json:"restart_count,omitempty" yaml:"restart_count,omitempty"`
+				MaxUptime       string             `json:"max_uptime,omitempty" yaml:"max_uptime,omitempty"`
+				NextRestart     string             `json:"next_restart,omitempty" yaml:"next_restart,omitempty"`
+				StallTimeout    string             `json:"stall_timeout,omitempty" yaml:"stall_timeout,omitempty"`
+				Parallelism     int                `json:"parallelism" yaml:"parallelism"`
+				Rate            streamRate         `json:"rate" yaml:"rate"`
+				BufferDepth     *streamBufferDepth `json:"buffer_depth" yaml:"buffer_depth"`
+				Backpressure    *bool              `json:"backpressure" yaml:"backpressure"`
+				MetricsLabel    string             `json:"metrics_label,omitempty" yaml:"metrics_label,omitempty"`
+				Labels          map[string]string  `json:"labels,omitempty" yaml:"labels,omitempty"`
+				Description     string             `json:"description,omitempty" yaml:"description,omitempty"`
+				DependsOn       []string           `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+				Origin          string             `json:"origin" yaml:"origin"`
+				OriginPath      string             `json:"origin_path,omitempty" yaml:"origin_path,omitempty"`
+				Connections     *streamConnections `json:"connections,omitempty" yaml:"connections,omitempty"`
+				Config          any                `json:"config" yaml:"config"`
 			}{
-				Active:    info.IsRunning(),
-				Uptime:    info.Uptime().Seconds(),
-				UptimeStr: info.Uptime().String(),
-				Config:    sanit,
-			}); serverErr != nil {
+				Active:          info.IsRunning(),
+				State:           info.State(),
+				Paused:          info.IsPaused(),
+				PauseRemaining:  pauseRemaining,
+				Uptime:          info.Uptime().Seconds(),
+				UptimeStr:       info.Uptime().String(),
+				RestartFailures: info.RestartFailures(),
+				RestartCount:    info.RestartCount(),
+				MaxUptime:       maxUptime,
+				NextRestart:     nextRestart,
+				StallTimeout:    stallTimeout,
+				Parallelism:     info.Parallelism(),
+				Rate:            info.rate(),
+				BufferDepth:     bufferDepth,
+				Backpressure:    backpressure,
+				MetricsLabel:    info.MetricsLabel(),
+				Labels:          info.Labels(),
+				Description:     info.Description(),
+				DependsOn:       info.DependsOn(),
+				Origin:          origin,
+				OriginPath:      originPath,
+				Connections:     connections,
+				Config:          sanit,
+			}
+
+			// Configs are served in the format that they were submitted in
+			// unless the client asks otherwise.
+			format, contentType := responseConfigFormat(r, info.getConfigFormat())
+
+			var served any = body
+			if compatMode == compatModeLegacy {
+				if served, serverErr = withLegacyStatusFields(body); serverErr != nil {
+					return
+				}
+			}
+
+			var bodyBytes []byte
+			if format == configFormatYAML {
+				bodyBytes, serverErr = yaml.Marshal(served)
+			} else {
+				bodyBytes, serverErr = json.Marshal(served)
+			}
+			if serverErr != nil {
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Add("Vary", "Accept")
+			w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
 			_, _ = w.Write(bodyBytes)
 		}
 	case "PUT":
@@ -410,30 +1258,46 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Update(r.Context(), id, conf)
+		update := m.Update
+		if r.URL.Query().Get("zero_downtime") == "true" {
+			update = m.Swap
+		}
+		if async {
+			serverErr = m.runJob(w, id, jobOperationUpdate, func(ctx context.Context) error {
+				return update(ctx, id, conf, streamOpts...)
+			})
+			return
+		}
+		serverErr = update(ctx, id, conf, streamOpts...)
 	case "DELETE":
-		serverErr = m.Delete(r.Context(), id)
+		switch {
+		case r.URL.Query().Get("force") == "true":
+			serverErr = m.ForceDelete(ctx, id)
+		case r.URL.Query().Get("deadletter") == "true":
+			var dlConf output.Config
+			if dlConf, requestErr = m.deadLetterConfigFromRequest(r); requestErr != nil {
+				return
+			}
+			if serverErr = m.DeleteWithDeadLetter(ctx, id, dlConf); errors.Is(serverErr, errOutputDiversionDisabled) {
+				requestErr, serverErr = serverErr, nil
+			}
+		default:
+			serverErr = m.Delete(ctx, id)
+		}
 	case "PATCH":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
 			if conf, requestErr = patchConfig(info.Config()); requestErr != nil {
 				return
 			}
-			serverErr = m.Update(r.Context(), id, conf)
+			serverErr = m.Update(ctx, id, conf, streamOpts...)
 		}
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
 	}
 
-	if serverErr == ErrStreamDoesNotExist {
+	if writeStreamError(w, serverErr) {
 		serverErr = nil
-		http.Error(w, "Stream not found", http.StatusNotFound)
-		return
-	}
-	if serverErr == ErrStreamExists {
-		serverErr = nil
-		http.Error(w, "Stream already exists", http.StatusBadRequest)
-		return
 	}
 }
 
@@ -457,7 +1321,7 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	if r.Method != "POST" {
+	if r.Method != "POST" && r.Method != "DELETE" {
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
 		return
 	}
@@ -470,6 +1334,23 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	if r.Method == "DELETE" {
+		switch docType := docs.Type(mux.Vars(r)["type"]); docType {
+		case docs.TypeCache, docs.TypeInput, docs.TypeOutput, docs.TypeProcessor, docs.TypeRateLimit:
+			var inUseErr ErrResourceInUse
+			if err := m.RemoveResource(ctx, docType, id); errors.Is(err, ErrResourceDoesNotExist) {
+				http.Error(w, "Resource not found", http.StatusNotFound)
+			} else if errors.As(err, &inUseErr) {
+				http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusConflict)
+			} else {
+				serverErr = err
+			}
+		default:
+			http.Error(w, "Var `type` must be set to one of `cache`, `input`, `output`, `processor` or `rate_limit`", http.StatusBadRequest)
+		}
+		return
+	}
+
 	var storeFn func(*yaml.Node)
 
 	docType := docs.Type(mux.Vars(r)["type"])
@@ -624,25 +1505,21 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
 	}
-	if serverErr == ErrStreamDoesNotExist {
+	if writeStreamError(w, serverErr) {
 		serverErr = nil
-		http.Error(w, "Stream not found", http.StatusNotFound)
-		return
 	}
 }
 
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
-// all streams.
+// all streams, where streams reconnecting within the unhealthy grace period
+// are considered ready.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
 	var notReady []string
-
-	m.lock.Lock()
-	for k, v := range m.streams {
-		if !v.IsReady() && v.IsRunning() {
+	for k, v := range m.snapshotStreams() {
+		if !v.IsReady() && v.IsRunning() && !v.IsReconnecting() {
 			notReady = append(notReady, k)
 		}
 	}
-	m.lock.Unlock()
 
 	if len(notReady) == 0 {
 		_, _ = w.Write([]byte("OK"))
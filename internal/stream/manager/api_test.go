@@ -1,8 +1,13 @@
 package manager_test
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,7 +15,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,27 +26,74 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
 	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/log"
 	bmanager "github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/stream"
 	"github.com/warpstreamlabs/bento/internal/stream/manager"
 
+	_ "github.com/warpstreamlabs/bento/public/components/aws"
 	_ "github.com/warpstreamlabs/bento/public/components/io"
+	_ "github.com/warpstreamlabs/bento/public/components/kafka"
+	_ "github.com/warpstreamlabs/bento/public/components/nanomsg"
 	_ "github.com/warpstreamlabs/bento/public/components/pure"
 )
 
 func router(m *manager.Type) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/ready", m.HandleStreamReady)
+	router.HandleFunc("/version", m.HandleVersion)
 	router.HandleFunc("/streams", m.HandleStreamsCRUD)
+	router.HandleFunc("/streams/schema", m.HandleStreamSchema)
+	router.HandleFunc("/streams/normalize", m.HandleStreamNormalize)
+	router.HandleFunc("/streams/components", m.HandleStreamComponents)
+	router.HandleFunc("/streams/events", m.HandleStreamEvents)
+	router.HandleFunc("/streams/export", m.HandleStreamsExport)
+	router.HandleFunc("/streams/import", m.HandleStreamsImport)
+	router.HandleFunc("/streams/reloads", m.HandleStreamReloads)
+	router.HandleFunc("/streams/maintenance", m.HandleStreamMaintenance)
+	router.HandleFunc("/streams/templates", m.HandleStreamTemplates)
+	router.HandleFunc("/streams/templates/{name}", m.HandleStreamTemplate)
+	router.HandleFunc("/streams/pause", m.HandleStreamsPause)
+	router.HandleFunc("/streams/resume", m.HandleStreamsResume)
+	router.HandleFunc("/streams/validate", m.HandleStreamsValidate)
+	router.HandleFunc("/streams/audit", m.HandleStreamAudit)
+	router.HandleFunc("/streams/quarantine", m.HandleStreamsQuarantine)
+	router.HandleFunc("/streams/timeout", m.HandleAPITimeout)
+	router.HandleFunc("/streams/selftest", m.HandleStreamsSelfTest)
+	router.HandleFunc("/streams/groups/{group}/{action}", m.HandleStreamGroupAction)
+	router.HandleFunc("/streams/jobs/{jobid}", m.HandleStreamJob)
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
+	router.HandleFunc("/streams/{id}/diff", m.HandleStreamDiff)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
+	router.HandleFunc("/streams/{id}/logs", m.HandleStreamLogs)
+	router.HandleFunc("/streams/{id}/loglevel", m.HandleStreamLogLevel)
+	router.HandleFunc("/streams/{id}/pause", m.HandleStreamPause)
+	router.HandleFunc("/streams/{id}/resume", m.HandleStreamResume)
+	router.HandleFunc("/streams/{id}/reload", m.HandleStreamReload)
+	router.HandleFunc("/streams/{id}/reset", m.HandleStreamReset)
+	router.HandleFunc("/streams/{id}/unquarantine", m.HandleStreamUnquarantine)
+	router.HandleFunc("/streams/{id}/ratelimit", m.HandleStreamRateLimit)
+	router.HandleFunc("/streams/{id}/scale", m.HandleStreamScale)
+	router.HandleFunc("/streams/{id}/config/raw", m.HandleStreamRawConfig)
+	router.HandleFunc("/streams/{id}/output", m.HandleStreamOutput)
+	router.HandleFunc("/streams/{id}/config/{section}", m.HandleStreamConfigSection)
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
 	return router
 }
@@ -90,9 +145,11 @@ func genYAMLRequest(verb, url string, payload any) *http.Request {
 }
 
 type listItemBody struct {
-	Active    bool    `json:"active"`
-	Uptime    float64 `json:"uptime"`
-	UptimeStr string  `json:"uptime_str"`
+	Active     bool    `json:"active"`
+	Uptime     float64 `json:"uptime"`
+	UptimeStr  string  `json:"uptime_str"`
+	InputType  string  `json:"input_type"`
+	OutputType string  `json:"output_type"`
 }
 
 type listBody map[string]listItemBody
@@ -150,6 +207,85 @@ func TestTypeAPIDisabled(t *testing.T) {
 	assert.Contains(t, r.endpoints, "/ready")
 }
 
+func TestTypeAPICORS(t *testing.T) {
+	r := &endpointReg{endpoints: map[string]http.HandlerFunc{}}
+	rMgr, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(r))
+	require.NoError(t, err)
+
+	_ = manager.New(rMgr, manager.OptSetCORS([]string{"http://dashboard.example.com"}))
+
+	request := genRequest("OPTIONS", "/streams", nil)
+	request.Header.Set("Origin", "http://dashboard.example.com")
+	request.Header.Set("Access-Control-Request-Method", "PUT")
+	request.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	response := httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "http://dashboard.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "PUT", response.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", response.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Credentials"))
+
+	request = genRequest("GET", "/streams", nil)
+	request.Header.Set("Origin", "http://dashboard.example.com")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "http://dashboard.example.com", response.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "{}", response.Body.String())
+	assert.Contains(t, response.Header().Get("Access-Control-Expose-Headers"), "Last-Modified")
+	assert.Contains(t, response.Header().Get("Access-Control-Expose-Headers"), "Idempotent-Replayed")
+
+	request = genRequest("OPTIONS", "/streams", nil)
+	request.Header.Set("Origin", "http://dashboard.example.com")
+	request.Header.Set("Access-Control-Request-Method", "POST")
+	request.Header.Set("Access-Control-Request-Headers", "Content-Type, Idempotency-Key, X-Bento-Compat")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "Content-Type,Idempotency-Key,X-Bento-Compat", response.Header().Get("Access-Control-Allow-Headers"))
+
+	request = genRequest("OPTIONS", "/streams", nil)
+	request.Header.Set("Origin", "http://dashboard.example.com")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	request.Header.Set("Access-Control-Request-Headers", "Authorization")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	request = genRequest("GET", "/streams", nil)
+	request.Header.Set("Origin", "http://evil.example.com")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, response.Header().Get("Access-Control-Allow-Origin"))
+
+	r = &endpointReg{endpoints: map[string]http.HandlerFunc{}}
+	rMgr, err = bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(r))
+	require.NoError(t, err)
+
+	_ = manager.New(rMgr,
+		manager.OptSetCORS([]string{"*"}),
+		manager.OptSetCORSAllowedHeaders([]string{"Authorization"}),
+	)
+
+	request = genRequest("GET", "/streams", nil)
+	request.Header.Set("Origin", "http://anywhere.example.com")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "*", response.Header().Get("Access-Control-Allow-Origin"))
+
+	request = genRequest("OPTIONS", "/streams", nil)
+	request.Header.Set("Origin", "http://anywhere.example.com")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	request.Header.Set("Access-Control-Request-Headers", "Authorization")
+	response = httptest.NewRecorder()
+	r.endpoints["/streams"](response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "Authorization", response.Header().Get("Access-Control-Allow-Headers"))
+}
+
 func TestTypeAPIBadMethods(t *testing.T) {
 	mgr := manager.New(mock.NewManager())
 
@@ -210,7 +346,7 @@ func TestTypeAPIBasicOperations(t *testing.T) {
 	request = genRequest("POST", "/streams/foo", conf)
 	response = httptest.NewRecorder()
 	r.ServeHTTP(response, request)
-	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, http.StatusConflict, response.Code)
 
 	assert.Eventually(t, func() bool {
 		request = genRequest("GET", "/ready", nil)
@@ -296,6 +432,135 @@ func TestTypeAPIBasicOperations(t *testing.T) {
 	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
 }
 
+func TestTypeAPIConditionalGet(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	lastModified := response.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	request = genRequest("GET", "/streams/foo", nil)
+	request.Header.Set("If-Modified-Since", lastModified)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotModified, response.Code)
+	assert.Empty(t, response.Body.String())
+
+	newConf := harmlessConf()
+	_, _ = gabs.Wrap(newConf).Set("memory", "buffer", "type")
+
+	request = genRequest("PUT", "/streams/foo", newConf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo", nil)
+	request.Header.Set("If-Modified-Since", lastModified)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotEqual(t, lastModified, response.Header().Get("Last-Modified"))
+
+	info := parseGetBody(t, response.Body)
+	assert.Equal(t, "memory", gabs.Wrap(info.Config).S("buffer", "type").Data())
+}
+
+func TestTypeAPIMaxStreams(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetMaxStreams(1))
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams", map[string]any{
+		"bar": harmlessConf(),
+		"baz": harmlessConf(),
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusTooManyRequests, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams", map[string]any{
+		"bar": harmlessConf(),
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	_, err = mgr.Read("bar")
+	assert.NoError(t, err)
+}
+
+func TestTypeAPIManualStart(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo?start=false", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var info map[string]struct {
+		Active bool   `json:"active"`
+		State  string `json:"state"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+	assert.False(t, info["foo"].Active)
+	assert.Equal(t, "pending", info["foo"].State)
+	assert.True(t, info["bar"].Active)
+	assert.Equal(t, "running", info["bar"].State)
+
+	require.NoError(t, mgr.Start("foo"))
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.True(t, parseGetBody(t, response.Body).Active)
+}
+
 func TestTypeAPIPatch(t *testing.T) {
 	res, err := bmanager.New(bmanager.NewResourceConfig())
 	require.NoError(t, err)
@@ -372,7 +637,7 @@ func TestTypeAPIBasicOperationsYAML(t *testing.T) {
 	request = genYAMLRequest("POST", "/streams/foo", conf)
 	response = httptest.NewRecorder()
 	r.ServeHTTP(response, request)
-	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, http.StatusConflict, response.Code)
 
 	request = genYAMLRequest("GET", "/streams/bar", nil)
 	response = httptest.NewRecorder()
@@ -458,6 +723,28 @@ output:
 	if exp, act := true, info["foo"].Active; !reflect.DeepEqual(exp, act) {
 		t.Errorf("Wrong list response: %v != %v", act, exp)
 	}
+	assert.Equal(t, "generate", info["foo"].InputType)
+	assert.Equal(t, "drop", info["foo"].OutputType)
+
+	conf, err = testutil.StreamFromYAML(`
+input:
+  inproc: foo
+output:
+  inproc: bar
+`)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Create("bar", conf))
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	info = parseListBody(response.Body)
+	assert.Equal(t, "generate", info["foo"].InputType)
+	assert.Equal(t, "drop", info["foo"].OutputType)
+	assert.Equal(t, "inproc", info["bar"].InputType)
+	assert.Equal(t, "inproc", info["bar"].OutputType)
 }
 
 func TestTypeAPISetStreams(t *testing.T) {
@@ -541,6 +828,77 @@ output:
 	assert.Equal(t, "root = this.BAZ_ONE", gabs.Wrap(conf.Config).S("input", "generate", "mapping").Data())
 }
 
+func TestTypeAPISetStreamsPartialFailure(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	bazConf := harmlessConf()
+	_, _ = gabs.Wrap(bazConf).Set("root = this.BAZ", "input", "generate", "mapping")
+
+	streamsBody := map[string]any{
+		"foo": harmlessConf(),
+		"baz": bazConf,
+		"buz": map[string]any{
+			"input": map[string]any{
+				"not_a_real_input": map[string]any{},
+			},
+			"output": map[string]any{
+				"drop": map[string]any{},
+			},
+		},
+	}
+
+	// Without partial application the invalid stream rejects the whole set.
+	request = genRequest("POST", "/streams", streamsBody)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	_, err = mgr.Read("bar")
+	require.NoError(t, err)
+
+	request = genRequest("POST", "/streams?partial=true", streamsBody)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMultiStatus, response.Code, response.Body.String())
+
+	var outcomes map[string]struct {
+		Outcome string `json:"outcome"`
+		Error   string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &outcomes), response.Body.String())
+	require.Len(t, outcomes, 4)
+	assert.Equal(t, "unchanged", outcomes["foo"].Outcome)
+	assert.Equal(t, "deleted", outcomes["bar"].Outcome)
+	assert.Equal(t, "created", outcomes["baz"].Outcome)
+	assert.Equal(t, "failed", outcomes["buz"].Outcome)
+	assert.Contains(t, outcomes["buz"].Error, "unable to infer component type")
+
+	_, err = mgr.Read("baz")
+	require.NoError(t, err)
+	_, err = mgr.Read("buz")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+}
+
 func testConfToAny(t testing.TB, conf any) any {
 	var node yaml.Node
 	err := node.Encode(conf)
@@ -827,6 +1185,103 @@ func TestResourceAPILinting(t *testing.T) {
 	}
 }
 
+func TestTypeAPISchema(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/streams/schema", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	schema, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+
+	for _, k := range []string{"input", "buffer", "pipeline", "output"} {
+		assert.True(t, schema.Exists("properties", k), k)
+	}
+	assert.Equal(t, "#/definitions/input", schema.S("properties", "input", "$ref").Data())
+	assert.Contains(t, schema.S("definitions", "input", "allOf", "1", "properties", "type", "enum").Data(), "generate")
+	assert.Contains(t, schema.S("definitions", "output", "allOf", "1", "properties", "type", "enum").Data(), "drop")
+
+	request = genRequest("POST", "/streams/schema", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestTypeAPIComponents(t *testing.T) {
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &mock.Input{TChan: make(chan message.Transaction)}, nil
+	}, docs.ComponentSpec{
+		Name:    "custom_input",
+		Summary: "A custom input.",
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("address", "An address to connect to."),
+		),
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	componentNames := func(c *gabs.Container, ctype string) (names []string) {
+		for _, v := range c.S(ctype).Children() {
+			names = append(names, v.S("name").Data().(string))
+		}
+		return
+	}
+
+	request := genRequest("GET", "/streams/components", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	components, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+
+	assert.Contains(t, componentNames(components, "input"), "nanomsg")
+	assert.Contains(t, componentNames(components, "input"), "custom_input")
+	assert.Contains(t, componentNames(components, "buffer"), "memory")
+	assert.Contains(t, componentNames(components, "processor"), "bloblang")
+	assert.Contains(t, componentNames(components, "output"), "nanomsg")
+
+	for _, v := range components.S("input").Children() {
+		if v.S("name").Data() != "custom_input" {
+			continue
+		}
+		assert.Equal(t, "A custom input.", v.S("summary").Data())
+		assert.Equal(t, "address", v.S("config", "children", "0", "name").Data())
+		assert.Equal(t, "string", v.S("config", "children", "0", "type").Data())
+	}
+
+	request = genRequest("GET", "/streams/components?type=input", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	components, err = gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+
+	assert.Len(t, components.ChildrenMap(), 1)
+	assert.Contains(t, componentNames(components, "input"), "nanomsg")
+
+	request = genRequest("GET", "/streams/components?type=nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+}
+
 func TestTypeAPIGetStats(t *testing.T) {
 	mgr, err := bmanager.New(bmanager.NewResourceConfig())
 	require.NoError(t, err)
@@ -960,34 +1415,135 @@ file:
 	assert.Equal(t, `{"id":"second","content":"hello world 2"}`, string(file2Bytes))
 }
 
-func TestAPIReady(t *testing.T) {
-	res, err := bmanager.New(bmanager.NewResourceConfig())
+func TestTypeAPIDeleteResources(t *testing.T) {
+	bmgr, err := bmanager.New(bmanager.NewResourceConfig())
 	require.NoError(t, err)
 
-	mgr := manager.New(res)
+	mgr := manager.New(bmgr)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
 
 	r := router(mgr)
 
-	request := genRequest("POST", "/streams/foo", `
-input:
-  generate:
-    count: 1
-    mapping: 'root = {}'
-    interval: ""
-
-output:
-  drop: {}
+	request := genYAMLRequest("POST", "/resources/cache/foocache", `
+memory: {}
 `)
 	response := httptest.NewRecorder()
 	r.ServeHTTP(response, request)
-	assert.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
 
-	assert.Eventually(t, func() bool {
-		request = genRequest("GET", "/ready", nil)
-		response = httptest.NewRecorder()
-		r.ServeHTTP(response, request)
-		return response.Code == http.StatusOK
-	}, time.Second*10, time.Millisecond*50)
+	request = genYAMLRequest("POST", "/resources/input/fooinput", `
+generate:
+  mapping: 'root = deleted()'
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	for _, id := range []string{"foo", "bar"} {
+		request = genYAMLRequest("POST", "/streams/"+id, `
+input:
+  generate:
+    mapping: 'root = deleted()'
+pipeline:
+  processors:
+    - cache:
+        resource: foocache
+        operator: get
+        key: '${! content() }'
+output:
+  drop: {}
+`)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request = genYAMLRequest("POST", "/streams/baz", `
+input:
+  resource: fooinput
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/resources/cache/foocache", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "resource is in use by streams: bar, foo")
+
+	request = genRequest("DELETE", "/resources/input/fooinput", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "resource is in use by streams: baz")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+
+	request = genRequest("DELETE", "/resources/cache/foocache", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "resource is in use by streams: bar")
+
+	require.NoError(t, mgr.Delete(ctx, "bar"))
+	require.NoError(t, mgr.Delete(ctx, "baz"))
+
+	request = genRequest("DELETE", "/resources/cache/foocache", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.False(t, bmgr.ProbeCache("foocache"))
+
+	request = genRequest("DELETE", "/resources/input/fooinput", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.False(t, bmgr.ProbeInput("fooinput"))
+
+	request = genRequest("DELETE", "/resources/cache/foocache", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestAPIReady(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", `
+input:
+  generate:
+    count: 1
+    mapping: 'root = {}'
+    interval: ""
+
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	assert.Eventually(t, func() bool {
+		request = genRequest("GET", "/ready", nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response.Code == http.StatusOK
+	}, time.Second*10, time.Millisecond*50)
 
 	request = genRequest("POST", "/streams/bar", `
 input:
@@ -1011,3 +1567,4863 @@ output:
 		return response.Code == http.StatusServiceUnavailable
 	}, time.Second*10, time.Millisecond*50)
 }
+
+type addressedMockInput struct {
+	*mock.Input
+	addrs []string
+}
+
+func (a *addressedMockInput) ConnectionAddresses() []string {
+	return a.addrs
+}
+
+func TestTypeAPIConnectionAddresses(t *testing.T) {
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &addressedMockInput{
+			Input: &mock.Input{TChan: make(chan message.Transaction)},
+			addrs: []string{"broker-1.example.com:9092"},
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "addressed_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"addressed_input": map[string]any{},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var info struct {
+		Connections *struct {
+			Input  []string `json:"input"`
+			Output []string `json:"output"`
+		} `json:"connections"`
+	}
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+	require.NotNil(t, info.Connections)
+	assert.Equal(t, []string{"broker-1.example.com:9092"}, info.Connections.Input)
+	assert.Empty(t, info.Connections.Output)
+
+	info.Connections = nil
+	request = genRequest("GET", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+	assert.Nil(t, info.Connections)
+}
+
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func readSSEEvents(t testing.TB, body io.Reader) <-chan sseEvent {
+	t.Helper()
+
+	eventsChan := make(chan sseEvent)
+	go func() {
+		defer close(eventsChan)
+
+		var current sseEvent
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if current.Event != "" {
+					eventsChan <- current
+				}
+				current = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				current.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				current.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				current.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return eventsChan
+}
+
+func nextSSEEvent(t testing.TB, events <-chan sseEvent) sseEvent {
+	t.Helper()
+
+	select {
+	case e, open := <-events:
+		require.True(t, open, "event feed closed")
+		return e
+	case <-time.After(time.Second * 10):
+		t.Fatal("timed out waiting for event")
+	}
+	return sseEvent{}
+}
+
+func TestTypeAPIStreamEvents(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	server := httptest.NewServer(router(mgr))
+	t.Cleanup(server.Close)
+
+	feedRes, err := http.Get(server.URL + "/streams/events")
+	require.NoError(t, err)
+	t.Cleanup(func() { feedRes.Body.Close() })
+
+	require.Equal(t, http.StatusOK, feedRes.StatusCode)
+	assert.Equal(t, "text/event-stream", feedRes.Header.Get("Content-Type"))
+
+	events := readSSEEvents(t, feedRes.Body)
+
+	createRes, err := http.Post(server.URL+"/streams/foo", "application/json", bytes.NewReader(gabs.Wrap(harmlessConf()).Bytes()))
+	require.NoError(t, err)
+	createRes.Body.Close()
+	require.Equal(t, http.StatusOK, createRes.StatusCode)
+
+	e := nextSSEEvent(t, events)
+	assert.Equal(t, "created", e.Event)
+	assert.Equal(t, "1", e.ID)
+
+	var data struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Status struct {
+			Active bool   `json:"active"`
+			State  string `json:"state"`
+		} `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(e.Data), &data))
+	assert.Equal(t, "foo", data.ID)
+	assert.Equal(t, "created", data.Type)
+	assert.True(t, data.Status.Active)
+	assert.Equal(t, "running", data.Status.State)
+
+	deleteReq, err := http.NewRequest("DELETE", server.URL+"/streams/foo", http.NoBody)
+	require.NoError(t, err)
+	deleteRes, err := http.DefaultClient.Do(deleteReq)
+	require.NoError(t, err)
+	deleteRes.Body.Close()
+	require.Equal(t, http.StatusOK, deleteRes.StatusCode)
+
+	// The stream shutting down results in a health event prior to deletion.
+	for e = nextSSEEvent(t, events); e.Event == "health"; e = nextSSEEvent(t, events) {
+	}
+	assert.Equal(t, "deleted", e.Event)
+	deletedID := e.ID
+
+	// Resuming the feed from the create event should replay the remaining
+	// events.
+	resumeReq, err := http.NewRequest("GET", server.URL+"/streams/events", http.NoBody)
+	require.NoError(t, err)
+	resumeReq.Header.Set("Last-Event-ID", "1")
+	resumeRes, err := http.DefaultClient.Do(resumeReq)
+	require.NoError(t, err)
+	t.Cleanup(func() { resumeRes.Body.Close() })
+	require.Equal(t, http.StatusOK, resumeRes.StatusCode)
+
+	resumed := readSSEEvents(t, resumeRes.Body)
+	for e = nextSSEEvent(t, resumed); e.Event == "health"; e = nextSSEEvent(t, resumed) {
+	}
+	assert.Equal(t, "deleted", e.Event)
+	assert.Equal(t, deletedID, e.ID)
+}
+
+type stuckMockInput struct {
+	*mock.Input
+	release chan struct{}
+}
+
+func (s *stuckMockInput) WaitForClose(ctx context.Context) error {
+	<-s.release
+	return nil
+}
+
+func TestTypeAPIForceDelete(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &stuckMockInput{
+			Input:   &mock.Input{TChan: make(chan message.Transaction)},
+			release: release,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Millisecond*100))
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"stuck_input": map[string]any{},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// Deletions without force remain graceful.
+	request = genRequest("DELETE", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/streams/foo?force=true", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIExportImport(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	fooConf := `
+input:
+  generate:
+    mapping: 'root = "foo"'
+    interval: 1s
+output:
+  drop: {}
+`
+	barConf := `
+input:
+  inproc: bar_in
+output:
+  inproc: bar_out
+`
+
+	for id, conf := range map[string]string{"foo": fooConf, "bar": barConf} {
+		request := genYAMLRequest("POST", "/streams/"+id, conf)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	getConfig := func(id string) any {
+		t.Helper()
+
+		request := genRequest("GET", "/streams/"+id, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return parseGetBody(t, response.Body).Config
+	}
+	origFoo, origBar := getConfig("foo"), getConfig("bar")
+
+	request := genRequest("GET", "/streams/export", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+	snapshot := response.Body.String()
+
+	request = genRequest("GET", "/streams/export?format=json", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	var jsonSnapshot map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &jsonSnapshot))
+	assert.Len(t, jsonSnapshot, 2)
+
+	for _, id := range []string{"foo", "bar"} {
+		request = genRequest("DELETE", "/streams/"+id, nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, listBody{}, parseListBody(response.Body))
+
+	request = genYAMLRequest("POST", "/streams/import", snapshot)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Equal(t, origFoo, getConfig("foo"))
+	assert.Equal(t, origBar, getConfig("bar"))
+
+	// Invalid snapshots are rejected before any streams are modified.
+	request = genYAMLRequest("POST", "/streams/import", `
+foo:
+  input:
+    nope: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	assert.Equal(t, origFoo, getConfig("foo"))
+	assert.Equal(t, origBar, getConfig("bar"))
+}
+
+func TestTypeAPIExportImportGzip(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetMaxDecompressedBodySize(64*1024))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for _, id := range []string{"foo", "bar"} {
+		request := genRequest("POST", "/streams/"+id, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request := genRequest("GET", "/streams/export?format=json", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Empty(t, response.Header().Get("Content-Encoding"))
+	plainSnapshot := response.Body.Bytes()
+
+	request = genRequest("GET", "/streams/export?format=json", nil)
+	request.Header.Set("Accept-Encoding", "gzip;q=0")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Empty(t, response.Header().Get("Content-Encoding"))
+
+	request = genRequest("GET", "/streams/export?format=json", nil)
+	request.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "gzip", response.Header().Get("Content-Encoding"))
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	compressedSnapshot := response.Body.Bytes()
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressedSnapshot))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(plainSnapshot), string(decompressed))
+
+	for _, id := range []string{"foo", "bar"} {
+		require.NoError(t, mgr.Delete(context.Background(), id))
+	}
+
+	request = genRequest("POST", "/streams/import", nil)
+	request.Body = io.NopCloser(bytes.NewReader(compressedSnapshot))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Encoding", "gzip")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	for _, id := range []string{"foo", "bar"} {
+		_, err := mgr.Read(id)
+		assert.NoError(t, err, id)
+	}
+
+	// Bodies that decompress beyond the limit are rejected without modifying
+	// any streams.
+	var bomb bytes.Buffer
+	gw := gzip.NewWriter(&bomb)
+	_, err = gw.Write(append(bytes.Repeat([]byte(" "), 1<<20), "{}"...))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	for _, contentType := range []string{"application/json", "application/yaml"} {
+		request = genRequest("POST", "/streams/import", nil)
+		request.Body = io.NopCloser(bytes.NewReader(bomb.Bytes()))
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Content-Encoding", "gzip")
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code, response.Body.String())
+	}
+
+	request = genRequest("POST", "/streams/import", nil)
+	request.Body = io.NopCloser(bytes.NewReader(plainSnapshot))
+	request.Header.Set("Content-Encoding", "br")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/import", nil)
+	request.Body = io.NopCloser(bytes.NewReader(plainSnapshot))
+	request.Header.Set("Content-Encoding", "gzip")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	for _, id := range []string{"foo", "bar"} {
+		_, err := mgr.Read(id)
+		assert.NoError(t, err, id)
+	}
+}
+
+func TestTypeAPIExportTar(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for _, id := range []string{"foo", "bar_baz"} {
+		request := genYAMLRequest("POST", "/streams/"+id, fmt.Sprintf(`
+input:
+  inproc: %v_in
+output:
+  drop: {}
+`, id))
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request := genRequest("GET", "/streams/export", nil)
+	request.Header.Set("Accept", "application/x-tar")
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/x-tar", response.Header().Get("Content-Type"))
+
+	dir := t.TempDir()
+	var names []string
+	tr := tar.NewReader(response.Body)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+
+		entryBytes, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, hdr.Name), entryBytes, 0o644))
+	}
+	assert.Equal(t, []string{"bar_baz.yaml", "foo.yaml"}, names)
+
+	confs, lints, err := config.LoadStreamConfigsFromDirectories([]string{dir})
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+	require.Len(t, confs, 2)
+
+	for _, id := range []string{"foo", "bar_baz"} {
+		info, err := mgr.Read(id)
+		require.NoError(t, err)
+		require.Contains(t, confs, id)
+		assert.Equal(t, info.Config().Input.Type, confs[id].Input.Type, id)
+		assert.Equal(t, id+"_in", confs[id].Input.Plugin.(*yaml.Node).Value, id)
+	}
+
+	request = genRequest("GET", "/streams/export", nil)
+	request.Header.Set("Accept", "application/json")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+}
+
+func TestTypeAPIStreamOrigin(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	dir := t.TempDir()
+	fooPath := filepath.Join(dir, "foo.yaml")
+	require.NoError(t, os.WriteFile(fooPath, []byte(`
+input:
+  generate:
+    mapping: 'root = "foo"'
+    interval: 1s
+output:
+  drop: {}
+`), 0o644))
+
+	_, err = mgr.ReloadFromDirectory(context.Background(), dir)
+	require.NoError(t, err)
+
+	request := genRequest("POST", "/streams/bar", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type originBody struct {
+		Origin     string `json:"origin"`
+		OriginPath string `json:"origin_path"`
+	}
+
+	getOrigin := func(id string) originBody {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/"+id, nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var body originBody
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		return body
+	}
+	assert.Equal(t, originBody{Origin: "directory", OriginPath: fooPath}, getOrigin("foo"))
+	assert.Equal(t, originBody{Origin: "api"}, getOrigin("bar"))
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var list map[string]originBody
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+	assert.Equal(t, map[string]originBody{
+		"foo": {Origin: "directory", OriginPath: fooPath},
+		"bar": {Origin: "api"},
+	}, list)
+}
+
+func TestTypeAPIStreamReload(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	dir := t.TempDir()
+	writeStream := func(name, mapping string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`
+input:
+  generate:
+    mapping: '%v'
+    interval: 1s
+output:
+  drop: {}
+`, mapping)), 0o644))
+		return path
+	}
+	fooPath := writeStream("foo.yaml", `root = "foo"`)
+	writeStream("baz.yaml", `root = "baz"`)
+
+	_, err = mgr.ReloadFromDirectory(context.Background(), dir)
+	require.NoError(t, err)
+
+	request := genRequest("POST", "/streams/bar", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	bazBefore, err := mgr.Read("baz")
+	require.NoError(t, err)
+
+	getMapping := func(id string) any {
+		t.Helper()
+		info, err := mgr.Read(id)
+		require.NoError(t, err)
+		return gabs.Wrap(testConfToAny(t, info.Config())).S("input", "generate", "mapping").Data()
+	}
+
+	writeStream("foo.yaml", `root = "foo edited"`)
+	writeStream("baz.yaml", `root = "baz edited"`)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/reload", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Equal(t, `root = "foo edited"`, getMapping("foo"))
+
+	// Only the reloaded stream is modified.
+	assert.Equal(t, `root = "baz"`, getMapping("baz"))
+	bazAfter, err := mgr.Read("baz")
+	require.NoError(t, err)
+	assert.Same(t, bazBefore, bazAfter)
+
+	// The origin of the reloaded stream is retained.
+	fooInfo, err := mgr.Read("foo")
+	require.NoError(t, err)
+	origin, originPath := fooInfo.Origin()
+	assert.Equal(t, manager.StreamOriginDirectory, origin)
+	assert.Equal(t, fooPath, originPath)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/bar/reload", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/nope/reload", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	require.NoError(t, os.Remove(fooPath))
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/reload", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), fooPath)
+	assert.Equal(t, `root = "foo edited"`, getMapping("foo"))
+}
+
+func TestTypeAPIConfigOverrides(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	conf := `
+input:
+  generate:
+    mapping: 'root = "foo"'
+    interval: 1s
+buffer:
+  memory: {}
+output:
+  drop: {}
+`
+
+	request := genYAMLRequest("POST", "/streams/foo?set=buffer.memory.limit=100000&set=input.generate.interval=5s", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo", nil)
+	request.Header.Set("Accept", "application/json")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	served, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 100000.0, served.Path("config.buffer.memory.limit").Data())
+	assert.Equal(t, "5s", served.Path("config.input.generate.interval").Data())
+
+	for _, override := range []string{
+		"buffer.memory.limit=lots",
+		"buffer.memory.nope=10",
+		"buffer.memory.limit",
+	} {
+		request = genYAMLRequest("POST", "/streams/bar?set="+override, conf)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusBadRequest, response.Code, override)
+	}
+
+	_, err = mgr.Read("bar")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+}
+
+func TestTypeAPIVersion(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetBuildInfo("v1.2.3", "2024-01-02T03:04:05Z"))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/version", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	var body struct {
+		Version   string   `json:"version"`
+		Built     string   `json:"built"`
+		Commit    string   `json:"commit"`
+		GoVersion string   `json:"go_version"`
+		Features  []string `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "v1.2.3", body.Version)
+	assert.Equal(t, "2024-01-02T03:04:05Z", body.Built)
+	assert.NotEmpty(t, body.Commit)
+	assert.NotEmpty(t, body.GoVersion)
+	assert.Contains(t, body.Features, "export_tar")
+
+	// Without build info a version is still reported.
+	bareMgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, bareMgr.Stop(ctx))
+	})
+	response = httptest.NewRecorder()
+	router(bareMgr).ServeHTTP(response, genRequest("GET", "/version", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.NotEmpty(t, body.Version)
+}
+
+type syncBuffer struct {
+	mut sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.buf.String()
+}
+
+func TestTypeAPIAccessLog(t *testing.T) {
+	logConf := log.NewConfig()
+	logConf.AddTimeStamp = false
+	logConf.Format = "logfmt"
+	logConf.LogLevel = "INFO"
+
+	var logBuf syncBuffer
+	logger, err := log.New(&logBuf, ifs.OS(), logConf)
+	require.NoError(t, err)
+
+	r := &endpointReg{endpoints: map[string]http.HandlerFunc{}}
+	rMgr, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(r), bmanager.OptSetLogger(logger))
+	require.NoError(t, err)
+
+	mgr := manager.New(rMgr, manager.OptSetAPIAccessLog(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    mapping: 'root = "very secret value"'
+output:
+  drop: {}
+`)
+	request = mux.SetURLVars(request, map[string]string{"id": "foo"})
+	response := httptest.NewRecorder()
+	r.endpoints["/streams/{id}"](response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	logStr := logBuf.String()
+	assert.Contains(t, logStr, `msg="Handled API request"`)
+	assert.Contains(t, logStr, "method=POST")
+	assert.Contains(t, logStr, "path=/streams/foo")
+	assert.Contains(t, logStr, "status=200")
+	assert.Contains(t, logStr, "stream=foo")
+	assert.NotContains(t, logStr, "very secret value")
+}
+
+func TestTypeAPILabels(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for _, path := range []string{
+		"/streams/foo?label=team:ingest&label=env:prod",
+		"/streams/bar?label=team:ingest&label=env:dev",
+		"/streams/baz?label=team:egress",
+		"/streams/buz",
+	} {
+		request := genRequest("POST", path, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	listIDs := func(query string) []string {
+		t.Helper()
+
+		request := genRequest("GET", "/streams"+query, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var ids []string
+		for id := range parseListBody(response.Body) {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"foo", "bar", "baz", "buz"}, listIDs(""))
+	assert.ElementsMatch(t, []string{"foo", "bar"}, listIDs("?label=team:ingest"))
+	assert.ElementsMatch(t, []string{"bar"}, listIDs("?label=team:ingest&label=env:dev"))
+	assert.ElementsMatch(t, []string{"baz"}, listIDs("?label=team:egress"))
+	assert.Empty(t, listIDs("?label=team:nope"))
+
+	request := genRequest("GET", "/streams?label=nope", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var info struct {
+		Labels map[string]string `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+	assert.Equal(t, map[string]string{"team": "ingest", "env": "prod"}, info.Labels)
+
+	// Labels are retained across updates unless replaced.
+	request = genRequest("PUT", "/streams/foo", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.ElementsMatch(t, []string{"foo"}, listIDs("?label=env:prod"))
+
+	request = genRequest("PUT", "/streams/foo?label=env:staging", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Empty(t, listIDs("?label=env:prod"))
+	assert.ElementsMatch(t, []string{"foo"}, listIDs("?label=env:staging"))
+}
+
+func TestTypeAPIDescription(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo?description=Copies+orders+to+the+archive", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	readDescription := func() string {
+		t.Helper()
+
+		request := genRequest("GET", "/streams/foo", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var info struct {
+			Description string `json:"description"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return info.Description
+	}
+	assert.Equal(t, "Copies orders to the archive", readDescription())
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var list map[string]struct {
+		Description string `json:"description"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+	assert.Equal(t, "Copies orders to the archive", list["foo"].Description)
+
+	// Descriptions are retained across updates unless replaced.
+	request = genRequest("PUT", "/streams/foo", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "Copies orders to the archive", readDescription())
+
+	request = genRequest("PUT", "/streams/foo?description=", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "", readDescription())
+}
+
+func TestTypeAPIDuplicateConfigs(t *testing.T) {
+	streamSet := `
+foo:
+  input:
+    generate:
+      mapping: 'root = "hello"'
+      interval: 1s
+  output:
+    drop: {}
+bar:
+  output:
+    drop: {}
+  input:
+    type: generate
+    generate:
+      interval: 1s
+      mapping: 'root = "hello"'
+baz:
+  input:
+    generate:
+      mapping: 'root = "world"'
+      interval: 1s
+  output:
+    drop: {}
+`
+
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict %v", strict), func(t *testing.T) {
+			logConf := log.NewConfig()
+			logConf.AddTimeStamp = false
+			logConf.Format = "logfmt"
+			logConf.LogLevel = "WARN"
+
+			var logBuf syncBuffer
+			logger, err := log.New(&logBuf, ifs.OS(), logConf)
+			require.NoError(t, err)
+
+			res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetLogger(logger))
+			require.NoError(t, err)
+
+			mgr := manager.New(res, manager.OptSetStrictDuplicateDetection(strict))
+			t.Cleanup(func() {
+				ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+				defer done()
+				assert.NoError(t, mgr.Stop(ctx))
+			})
+
+			r := router(mgr)
+
+			request := genYAMLRequest("POST", "/streams", streamSet)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+
+			assert.Contains(t, logBuf.String(), "Streams [bar foo] have identical configs")
+			assert.NotContains(t, logBuf.String(), "baz")
+
+			request = genRequest("GET", "/streams", nil)
+			listResponse := httptest.NewRecorder()
+			r.ServeHTTP(listResponse, request)
+			require.Equal(t, http.StatusOK, listResponse.Code)
+
+			if strict {
+				assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+				assert.Empty(t, parseListBody(listResponse.Body))
+			} else {
+				assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+				assert.Len(t, parseListBody(listResponse.Body), 3)
+			}
+		})
+	}
+}
+
+func TestTypeAPIAsyncJobs(t *testing.T) {
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		time.Sleep(time.Millisecond * 300)
+		return &mock.Input{TChan: make(chan message.Transaction)}, nil
+	}, docs.ComponentSpec{
+		Name: "slow_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetJobRetention(time.Second))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	slowConf := map[string]any{
+		"input": map[string]any{
+			"slow_input": map[string]any{},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}
+
+	type jobBody struct {
+		ID        string `json:"id"`
+		StreamID  string `json:"stream_id"`
+		Operation string `json:"operation"`
+		Status    string `json:"status"`
+		Error     string `json:"error"`
+	}
+
+	pollJob := func(location string) jobBody {
+		t.Helper()
+		var job jobBody
+		require.Eventually(t, func() bool {
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, genRequest("GET", location, nil))
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &job))
+			return job.Status != "pending"
+		}, time.Second*5, time.Millisecond*10)
+		return job
+	}
+
+	request := genRequest("POST", "/streams/foo?async=true", slowConf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusAccepted, response.Code, response.Body.String())
+
+	var accepted jobBody
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &accepted))
+	assert.Equal(t, "foo", accepted.StreamID)
+	assert.Equal(t, "create", accepted.Operation)
+	assert.Equal(t, "pending", accepted.Status)
+
+	location := response.Header().Get("Location")
+	assert.Equal(t, "/streams/jobs/"+accepted.ID, location)
+
+	job := pollJob(location)
+	assert.Equal(t, "succeeded", job.Status)
+	assert.Empty(t, job.Error)
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+
+	// A failed operation is reported by the job rather than the request.
+	request = genRequest("POST", "/streams/foo?async=true", slowConf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusAccepted, response.Code, response.Body.String())
+
+	job = pollJob(response.Header().Get("Location"))
+	assert.Equal(t, "failed", job.Status)
+	assert.Contains(t, job.Error, "stream already exists")
+
+	request = genRequest("PUT", "/streams/foo?async=true", slowConf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusAccepted, response.Code, response.Body.String())
+
+	location = response.Header().Get("Location")
+	job = pollJob(location)
+	assert.Equal(t, "update", job.Operation)
+	assert.Equal(t, "succeeded", job.Status)
+
+	// Completed jobs expire after the retention period.
+	assert.Eventually(t, func() bool {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", location, nil))
+		return response.Code == http.StatusNotFound
+	}, time.Second*5, time.Millisecond*50)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/jobs/nope", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIConfigSections(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/config/output", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"drop":{}}`, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/config/output?format=yaml", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+	assert.YAMLEq(t, "drop: {}", response.Body.String())
+
+	request = genRequest("PUT", "/streams/foo/config/output", map[string]any{
+		"reject": "nope",
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/config/output", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"reject":"nope"}`, response.Body.String())
+
+	// Other sections are left untouched.
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "generate", info.Config().Input.Type)
+	assert.Equal(t, "reject", info.Config().Output.Type)
+
+	request = genRequest("GET", "/streams/foo/config/input", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"generate":{"mapping":"root = deleted()"}}`, response.Body.String())
+
+	// An invalid section is rejected without modifying the stream.
+	request = genRequest("PUT", "/streams/foo/config/output", map[string]any{
+		"not_a_real_output": map[string]any{},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "reject", info.Config().Output.Type)
+
+	request = genRequest("GET", "/streams/foo/config/nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/bar/config/output", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIRateLimit(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/ratelimit", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"messages_per_second":0}`, response.Body.String())
+
+	request = genRequest("PUT", "/streams/foo/ratelimit", map[string]any{
+		"messages_per_second": 10.5,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 10.5, info.RateLimit())
+
+	request = genRequest("GET", "/streams/foo/ratelimit", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"messages_per_second":10.5}`, response.Body.String())
+
+	request = genRequest("PUT", "/streams/foo/ratelimit", map[string]any{
+		"messages_per_second": -1,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/streams/foo/ratelimit", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, float64(0), info.RateLimit())
+
+	request = genRequest("GET", "/streams/bar/ratelimit", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamLogs(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetStreamLogBufferSize(5))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	server := httptest.NewServer(router(mgr))
+	t.Cleanup(server.Close)
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    count: 10
+    interval: ""
+    mapping: 'root = counter()'
+pipeline:
+  processors:
+    - log:
+        message: 'processed ${! content() }'
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type logLine struct {
+		Level   string            `json:"level"`
+		Message string            `json:"message"`
+		Fields  map[string]string `json:"fields"`
+	}
+
+	var lines []logLine
+	require.Eventually(t, func() bool {
+		request := genRequest("GET", "/streams/foo/logs", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		if response.Code != http.StatusOK {
+			return false
+		}
+		lines = nil
+		if err := json.Unmarshal(response.Body.Bytes(), &lines); err != nil {
+			return false
+		}
+		return len(lines) > 0 && lines[len(lines)-1].Message == "processed 10"
+	}, time.Second*5, time.Millisecond*50)
+
+	// Only the most recent lines are retained.
+	require.Len(t, lines, 5)
+	assert.Equal(t, "processed 6", lines[0].Message)
+	assert.Equal(t, "info", lines[0].Level)
+	assert.Equal(t, "root.pipeline.processors.0", lines[0].Fields["path"])
+
+	request = genRequest("GET", "/streams/bar/logs", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	feedRes, err := http.Get(server.URL + "/streams/foo/logs?follow=true")
+	require.NoError(t, err)
+	t.Cleanup(func() { feedRes.Body.Close() })
+
+	require.Equal(t, http.StatusOK, feedRes.StatusCode)
+	assert.Equal(t, "text/event-stream", feedRes.Header.Get("Content-Type"))
+
+	events := readSSEEvents(t, feedRes.Body)
+
+	// Retained lines are sent first.
+	var line logLine
+	for i := 6; i <= 10; i++ {
+		e := nextSSEEvent(t, events)
+		assert.Equal(t, "log", e.Event)
+		require.NoError(t, json.Unmarshal([]byte(e.Data), &line))
+		assert.Equal(t, fmt.Sprintf("processed %v", i), line.Message)
+	}
+
+	// Lines emitted by a new version of the stream follow.
+	request = genYAMLRequest("PUT", "/streams/foo", `
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = "updated"'
+pipeline:
+  processors:
+    - log:
+        message: 'processed ${! content() }'
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	for {
+		e := nextSSEEvent(t, events)
+		require.NoError(t, json.Unmarshal([]byte(e.Data), &line))
+		if line.Message == "processed updated" {
+			break
+		}
+	}
+}
+
+func TestTypeAPIStreamLogLevel(t *testing.T) {
+	logConf := log.NewConfig()
+	logConf.AddTimeStamp = false
+	logConf.Format = "logfmt"
+	logConf.LogLevel = "INFO"
+
+	var logBuf syncBuffer
+	logger, err := log.New(&logBuf, ifs.OS(), logConf)
+	require.NoError(t, err)
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetLogger(logger))
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for _, id := range []string{"foo", "bar"} {
+		request := genYAMLRequest("POST", "/streams/"+id, fmt.Sprintf(`
+input:
+  generate:
+    interval: 10ms
+    mapping: 'root = counter()'
+pipeline:
+  processors:
+    - log:
+        level: DEBUG
+        message: '%v debug ${! content() }'
+output:
+  drop: {}
+`, id))
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request := genRequest("POST", "/streams/foo/loglevel", map[string]any{
+		"level": "DEBUG",
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(logBuf.String(), `msg="foo debug`)
+	}, time.Second*5, time.Millisecond*50)
+	assert.NotContains(t, logBuf.String(), `msg="bar debug`)
+
+	request = genRequest("GET", "/streams/foo/loglevel", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"level":"DEBUG"}`, response.Body.String())
+
+	request = genRequest("GET", "/streams/bar/loglevel", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"level":""}`, response.Body.String())
+
+	// The override reverts once the TTL elapses.
+	request = genRequest("POST", "/streams/foo/loglevel", map[string]any{
+		"level": "TRACE",
+		"ttl":   "200ms",
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/loglevel", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	var body struct {
+		Level     string `json:"level"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "TRACE", body.Level)
+	assert.NotEmpty(t, body.ExpiresAt)
+
+	require.Eventually(t, func() bool {
+		info, err := mgr.Read("foo")
+		if err != nil {
+			return false
+		}
+		level, _ := info.LogLevel()
+		return level == ""
+	}, time.Second*5, time.Millisecond*50)
+
+	// Lines logged after the override reverts are filtered again.
+	time.Sleep(time.Millisecond * 100)
+	before := strings.Count(logBuf.String(), `msg="foo debug`)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, before, strings.Count(logBuf.String(), `msg="foo debug`))
+
+	request = genRequest("POST", "/streams/foo/loglevel", map[string]any{
+		"level": "LOUD",
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/foo/loglevel", map[string]any{
+		"level": "DEBUG",
+		"ttl":   "nope",
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/baz/loglevel", map[string]any{
+		"level": "DEBUG",
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/streams/foo/loglevel", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+}
+
+func TestTypeAPINormalize(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	normalize := func(t *testing.T, conf string) string {
+		t.Helper()
+
+		request := genYAMLRequest("POST", "/streams/normalize", conf)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+		return response.Body.String()
+	}
+
+	normalized := normalize(t, `
+output:
+  type: drop
+  drop: {}
+pipeline:
+  threads: -1
+  processors:
+    - mapping: 'root = content().uppercase()'
+input:
+  generate:
+    mapping: 'root = "hello"'
+    interval: 1s
+    count: 5
+  processors: []
+buffer:
+  none: {}
+`)
+	assert.Equal(t, `input:
+  generate:
+    mapping: root = "hello"
+    count: 5
+pipeline:
+  processors:
+    - mapping: root = content().uppercase()
+output:
+  drop: {}
+`, normalized)
+
+	// Normalizing an already normalized config has no effect.
+	assert.Equal(t, normalized, normalize(t, normalized))
+
+	// Map keys are sorted and env var interpolations are left intact.
+	normalized = normalize(t, `
+input:
+  generate:
+    mapping: 'root = "${GREETING:hello}"'
+pipeline:
+  processors:
+    - mutation: 'root.c = 3'
+output:
+  broker:
+    outputs:
+      - drop: {}
+`)
+	assert.Contains(t, normalized, `${GREETING:hello}`)
+	assert.Equal(t, normalized, normalize(t, normalized))
+
+	// The normalized config is valid and results in the same stream.
+	request := genYAMLRequest("POST", "/streams/foo", normalized)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/normalize", `
+input:
+  generate:
+    mapping: 'root = "hello"'
+    nope: true
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "nope")
+}
+
+type unreadyMockInput struct {
+	*mock.Input
+	connected *atomic.Bool
+}
+
+func (u *unreadyMockInput) ConnectionStatus() component.ConnectionStatuses {
+	if u.connected.Load() {
+		return u.Input.ConnectionStatus()
+	}
+	return component.ConnectionStatuses{
+		component.ConnectionFailing(component.NoopObservability(), errors.New("nope")),
+	}
+}
+
+func TestTypeAPIAutoRestartCircuitBreaker(t *testing.T) {
+	var constructed atomic.Int64
+	var connected atomic.Bool
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		constructed.Add(1)
+		return &unreadyMockInput{
+			Input:     &mock.Input{TChan: make(chan message.Transaction)},
+			connected: &connected,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "unready_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetHealthCheckInterval(time.Millisecond*10),
+		manager.OptSetAutoRestart(time.Millisecond*50),
+		manager.OptSetRestartCircuitBreaker(2, time.Minute),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"unready_input": map[string]any{},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type streamInfo struct {
+		Active          bool   `json:"active"`
+		State           string `json:"state"`
+		RestartFailures int    `json:"restart_failures"`
+	}
+	getInfo := func() (info streamInfo) {
+		request := genRequest("GET", "/streams/foo", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return
+	}
+
+	// The stream is restarted twice before the breaker opens.
+	require.Eventually(t, func() bool {
+		info := getInfo()
+		return info.State == manager.StreamStateFailed && !info.Active
+	}, time.Second*5, time.Millisecond*10)
+
+	info := getInfo()
+	assert.Equal(t, 2, info.RestartFailures)
+	assert.Equal(t, int64(3), constructed.Load())
+
+	// No further restarts are attempted whilst the breaker is open.
+	<-time.After(time.Millisecond * 200)
+	assert.Equal(t, int64(3), constructed.Load())
+	assert.Equal(t, manager.StreamStateFailed, getInfo().State)
+
+	connected.Store(true)
+
+	request = genRequest("POST", "/streams/foo/reset", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info = getInfo()
+	assert.True(t, info.Active)
+	assert.Equal(t, manager.StreamStateRunning, info.State)
+	assert.Equal(t, 0, info.RestartFailures)
+	assert.Equal(t, int64(4), constructed.Load())
+
+	request = genRequest("POST", "/streams/bar/reset", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestTypeAPIMiddleware(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	var pathsMut sync.Mutex
+	var paths []string
+
+	mgr := manager.New(res, manager.OptSetAPIMiddleware(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Outer", "true")
+				pathsMut.Lock()
+				paths = append(paths, r.URL.Path)
+				pathsMut.Unlock()
+				next.ServeHTTP(w, r)
+			})
+		},
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "letmein" {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		},
+	))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	mgr.Router().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, "true", response.Header().Get("X-Outer"))
+
+	request = genRequest("POST", "/streams/foo", harmlessConf())
+	request.Header.Set("Authorization", "letmein")
+	response = httptest.NewRecorder()
+	mgr.Router().ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "true", response.Header().Get("X-Outer"))
+
+	request = genRequest("GET", "/streams/foo/stats", nil)
+	request.Header.Set("Authorization", "letmein")
+	response = httptest.NewRecorder()
+	mgr.Router().ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "true", response.Header().Get("X-Outer"))
+
+	pathsMut.Lock()
+	assert.Equal(t, []string{"/streams/foo", "/streams/foo", "/streams/foo/stats"}, paths)
+	pathsMut.Unlock()
+}
+
+func TestTypeAPIGetStreamFormat(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/fromyaml", `
+input:
+  generate:
+    mapping: 'root = "hello"'
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/fromjson", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	getStream := func(id, accept string) *httptest.ResponseRecorder {
+		request := genRequest("GET", "/streams/"+id, nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return response
+	}
+
+	assertYAML := func(t *testing.T, response *httptest.ResponseRecorder) {
+		t.Helper()
+		var body struct {
+			State  string         `yaml:"state"`
+			Config map[string]any `yaml:"config"`
+		}
+		require.NoError(t, yaml.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(t, "running", body.State)
+		assert.Contains(t, body.Config, "input")
+		assert.NotEqual(t, byte('{'), response.Body.Bytes()[0])
+	}
+
+	assertJSON := func(t *testing.T, response *httptest.ResponseRecorder) {
+		t.Helper()
+		var body struct {
+			State  string         `json:"state"`
+			Config map[string]any `json:"config"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(t, "running", body.State)
+		assert.Contains(t, body.Config, "input")
+	}
+
+	// Without an Accept header the original submission format is used.
+	response = getStream("fromyaml", "")
+	assert.Equal(t, "application/x-yaml", response.Header().Get("Content-Type"))
+	assertYAML(t, response)
+
+	response = getStream("fromjson", "")
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assertJSON(t, response)
+
+	response = getStream("fromyaml", "*/*")
+	assert.Equal(t, "application/x-yaml", response.Header().Get("Content-Type"))
+	assertYAML(t, response)
+
+	// The Accept header takes precedence over the submission format.
+	response = getStream("fromyaml", "application/json")
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assertJSON(t, response)
+
+	response = getStream("fromjson", "application/x-yaml")
+	assert.Equal(t, "application/x-yaml", response.Header().Get("Content-Type"))
+	assertYAML(t, response)
+
+	response = getStream("fromjson", "text/html, application/yaml;q=0.9")
+	assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+	assertYAML(t, response)
+
+	// Updating a stream with a config in another format changes its default.
+	request = genRequest("PUT", "/streams/fromyaml", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	response = getStream("fromyaml", "")
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assertJSON(t, response)
+}
+
+func TestTypeAPIListStreamsConcurrentMutations(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	const mutators, iterations = 4, 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < mutators; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				id := fmt.Sprintf("stream_%v_%v", i, j)
+
+				response := httptest.NewRecorder()
+				r.ServeHTTP(response, genRequest("POST", "/streams/"+id, harmlessConf()))
+				if !assert.Equal(t, http.StatusOK, response.Code, response.Body.String()) {
+					return
+				}
+
+				// Delete every other stream so that some remain.
+				if j%2 == 0 {
+					response = httptest.NewRecorder()
+					r.ServeHTTP(response, genRequest("DELETE", "/streams/"+id, nil))
+					if !assert.Equal(t, http.StatusOK, response.Code, response.Body.String()) {
+						return
+					}
+				}
+			}
+		}(i)
+	}
+
+	listStreams := func() map[string]map[string]any {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var infos map[string]map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &infos))
+		return infos
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	validStates := []any{manager.StreamStatePending, manager.StreamStateRunning, manager.StreamStateClosed}
+	deadline := time.After(time.Second * 30)
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		case <-deadline:
+			t.Fatal("timed out waiting for mutations, possible deadlock")
+		default:
+		}
+		for id, info := range listStreams() {
+			assert.Contains(t, validStates, info["state"], id)
+			assert.Equal(t, "generate", info["input_type"], id)
+		}
+	}
+
+	infos := listStreams()
+	assert.Len(t, infos, mutators*iterations/2)
+	for i := 0; i < mutators; i++ {
+		for j := 1; j < iterations; j += 2 {
+			assert.Contains(t, infos, fmt.Sprintf("stream_%v_%v", i, j))
+		}
+	}
+}
+
+func TestTypeAPIApplyStream(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	var eventsMut sync.Mutex
+	var events []string
+	mgr := manager.New(res, manager.OptAddLifecycleHook(func(e manager.LifecycleEvent) {
+		if e.Type == manager.LifecycleEventHealth {
+			return
+		}
+		eventsMut.Lock()
+		events = append(events, e.Type)
+		eventsMut.Unlock()
+	}))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	apply := func(conf string) bool {
+		request := genYAMLRequest("POST", "/streams/foo?if_exists=update", conf)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var body struct {
+			Changed bool `json:"changed"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		return body.Changed
+	}
+
+	assert.True(t, apply(`
+input:
+  generate:
+    mapping: 'root = "hello"'
+output:
+  drop: {}
+`))
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+
+	// Cosmetic differences such as the ordering of fields, explicit type
+	// fields and explicit default values are ignored.
+	assert.False(t, apply(`
+output:
+  type: drop
+  drop: {}
+input:
+  generate:
+    interval: 1s
+    mapping: 'root = "hello"'
+`))
+
+	sameInfo, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Same(t, info, sameInfo)
+
+	assert.True(t, apply(`
+input:
+  generate:
+    mapping: 'root = "world"'
+output:
+  drop: {}
+`))
+
+	newInfo, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.NotSame(t, info, newInfo)
+
+	// Without the parameter creating an existing stream is still an error.
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	eventsMut.Lock()
+	assert.Equal(t, []string{manager.LifecycleEventCreated, manager.LifecycleEventUpdated}, events)
+	eventsMut.Unlock()
+}
+
+func TestTypeAPIDeleteAllStreams(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	createStream := func(id string) {
+		request := genRequest("POST", "/streams/"+id, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+	for _, id := range []string{"foo", "bar", "baz"} {
+		createStream(id)
+	}
+
+	// Without confirmation nothing is removed.
+	request := genRequest("DELETE", "/streams", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "all=true")
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+
+	request = genRequest("DELETE", "/streams?all=true", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"deleted":["bar","baz","foo"]}`, response.Body.String())
+
+	for _, id := range []string{"foo", "bar", "baz"} {
+		_, err = mgr.Read(id)
+		assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+	}
+
+	request = genRequest("DELETE", "/streams?all=true", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"deleted":[]}`, response.Body.String())
+
+	// The method is usable directly.
+	createStream("qux")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	deleted, err := mgr.DeleteAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qux"}, deleted)
+}
+
+func TestTypeAPIScaleStream(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    mapping: root = deleted()
+pipeline:
+  threads: 1
+  processors:
+    - mapping: root = content().uppercase()
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	getParallelism := func() int {
+		t.Helper()
+
+		request := genRequest("GET", "/streams/foo", nil)
+		request.Header.Set("Accept", "application/json")
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var info struct {
+			Parallelism int `json:"parallelism"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return info.Parallelism
+	}
+
+	assert.Equal(t, 1, getParallelism())
+
+	request = genRequest("PUT", "/streams/foo/scale", map[string]any{
+		"threads": 4,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Equal(t, 4, getParallelism())
+
+	request = genRequest("GET", "/streams/foo/scale", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"threads":4}`, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Len(t, info.Config().Pipeline.Processors, 1)
+
+	request = genRequest("PUT", "/streams/foo/scale", map[string]any{
+		"threads": 0,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genRequest("PUT", "/streams/bar/scale", map[string]any{
+		"threads": 2,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamThroughput(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetHealthCheckInterval(time.Millisecond*50),
+		manager.OptSetThroughputWindow(time.Second*10),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    count: 20
+    interval: 50ms
+    mapping: 'root = counter()'
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type rateBody struct {
+		Input  float64 `json:"input"`
+		Output float64 `json:"output"`
+	}
+
+	getRate := func() rateBody {
+		t.Helper()
+
+		request := genRequest("GET", "/streams/foo", nil)
+		request.Header.Set("Accept", "application/json")
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var info struct {
+			Rate rateBody `json:"rate"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return info.Rate
+	}
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return !info.IsRunning()
+	}, time.Second*10, time.Millisecond*50)
+
+	// Twenty messages generated at an interval of 50ms are consumed at roughly
+	// twenty messages per second, with leeway for slow test environments.
+	rate := getRate()
+	assert.Greater(t, rate.Input, 5.0)
+	assert.LessOrEqual(t, rate.Input, 25.0)
+	assert.InDelta(t, rate.Input, rate.Output, 5.0)
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var list map[string]struct {
+		Rate rateBody `json:"rate"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+	assert.Greater(t, list["foo"].Rate.Input, 0.0)
+}
+
+// withoutUptime removes the uptime fields of stream status bodies, which differ
+// between requests.
+func withoutUptime(v map[string]any) map[string]any {
+	delete(v, "uptime")
+	delete(v, "uptime_str")
+	for _, child := range v {
+		if childObj, ok := child.(map[string]any); ok {
+			withoutUptime(childObj)
+		}
+	}
+	return v
+}
+
+func TestTypeAPIResponseHeaders(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetResponseHeaders(func(id string) http.Header {
+		if id != "foo" {
+			return nil
+		}
+		return http.Header{
+			"Cache-Control": []string{"max-age=30"},
+			"content-type":  []string{"text/plain"},
+		}
+	}))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := mgr.Router()
+
+	for _, id := range []string{"foo", "bar"} {
+		request := genRequest("POST", "/streams/"+id, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	for _, path := range []string{"/streams/foo", "/streams/foo/config/output"} {
+		request := genRequest("GET", path, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		assert.Equal(t, "max-age=30", response.Header().Get("Cache-Control"), path)
+
+		// Headers set by the endpoint itself take precedence.
+		assert.Equal(t, "application/json", response.Header().Get("Content-Type"), path)
+	}
+
+	for _, path := range []string{"/streams/bar", "/streams"} {
+		request := genRequest("GET", path, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		assert.Empty(t, response.Header().Get("Cache-Control"), path)
+	}
+}
+
+func TestTypeAPIPrettyResponses(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := mgr.Router()
+
+	request := genRequest("POST", "/streams/foo?pretty=true", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	for _, path := range []string{"/streams", "/streams/foo", "/streams/foo/ratelimit"} {
+		request = genRequest("GET", path, nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		compact := response.Body.String()
+		assert.NotContains(t, compact, "\n  ", path)
+
+		request = genRequest("GET", path+"?pretty=true", nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		assert.Equal(t, "application/json", response.Header().Get("Content-Type"), path)
+		pretty := response.Body.String()
+		assert.Contains(t, pretty, "\n  ", path)
+
+		var compactObj, prettyObj map[string]any
+		require.NoError(t, json.Unmarshal([]byte(compact), &compactObj), path)
+		require.NoError(t, json.Unmarshal([]byte(pretty), &prettyObj), path)
+		assert.Equal(t, withoutUptime(compactObj), withoutUptime(prettyObj), path)
+	}
+
+	// Errors are passed through untouched.
+	request = genRequest("GET", "/streams/bar?pretty=true", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "Stream not found\n", response.Body.String())
+}
+
+// redactPasswords returns a copy of a structure where the values of all fields
+// named password are redacted.
+func redactPasswords(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(t))
+		for k, child := range t {
+			if k == "password" {
+				redacted[k] = "!!!REDACTED!!!"
+				continue
+			}
+			redacted[k] = redactPasswords(child)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(t))
+		for i, child := range t {
+			redacted[i] = redactPasswords(child)
+		}
+		return redacted
+	}
+	return v
+}
+
+func TestTypeAPIConfigSanitizer(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetConfigSanitizer(func(conf stream.Config) (any, error) {
+		return redactPasswords(conf.GetRawSource()), nil
+	}))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo?start=false", `
+input:
+  generate:
+    mapping: root = deleted()
+output:
+  http_client:
+    url: http://localhost:4195/post
+    basic_auth:
+      enabled: true
+      username: foo
+      password: hunter2
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	for _, path := range []string{"/streams/foo", "/streams/foo/config/output", "/streams/export"} {
+		request = genRequest("GET", path, nil)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		assert.NotContains(t, response.Body.String(), "hunter2", path)
+		assert.Contains(t, response.Body.String(), "!!!REDACTED!!!", path)
+		assert.Contains(t, response.Body.String(), "foo", path)
+	}
+
+	// The running config of the stream is unaffected.
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	conf := info.Config()
+	assert.Equal(t, "hunter2", gabs.Wrap(conf.GetRawSource()).S("output", "http_client", "basic_auth", "password").Data())
+}
+
+func TestTypeAPIStreamRawConfig(t *testing.T) {
+	streamConf := `
+input:
+  generate:
+    mapping: root = deleted()
+output:
+  http_client:
+    url: http://localhost:4195/post
+    basic_auth:
+      enabled: true
+      username: foo
+      password: hunter2
+`
+	sanitizer := manager.OptSetConfigSanitizer(func(conf stream.Config) (any, error) {
+		return redactPasswords(conf.GetRawSource()), nil
+	})
+
+	for _, test := range []struct {
+		name     string
+		opts     []func(*manager.Type)
+		allowRaw bool
+	}{
+		{name: "disabled by default", opts: []func(*manager.Type){sanitizer}},
+		{name: "disabled explicitly", opts: []func(*manager.Type){sanitizer, manager.OptSetAllowRawConfig(false)}},
+		{name: "enabled", opts: []func(*manager.Type){sanitizer, manager.OptSetAllowRawConfig(true)}, allowRaw: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := bmanager.New(bmanager.NewResourceConfig())
+			require.NoError(t, err)
+
+			mgr := manager.New(res, test.opts...)
+			t.Cleanup(func() {
+				ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+				defer done()
+				_ = mgr.Stop(ctx)
+			})
+
+			r := router(mgr)
+
+			request := genYAMLRequest("POST", "/streams/foo?start=false", streamConf)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+			request = genRequest("GET", "/streams/foo/config/raw", nil)
+			response = httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			if !test.allowRaw {
+				assert.Equal(t, http.StatusForbidden, response.Code, response.Body.String())
+				assert.NotContains(t, response.Body.String(), "hunter2")
+				return
+			}
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+			assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+			raw, err := gabs.ParseJSON(response.Body.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, "hunter2", raw.S("output", "http_client", "basic_auth", "password").Data())
+
+			request = genRequest("GET", "/streams/foo/config/raw?format=yaml", nil)
+			response = httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+			assert.Contains(t, response.Body.String(), "password: hunter2")
+
+			// The default read remains sanitized.
+			request = genRequest("GET", "/streams/foo", nil)
+			response = httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+			assert.NotContains(t, response.Body.String(), "hunter2")
+
+			request = genRequest("GET", "/streams/bar/config/raw", nil)
+			response = httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+		})
+	}
+}
+
+func TestTypeAPIIdempotentCreate(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	var created atomic.Int64
+	mgr := manager.New(res,
+		manager.OptSetIdempotencyWindow(time.Millisecond*500),
+		manager.OptAddLifecycleHook(func(e manager.LifecycleEvent) {
+			if e.Type == manager.LifecycleEventCreated {
+				created.Add(1)
+			}
+		}),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	createFoo := func(key string, conf any) *httptest.ResponseRecorder {
+		request := genRequest("POST", "/streams/foo", conf)
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	response := createFoo("first", harmlessConf())
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// A retry with the same key and body receives the original response.
+	response = createFoo("first", harmlessConf())
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, int64(1), created.Load())
+
+	// A retry without a key, or with a different key, is applied again.
+	response = createFoo("", harmlessConf())
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+
+	response = createFoo("second", harmlessConf())
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+
+	// Reusing a key for a different request is rejected.
+	otherConf := harmlessConf().(map[string]any)
+	otherConf["output"] = map[string]any{"reject": "nope"}
+	response = createFoo("first", otherConf)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code, response.Body.String())
+
+	// Keys expire after the idempotency window.
+	assert.Eventually(t, func() bool {
+		return createFoo("first", harmlessConf()).Code == http.StatusConflict
+	}, time.Second*5, time.Millisecond*50)
+	assert.Equal(t, int64(1), created.Load())
+}
+
+func TestTypeAPIIdempotentCreateConcurrent(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		close(entered)
+		<-release
+		return &mock.Input{TChan: make(chan message.Transaction)}, nil
+	}, docs.ComponentSpec{
+		Name: "blocking_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	var created atomic.Int64
+	mgr := manager.New(res,
+		manager.OptSetIdempotencyWindow(time.Minute),
+		manager.OptAddLifecycleHook(func(e manager.LifecycleEvent) {
+			if e.Type == manager.LifecycleEventCreated {
+				created.Add(1)
+			}
+		}),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	createFoo := func() *httptest.ResponseRecorder {
+		request := genRequest("POST", "/streams/foo", map[string]any{
+			"input":  map[string]any{"blocking_input": map[string]any{}},
+			"output": map[string]any{"drop": map[string]any{}},
+		})
+		request.Header.Set("Idempotency-Key", "first")
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	firstDone := make(chan *httptest.ResponseRecorder)
+	go func() {
+		firstDone <- createFoo()
+	}()
+	<-entered
+
+	// A duplicate of a request that is still being handled is rejected rather
+	// than being applied a second time.
+	response := createFoo()
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+	assert.Equal(t, "1", response.Header().Get("Retry-After"))
+
+	close(release)
+	response = <-firstDone
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Empty(t, response.Header().Get("Idempotent-Replayed"))
+
+	// Once the original request completes its response is replayed.
+	response = createFoo()
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "true", response.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int64(1), created.Load())
+}
+
+func TestTypeAPIStreamReloads(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	stats := metrics.NewLocal()
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = mgr.Stop(ctx)
+	})
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/streams/reloads", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"reloads":0}`, response.Body.String())
+
+	streamConf := func(mapping string) stream.Config {
+		conf, err := testutil.StreamFromYAML(fmt.Sprintf(`
+input:
+  generate:
+    mapping: %q
+output:
+  drop: {}
+`, mapping))
+		require.NoError(t, err)
+		return conf
+	}
+	for _, id := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, mgr.Create(id, streamConf("root = deleted()")))
+	}
+
+	_, err = mgr.Reconcile(ctx, map[string]stream.Config{
+		"foo":  streamConf("root = deleted()"),
+		"bar":  streamConf(`root = "changed"`),
+		"qux":  streamConf("root = deleted()"),
+		"quux": streamConf("root = this.nope("),
+	})
+	require.Error(t, err)
+
+	request = genRequest("GET", "/streams/reloads", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var body struct {
+		Reloads int `json:"reloads"`
+		Last    struct {
+			StartedAt time.Time `json:"started_at"`
+			Duration  float64   `json:"duration"`
+			Created   int       `json:"created"`
+			Updated   int       `json:"updated"`
+			Deleted   int       `json:"deleted"`
+			Unchanged int       `json:"unchanged"`
+			Failed    int       `json:"failed"`
+			Errors    []string  `json:"errors"`
+		} `json:"last"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Reloads)
+	assert.Equal(t, 1, body.Last.Created)
+	assert.Equal(t, 1, body.Last.Updated)
+	assert.Equal(t, 1, body.Last.Deleted)
+	assert.Equal(t, 1, body.Last.Unchanged)
+	assert.Equal(t, 1, body.Last.Failed)
+	require.Len(t, body.Last.Errors, 1)
+	assert.Contains(t, body.Last.Errors[0], "quux")
+	assert.WithinDuration(t, time.Now(), body.Last.StartedAt, time.Second*30)
+	assert.Greater(t, body.Last.Duration, 0.0)
+
+	gauges := map[string]int64{}
+	for k, v := range stats.GetCounters() {
+		name, _, values := metrics.ReverseLabelledPath(k)
+		if name == "stream_reload_streams" && len(values) == 1 {
+			gauges[values[0]] = v
+		}
+		if name == "stream_reloads" {
+			assert.Equal(t, int64(1), v)
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"created":   1,
+		"updated":   1,
+		"deleted":   1,
+		"unchanged": 1,
+		"failed":    1,
+	}, gauges)
+}
+
+// stuckOutput accepts transactions without ever delivering them, and only
+// rejects them once it is closed.
+type stuckOutput struct {
+	pending   chan message.Transaction
+	closeOnce sync.Once
+	closeSig  chan struct{}
+	doneSig   chan struct{}
+}
+
+func (s *stuckOutput) Consume(ts <-chan message.Transaction) error {
+	go func() {
+		defer close(s.doneSig)
+		var held []message.Transaction
+		defer func() {
+			for _, t := range held {
+				_ = t.Ack(context.Background(), component.ErrTypeClosed)
+			}
+		}()
+		for {
+			select {
+			case t, open := <-ts:
+				if !open {
+					ts = nil
+					continue
+				}
+				held = append(held, t)
+				s.pending <- t
+			case <-s.closeSig:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *stuckOutput) ConnectionStatus() component.ConnectionStatuses {
+	return component.ConnectionStatuses{
+		component.ConnectionActive(component.NoopObservability()),
+	}
+}
+
+func (s *stuckOutput) TriggerCloseNow() {
+	s.closeOnce.Do(func() {
+		close(s.closeSig)
+	})
+}
+
+func (s *stuckOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-s.doneSig:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTypeAPIDeleteWithDeadLetter(t *testing.T) {
+	stuck := &stuckOutput{
+		pending:  make(chan message.Transaction, 10),
+		closeSig: make(chan struct{}),
+		doneSig:  make(chan struct{}),
+	}
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return stuck, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Second*2), manager.OptSetOutputDiversion(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"count":    3,
+				"interval": "",
+			},
+		},
+		"output": map[string]any{
+			"stuck_output": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Eventually(t, func() bool {
+		return len(stuck.pending) == 3
+	}, time.Second*5, time.Millisecond*10)
+
+	request = genRequest("DELETE", "/streams/foo?deadletter=true", "")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	dlPath := filepath.Join(t.TempDir(), "deadletter.txt")
+	request = genYAMLRequest("DELETE", "/streams/foo?deadletter=true", map[string]any{
+		"file": map[string]any{
+			"path":  dlPath,
+			"codec": "lines",
+		},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	dlBytes, err := os.ReadFile(dlPath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\nhello world\nhello world\n", string(dlBytes))
+}
+
+func TestTypeAPIStreamOutputSwap(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Second*5), manager.OptSetOutputDiversion(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	tmpDir := t.TempDir()
+	firstPath, secondPath := filepath.Join(tmpDir, "first.txt"), filepath.Join(tmpDir, "second.txt")
+
+	request := genYAMLRequest("POST", "/streams/foo", fmt.Sprintf(`
+input:
+  generate:
+    mapping: root = counter()
+    interval: 1ms
+output:
+  file:
+    path: %v
+    codec: lines
+`, firstPath))
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	readCounts := func(path string) []int {
+		t.Helper()
+
+		b, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		require.NoError(t, err)
+
+		var counts []int
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			if line == "" {
+				continue
+			}
+			n, err := strconv.Atoi(line)
+			require.NoError(t, err)
+			counts = append(counts, n)
+		}
+		return counts
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(readCounts(firstPath)) >= 10
+	}, time.Second*5, time.Millisecond*10)
+
+	before, err := mgr.Read("foo")
+	require.NoError(t, err)
+	uptimeBefore := before.Uptime()
+
+	request = genYAMLRequest("POST", "/streams/foo/output", map[string]any{
+		"file": map[string]any{
+			"path":  secondPath,
+			"codec": "lines",
+		},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Eventually(t, func() bool {
+		return len(readCounts(secondPath)) >= 10
+	}, time.Second*5, time.Millisecond*10)
+
+	// The input keeps running throughout, and therefore the counter continues
+	// where the first output left off rather than starting over.
+	first, second := readCounts(firstPath), readCounts(secondPath)
+	counts := append(first, second...)
+	for i, n := range counts {
+		require.Equal(t, i+1, n, "counts: %v", counts)
+	}
+
+	after, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Same(t, before, after)
+	assert.True(t, after.IsRunning())
+	assert.Greater(t, after.Uptime(), uptimeBefore)
+	assert.Equal(t, secondPath, gabs.Wrap(testConfToAny(t, after.Config())).S("output", "file", "path").Data())
+
+	request = genRequest("POST", "/streams/foo/output", "")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo/output", map[string]any{
+		"nope": map[string]any{},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/bar/output", map[string]any{
+		"drop": map[string]any{},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamOutputSwapUndrained(t *testing.T) {
+	stuck := &stuckOutput{
+		pending:  make(chan message.Transaction, 10),
+		closeSig: make(chan struct{}),
+		doneSig:  make(chan struct{}),
+	}
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return stuck, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Millisecond*500), manager.OptSetOutputDiversion(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"count":    3,
+				"interval": "",
+			},
+		},
+		"output": map[string]any{
+			"stuck_output": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Eventually(t, func() bool {
+		return len(stuck.pending) == 3
+	}, time.Second*5, time.Millisecond*10)
+
+	outPath := filepath.Join(t.TempDir(), "out.txt")
+	request = genYAMLRequest("POST", "/streams/foo/output", map[string]any{
+		"file": map[string]any{
+			"path":  outPath,
+			"codec": "lines",
+		},
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Eventually(t, func() bool {
+		outBytes, _ := os.ReadFile(outPath)
+		return string(outBytes) == "hello world\nhello world\nhello world\n"
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestTypeAPIMaintenanceMode(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetMaintenanceQueueSize(2))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	type maintenanceBody struct {
+		Enabled   bool `json:"enabled"`
+		MaxQueued int  `json:"max_queued"`
+		Queued    []struct {
+			Method string `json:"method"`
+			Path   string `json:"path"`
+		} `json:"queued"`
+		Applied []struct {
+			Method string `json:"method"`
+			Path   string `json:"path"`
+			Status int    `json:"status"`
+		} `json:"applied"`
+	}
+	parseBody := func(t testing.TB, response *httptest.ResponseRecorder) (body maintenanceBody) {
+		t.Helper()
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body), response.Body.String())
+		return
+	}
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/maintenance", map[string]any{"enabled": true})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	body := parseBody(t, response)
+	assert.True(t, body.Enabled)
+	assert.Equal(t, 2, body.MaxQueued)
+	assert.Empty(t, body.Queued)
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusAccepted, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusAccepted, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/baz", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code, response.Body.String())
+
+	// Reads are served as normal.
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/maintenance", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	body = parseBody(t, response)
+	assert.True(t, body.Enabled)
+	require.Len(t, body.Queued, 2)
+	assert.Equal(t, "POST", body.Queued[0].Method)
+	assert.Equal(t, "/streams/bar", body.Queued[0].Path)
+	assert.Equal(t, "DELETE", body.Queued[1].Method)
+	assert.Equal(t, "/streams/foo", body.Queued[1].Path)
+
+	request = genRequest("POST", "/streams/maintenance", map[string]any{"enabled": false})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	body = parseBody(t, response)
+	assert.False(t, body.Enabled)
+	assert.Empty(t, body.Queued)
+	require.Len(t, body.Applied, 2)
+	assert.Equal(t, http.StatusOK, body.Applied[0].Status)
+	assert.Equal(t, http.StatusOK, body.Applied[1].Status)
+
+	request = genRequest("GET", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	request = genRequest("POST", "/streams/baz", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamDiff(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo/diff", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo", `
+input:
+  generate:
+    mapping: root = deleted()
+buffer:
+  memory: {}
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// Differences in formatting and defaults are not reported.
+	request = genYAMLRequest("POST", "/streams/foo/diff", `
+output:
+  drop: {}
+buffer:
+  memory:
+    limit: 524288000
+input:
+  generate:
+    mapping: 'root = deleted()'
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"added":[],"removed":[],"changed":[]}`, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo/diff", `
+input:
+  generate:
+    mapping: root = deleted()
+buffer:
+  system_window:
+    size: 1s
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{
+  "added": [],
+  "removed": [],
+  "changed": [
+    {
+      "path": "buffer",
+      "old": {"memory":{}},
+      "new": {"system_window":{"size":"1s"}}
+    }
+  ]
+}`, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo/diff", `
+input:
+  generate:
+    mapping: root = "hello world"
+    count: 10
+buffer:
+  memory: {}
+pipeline:
+  processors:
+    - mapping: root = content().uppercase()
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{
+  "added": [
+    {"path":"input.generate.count","value":10},
+    {"path":"pipeline","value":{"processors":[{"mapping":"root = content().uppercase()"}]}}
+  ],
+  "removed": [],
+  "changed": [
+    {"path":"input.generate.mapping","old":"root = deleted()","new":"root = \"hello world\""}
+  ]
+}`, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo/diff", `
+input:
+  generate:
+    mapping: root = deleted()
+buffer:
+  memory: {}
+output:
+  nope: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	conf := info.Config()
+	assert.Equal(t, "root = deleted()", gabs.Wrap(conf.GetRawSource()).S("input", "generate", "mapping").Data())
+}
+
+func TestTypeAPIStreamTemplates(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/streams/templates", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var templates []struct {
+		Name    string `json:"name"`
+		Summary string `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &templates))
+
+	var names []string
+	for _, tmpl := range templates {
+		assert.NotEmpty(t, tmpl.Summary, tmpl.Name)
+		names = append(names, tmpl.Name)
+	}
+	assert.Equal(t, []string{"generate-to-stdout", "http-to-stdout", "kafka-to-s3"}, names)
+
+	// Every template must be a valid config without lint errors as it is.
+	for _, name := range names {
+		request = genRequest("POST", "/streams/"+name+"?start=false&template="+name, "")
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusOK, response.Code, "%v: %v", name, response.Body.String())
+	}
+
+	request = genRequest("GET", "/streams/templates/http-to-stdout", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/x-yaml", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "http_server:")
+
+	request = genRequest("GET", "/streams/templates/http-to-stdout", nil)
+	request.Header.Set("Accept", "application/json")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	tmplConf, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "/post", tmplConf.S("input", "http_server", "path").Data())
+
+	request = genRequest("GET", "/streams/templates/nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo?start=false&template=http-to-stdout", `
+input:
+  label: ingest
+  http_server:
+    path: /ingest
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	conf := info.Config()
+	assert.Equal(t, map[string]any{
+		"input": map[string]any{
+			"label": "ingest",
+			"http_server": map[string]any{
+				"path":          "/ingest",
+				"allowed_verbs": []any{"POST"},
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}, conf.GetRawSource())
+
+	request = genRequest("POST", "/streams/bar?template=nope", "")
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+}
+
+type traceCaptureOutput struct {
+	traceIDs chan trace.TraceID
+	doneSig  chan struct{}
+}
+
+func (o *traceCaptureOutput) Consume(ts <-chan message.Transaction) error {
+	go func() {
+		defer close(o.doneSig)
+		for tran := range ts {
+			for _, p := range tran.Payload {
+				o.traceIDs <- trace.SpanContextFromContext(message.GetContext(p)).TraceID()
+			}
+			_ = tran.Ack(context.Background(), nil)
+		}
+	}()
+	return nil
+}
+
+func (o *traceCaptureOutput) ConnectionStatus() component.ConnectionStatuses {
+	return component.ConnectionStatuses{
+		component.ConnectionActive(component.NoopObservability()),
+	}
+}
+
+func (o *traceCaptureOutput) TriggerCloseNow() {}
+
+func (o *traceCaptureOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-o.doneSig:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTypeAPITraceContextPropagation(t *testing.T) {
+	traceIDs := make(chan trace.TraceID, 10)
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return &traceCaptureOutput{traceIDs: traceIDs, doneSig: make(chan struct{})}, nil
+	}, docs.ComponentSpec{
+		Name: "trace_capture",
+	}))
+
+	spans := tracetest.NewSpanRecorder()
+	res, err := bmanager.New(bmanager.NewResourceConfig(),
+		bmanager.OptSetEnvironment(env),
+		bmanager.OptSetTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+	)
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := mgr.Router()
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping": `root = "hello world"`,
+				"count":   1,
+			},
+		},
+		"output": map[string]any{
+			"trace_capture": map[string]any{},
+		},
+	})
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	parentID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	var requestSpan sdktrace.ReadOnlySpan
+	for _, s := range spans.Ended() {
+		if s.Name() == "POST /streams/{id}" {
+			requestSpan = s
+		}
+	}
+	require.NotNil(t, requestSpan)
+	assert.Equal(t, traceID, requestSpan.SpanContext().TraceID())
+	assert.Equal(t, parentID, requestSpan.Parent().SpanID())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, requestSpan.SpanContext().SpanID(), info.TraceParent().SpanID())
+
+	select {
+	case id := <-traceIDs:
+		assert.Equal(t, traceID, id)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+	}
+
+	// The spans started by the input for each message are children of the
+	// span of the request that created the stream.
+	assert.Eventually(t, func() bool {
+		for _, s := range spans.Ended() {
+			if s.Parent().SpanID() == requestSpan.SpanContext().SpanID() {
+				return s.SpanContext().TraceID() == traceID
+			}
+		}
+		return false
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestTypeAPIBufferDepth(t *testing.T) {
+	stuck := &stuckOutput{
+		pending:  make(chan message.Transaction, 10),
+		closeSig: make(chan struct{}),
+		doneSig:  make(chan struct{}),
+	}
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return stuck, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		// The buffer is unable to drain gracefully as the output never
+		// delivers messages.
+		ctx, done := context.WithTimeout(context.Background(), time.Second*4)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	type depthBody struct {
+		BufferDepth *struct {
+			Messages int `json:"messages"`
+			Bytes    int `json:"bytes"`
+		} `json:"buffer_depth"`
+		Backpressure *bool `json:"backpressure"`
+	}
+	getDepth := func(t testing.TB, id string) (body depthBody) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/"+id, nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body), response.Body.String())
+		return
+	}
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello"`,
+				"count":    5,
+				"interval": "",
+			},
+		},
+		"buffer": map[string]any{
+			"memory": map[string]any{},
+		},
+		"output": map[string]any{
+			"stuck_output": map[string]any{},
+		},
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/bar", harmlessConf())
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// None of the messages are acknowledged by the output and therefore all of
+	// them remain within the buffer.
+	assert.Eventually(t, func() bool {
+		body := getDepth(t, "foo")
+		return body.BufferDepth != nil && body.BufferDepth.Messages == 5
+	}, time.Second*5, time.Millisecond*10)
+
+	body := getDepth(t, "foo")
+	assert.Equal(t, 25, body.BufferDepth.Bytes)
+	require.NotNil(t, body.Backpressure)
+	assert.False(t, *body.Backpressure)
+
+	body = getDepth(t, "bar")
+	assert.Nil(t, body.BufferDepth)
+	assert.Nil(t, body.Backpressure)
+}
+
+func TestTypeAPIStreamGroups(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for _, path := range []string{
+		"/streams/foo?label=group:ingest",
+		"/streams/bar?label=group:ingest",
+		"/streams/baz?label=group:other",
+	} {
+		request := genRequest("POST", path, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	groupAction := func(t testing.TB, group, action string) (int, map[string]map[string]string) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("POST", "/streams/groups/"+group+"/"+action, nil))
+		var outcomes map[string]map[string]string
+		if response.Code != http.StatusNotFound && response.Code != http.StatusBadRequest {
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &outcomes), response.Body.String())
+		}
+		return response.Code, outcomes
+	}
+
+	code, outcomes := groupAction(t, "ingest", "pause")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]map[string]string{
+		"foo": {"outcome": "paused"},
+		"bar": {"outcome": "paused"},
+	}, outcomes)
+
+	for id, paused := range map[string]bool{"foo": true, "bar": true, "baz": false} {
+		info, err := mgr.Read(id)
+		require.NoError(t, err)
+		assert.Equal(t, paused, info.IsPaused(), id)
+		assert.True(t, info.IsRunning(), id)
+	}
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), `"paused":true`)
+
+	code, outcomes = groupAction(t, "ingest", "resume")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "resumed", outcomes["foo"]["outcome"])
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.False(t, info.IsPaused())
+
+	code, _ = groupAction(t, "nope", "pause")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = groupAction(t, "ingest", "explode")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, outcomes = groupAction(t, "ingest", "delete")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]map[string]string{
+		"foo": {"outcome": "deleted"},
+		"bar": {"outcome": "deleted"},
+	}, outcomes)
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+	_, err = mgr.Read("baz")
+	assert.NoError(t, err)
+}
+
+func TestTypeAPIStreamsPauseAll(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	fleetAction := func(t testing.TB, action string) (int, map[string]map[string]string) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("POST", "/streams/"+action, nil))
+		var outcomes map[string]map[string]string
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &outcomes), response.Body.String())
+		return response.Code, outcomes
+	}
+
+	code, outcomes := fleetAction(t, "pause")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, outcomes)
+
+	ids := []string{"foo", "bar", "baz"}
+	for _, id := range ids {
+		request := genRequest("POST", "/streams/"+id, harmlessConf())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+	require.NoError(t, mgr.Pause("bar"))
+
+	// Pausing is idempotent, such that repeated requests have the same outcome.
+	for i := 0; i < 2; i++ {
+		code, outcomes = fleetAction(t, "pause")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]map[string]string{
+			"foo": {"outcome": "paused"},
+			"bar": {"outcome": "paused"},
+			"baz": {"outcome": "paused"},
+		}, outcomes)
+
+		for _, id := range ids {
+			info, err := mgr.Read(id)
+			require.NoError(t, err)
+			assert.True(t, info.IsPaused(), id)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		code, outcomes = fleetAction(t, "resume")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]map[string]string{
+			"foo": {"outcome": "resumed"},
+			"bar": {"outcome": "resumed"},
+			"baz": {"outcome": "resumed"},
+		}, outcomes)
+
+		for _, id := range ids {
+			info, err := mgr.Read(id)
+			require.NoError(t, err)
+			assert.False(t, info.IsPaused(), id)
+			assert.True(t, info.IsRunning(), id)
+		}
+	}
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/pause", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/pause?duration=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	// Streams cannot be named after the bulk endpoints, which would shadow
+	// their /streams/{id} endpoints.
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    mapping: 'root = "hello world"'
+    interval: 1s
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	for _, id := range []string{"pause", "resume"} {
+		err := mgr.Create(id, conf)
+		assert.ErrorIs(t, err, manager.ErrStreamConfigInvalid, id)
+
+		_, err = mgr.Read(id)
+		assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist, id)
+	}
+}
+
+func TestTypeAPIStreamPauseDuration(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type pauseStatus struct {
+		Paused         bool     `json:"paused"`
+		PauseRemaining *float64 `json:"pause_remaining"`
+	}
+	getStatus := func() (s pauseStatus) {
+		t.Helper()
+		request := genRequest("GET", "/streams/foo", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &s))
+		return
+	}
+	post := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := genRequest("POST", path, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	response = post("/streams/foo/pause?duration=300ms")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	status := getStatus()
+	assert.True(t, status.Paused)
+	require.NotNil(t, status.PauseRemaining)
+	assert.Greater(t, *status.PauseRemaining, 0.0)
+	assert.LessOrEqual(t, *status.PauseRemaining, 0.3)
+
+	// The stream is resumed automatically once the duration elapses.
+	assert.Eventually(t, func() bool {
+		return !getStatus().Paused
+	}, time.Second*5, time.Millisecond*20)
+	assert.Nil(t, getStatus().PauseRemaining)
+
+	// The latest pause wins, and therefore a pause without a duration cancels
+	// the scheduled resume.
+	response = post("/streams/foo/pause?duration=100ms")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	response = post("/streams/foo/pause")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	time.Sleep(time.Millisecond * 300)
+	status = getStatus()
+	assert.True(t, status.Paused)
+	assert.Nil(t, status.PauseRemaining)
+
+	// A manual resume cancels a scheduled resume.
+	response = post("/streams/foo/pause?duration=200ms")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	response = post("/streams/foo/resume")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	response = post("/streams/foo/pause")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	time.Sleep(time.Millisecond * 400)
+	assert.True(t, getStatus().Paused)
+
+	response = post("/streams/foo/resume")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.False(t, getStatus().Paused)
+
+	response = post("/streams/foo/pause?duration=nope")
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = post("/streams/foo/pause?duration=-1s")
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = post("/streams/bar/pause?duration=1s")
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamPausedStop(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Second*30))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	conf := map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"interval": "1ms",
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}
+
+	request := genRequest("POST", "/streams/foo", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	require.NoError(t, mgr.Pause("foo"))
+
+	// Allow a message to be consumed and held by the pause.
+	time.Sleep(time.Millisecond * 100)
+
+	// Updating a paused stream stops its previous version without waiting for
+	// the held message, and the new version remains paused.
+	start := time.Now()
+	request = genRequest("PUT", "/streams/foo", conf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Less(t, time.Since(start), time.Second*5)
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsPaused())
+
+	time.Sleep(time.Millisecond * 100)
+
+	start = time.Now()
+	request = genRequest("DELETE", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Less(t, time.Since(start), time.Second*5)
+
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+}
+
+func TestTypeAPIStreamDryRun(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo?dry_run=true", `
+input:
+  generate:
+    mapping: 'root = "hello world"'
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo?dry_run=true&chilled=true", `
+input:
+  generate:
+    mapping: 'root = "hello world"'
+output:
+  broker:
+    outputs:
+      - drop: {}
+      - type: nope
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "field output.broker.outputs.1: unknown output type 'nope'")
+
+	// Neither request creates the stream.
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code, response.Body.String())
+}
+
+func TestTypeAPILegacyCompat(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo", harmlessConf())
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	readFields := func(request *http.Request) map[string]any {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &fields))
+		return fields
+	}
+
+	fields := readFields(genRequest("GET", "/streams/foo", nil))
+	assert.Equal(t, true, fields["active"])
+	assert.NotContains(t, fields, "running")
+
+	fields = readFields(genRequest("GET", "/streams/foo?compat=legacy", nil))
+	assert.Equal(t, true, fields["running"])
+	assert.NotContains(t, fields, "active")
+	assert.Contains(t, fields, "uptime")
+	assert.Contains(t, fields, "config")
+
+	request = genRequest("GET", "/streams", nil)
+	request.Header.Set("X-Bento-Compat", "legacy")
+	fields = readFields(request)
+	require.Contains(t, fields, "foo")
+	assert.Equal(t, true, fields["foo"].(map[string]any)["running"])
+	assert.NotContains(t, fields["foo"], "active")
+
+	request = genRequest("GET", "/streams?compat=nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamsListShape(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/streams?shape=array", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `[]`, response.Body.String())
+
+	for _, id := range []string{"foo", "bar"} {
+		request = genRequest("POST", "/streams/"+id+"?label=team:"+id, harmlessConf())
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	// Uptimes and rates vary between requests and are therefore omitted from
+	// comparisons.
+	withoutVolatile := func(fields map[string]any) map[string]any {
+		delete(fields, "uptime")
+		delete(fields, "uptime_str")
+		delete(fields, "rate")
+		return fields
+	}
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var byID map[string]map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &byID))
+
+	request = genRequest("GET", "/streams?shape=array", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+
+	var ids []string
+	for _, entry := range entries {
+		id, _ := entry["id"].(string)
+		ids = append(ids, id)
+		delete(entry, "id")
+
+		require.Contains(t, byID, id)
+		assert.Equal(t, withoutVolatile(byID[id]), withoutVolatile(entry))
+	}
+	assert.Equal(t, []string{"bar", "foo"}, ids)
+
+	request = genRequest("GET", "/streams?shape=array&label=team:foo&compat=legacy", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	entries = nil
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "foo", entries[0]["id"])
+	assert.Equal(t, true, entries[0]["running"])
+
+	request = genRequest("GET", "/streams?shape=nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamsListSort(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetManualStart(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	// The stream x has run the longest, followed by z, whereas y is never
+	// started and is therefore pending.
+	for _, id := range []string{"x", "z", "y"} {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("POST", "/streams/"+id, harmlessConf()))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		if id != "y" {
+			require.NoError(t, mgr.Start(id))
+			time.Sleep(time.Millisecond * 20)
+		}
+	}
+
+	listIDs := func(query string) []string {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams?shape=array&"+query, nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var entries []map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &entries))
+
+		var ids []string
+		for _, entry := range entries {
+			id, _ := entry["id"].(string)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"x", "y", "z"}, listIDs("sort=id"))
+	assert.Equal(t, []string{"z", "y", "x"}, listIDs("sort=-id"))
+	assert.Equal(t, []string{"y", "z", "x"}, listIDs("sort=uptime"))
+	assert.Equal(t, []string{"x", "z", "y"}, listIDs("sort=-uptime"))
+	assert.Equal(t, []string{"y", "x", "z"}, listIDs("sort=state"))
+	assert.Equal(t, []string{"x", "z", "y"}, listIDs("sort=-state"))
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams?shape=array&sort=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams?sort=id", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+}
+
+func TestTypeAPIStreamsValidate(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	validate := func(t testing.TB, request *http.Request) (int, map[string]map[string]any) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		var report map[string]map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report), response.Body.String())
+		return response.Code, report
+	}
+
+	code, report := validate(t, genRequest("POST", "/streams/validate", map[string]any{
+		"foo": harmlessConf(),
+		"bar": map[string]any{
+			"input": map[string]any{
+				"generate": map[string]any{
+					"mapping":  "root = deleted()",
+					"not_real": "nope",
+				},
+			},
+			"output": map[string]any{
+				"drop": map[string]any{},
+			},
+		},
+		"baz": map[string]any{
+			"input": map[string]any{
+				"not_a_real_input": map[string]any{},
+			},
+			"output": map[string]any{
+				"drop": map[string]any{},
+			},
+		},
+	}))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, map[string]any{"valid": true}, report["foo"])
+	assert.Equal(t, false, report["bar"]["valid"])
+	assert.Contains(t, fmt.Sprint(report["bar"]["lints"]), "not_real")
+	assert.Equal(t, false, report["baz"]["valid"])
+	assert.NotEmpty(t, report["baz"]["lints"])
+	assert.Len(t, report, 3)
+
+	// Lint errors are ignored when chilled, where the configs are still
+	// validated.
+	code, report = validate(t, genYAMLRequest("POST", "/streams/validate?chilled=true", `
+input:
+  generate:
+    mapping: root = deleted()
+    not_real: nope
+output:
+  drop: {}
+---
+input:
+  not_a_real_input: {}
+output:
+  drop: {}
+`))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, map[string]any{"valid": true}, report["0"])
+	assert.Equal(t, false, report["1"]["valid"])
+	assert.Contains(t, report["1"]["error"], "not_a_real_input")
+	assert.Len(t, report, 2)
+
+	code, report = validate(t, genYAMLRequest("POST", "/streams/validate", map[string]any{
+		"foo": harmlessConf(),
+	}))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]map[string]any{
+		"foo": {"valid": true},
+	}, report)
+
+	// No streams are created by validating their configs.
+	for _, id := range []string{"foo", "bar", "baz", "0", "1"} {
+		_, err := mgr.Read(id)
+		assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+	}
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/validate", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestTypeAPIStreamMaxUptime(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetHealthCheckInterval(time.Millisecond*10))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo?max_uptime=nope", harmlessConf()))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo?max_uptime=200ms", harmlessConf()))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type streamInfo struct {
+		Active       bool   `json:"active"`
+		RestartCount int    `json:"restart_count"`
+		MaxUptime    string `json:"max_uptime"`
+		NextRestart  string `json:"next_restart"`
+	}
+	getInfo := func() (info streamInfo) {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return
+	}
+
+	info := getInfo()
+	assert.Equal(t, "200ms", info.MaxUptime)
+	assert.Equal(t, 0, info.RestartCount)
+	nextRestart, err := time.Parse(time.RFC3339, info.NextRestart)
+	require.NoError(t, err, info.NextRestart)
+	assert.WithinDuration(t, time.Now(), nextRestart, time.Second*2)
+
+	require.Eventually(t, func() bool {
+		return getInfo().RestartCount >= 1
+	}, time.Second*5, time.Millisecond*10)
+
+	assert.True(t, getInfo().Active)
+
+	// Scheduled restarts are disabled by a max uptime of zero.
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("PUT", "/streams/foo?max_uptime=0s", harmlessConf()))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info = getInfo()
+	assert.Empty(t, info.MaxUptime)
+	assert.Empty(t, info.NextRestart)
+	assert.Equal(t, 0, info.RestartCount)
+}
+
+func TestTypeAPIStreamAudit(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAuditLogSize(4))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := mgr.Router()
+
+	for _, req := range []struct {
+		request *http.Request
+		status  int
+	}{
+		{genRequest("POST", "/streams/foo", harmlessConf()), http.StatusOK},
+		{genRequest("POST", "/streams/bar", harmlessConf()), http.StatusOK},
+		{genRequest("POST", "/streams/baz?dry_run=true", harmlessConf()), http.StatusOK},
+		{genRequest("GET", "/streams/foo", nil), http.StatusOK},
+		{genRequest("PUT", "/streams/foo", harmlessConf()), http.StatusOK},
+		{genRequest("POST", "/streams/foo", harmlessConf()), http.StatusConflict},
+		{genRequest("DELETE", "/streams/bar", nil), http.StatusOK},
+	} {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, req.request)
+		require.Equal(t, req.status, response.Code, response.Body.String())
+	}
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/audit", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var entries []struct {
+		Time      time.Time `json:"time"`
+		Operation string    `json:"operation"`
+		ID        string    `json:"id"`
+		Outcome   string    `json:"outcome"`
+		Status    int       `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &entries), response.Body.String())
+	require.Len(t, entries, 4)
+
+	// Only the most recent operations are retained, and reads and dry runs
+	// are not recorded.
+	type op struct{ operation, id, outcome string }
+	var ops []op
+	for i, e := range entries {
+		ops = append(ops, op{e.Operation, e.ID, e.Outcome})
+		if i > 0 {
+			assert.False(t, e.Time.Before(entries[i-1].Time))
+		}
+	}
+	assert.Equal(t, []op{
+		{"create", "bar", "success"},
+		{"update", "foo", "success"},
+		{"create", "foo", "failure"},
+		{"delete", "bar", "success"},
+	}, ops)
+	assert.Equal(t, http.StatusConflict, entries[2].Status)
+}
+
+func TestTypeAPIStreamsSelfTest(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	for i := 0; i < 2; i++ {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("POST", "/streams/selftest", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var body struct {
+			Passed   bool   `json:"passed"`
+			Duration string `json:"duration"`
+			Error    string `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body), response.Body.String())
+		assert.True(t, body.Passed)
+		assert.Empty(t, body.Error)
+		assert.NotEmpty(t, body.Duration)
+	}
+
+	// The transient stream is not registered with the manager.
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "{}", response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/selftest", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestTypeAPIStreamsComponentFilter(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetManualStart(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	confs := map[string]string{
+		"plain": `
+input:
+  generate:
+    mapping: root = deleted()
+output:
+  drop: {}
+`,
+		"direct": `
+input:
+  generate:
+    mapping: root = deleted()
+pipeline:
+  processors:
+    - mapping: root = this
+output:
+  aws_s3:
+    bucket: foo
+`,
+		"brokered": `
+input:
+  broker:
+    inputs:
+      - generate:
+          mapping: root = deleted()
+output:
+  broker:
+    outputs:
+      - drop: {}
+      - switch:
+          cases:
+            - output:
+                aws_s3:
+                  bucket: foo
+                processors:
+                  - mapping: root = this
+`,
+	}
+	for id, conf := range confs {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genYAMLRequest("POST", "/streams/"+id, conf))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	list := func(t testing.TB, query string) []string {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams?"+query, nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+		var infos map[string]any
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &infos), response.Body.String())
+		ids := make([]string, 0, len(infos))
+		for id := range infos {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{"brokered", "direct"}, list(t, "output_type=aws_s3"))
+	assert.ElementsMatch(t, []string{"brokered", "plain"}, list(t, "output_type=drop"))
+	assert.ElementsMatch(t, []string{"brokered", "direct", "plain"}, list(t, "input_type=generate"))
+	assert.ElementsMatch(t, []string{"brokered"}, list(t, "input_type=broker"))
+	assert.ElementsMatch(t, []string{"brokered", "direct"}, list(t, "processor_type=mapping"))
+	assert.ElementsMatch(t, []string{"brokered"}, list(t, "output_type=drop&processor_type=mapping"))
+	assert.Empty(t, list(t, "output_type=stdout"))
+}
+
+func TestTypeAPIStreamStallTimeout(t *testing.T) {
+	var constructed atomic.Int64
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		constructed.Add(1)
+		return &stuckOutput{
+			pending:  make(chan message.Transaction, 1000),
+			closeSig: make(chan struct{}),
+			doneSig:  make(chan struct{}),
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_output",
+	}))
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &mock.Input{TChan: make(chan message.Transaction)}, nil
+	}, docs.ComponentSpec{
+		Name: "idle_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	var stalledEvents atomic.Int64
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*2),
+		manager.OptSetHealthCheckInterval(time.Millisecond*10),
+		manager.OptAddLifecycleHook(func(e manager.LifecycleEvent) {
+			if e.Type == manager.LifecycleEventHealth && e.Status != nil && e.Status.State() == manager.StreamStateStalled {
+				stalledEvents.Add(1)
+			}
+		}),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/stuck?stall_timeout=200ms", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"count":    5,
+				"interval": "10ms",
+			},
+		},
+		"output": map[string]any{
+			"stuck_output": map[string]any{},
+		},
+	}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// An idle stream is not considered stalled whilst its input is inactive.
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/idle?stall_timeout=200ms", map[string]any{
+		"input": map[string]any{
+			"idle_input": map[string]any{},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	type streamInfo struct {
+		State        string `json:"state"`
+		RestartCount int    `json:"restart_count"`
+		StallTimeout string `json:"stall_timeout"`
+	}
+	getInfo := func(id string) (info streamInfo) {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/"+id, nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return
+	}
+	assert.Equal(t, "200ms", getInfo("stuck").StallTimeout)
+
+	require.Eventually(t, func() bool {
+		return getInfo("stuck").RestartCount >= 1
+	}, time.Second*10, time.Millisecond*10)
+	assert.GreaterOrEqual(t, stalledEvents.Load(), int64(1))
+	assert.GreaterOrEqual(t, constructed.Load(), int64(2))
+
+	info := getInfo("idle")
+	assert.Equal(t, manager.StreamStateRunning, info.State)
+	assert.Equal(t, 0, info.RestartCount)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/bad?stall_timeout=nope", harmlessConf()))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	// Deleting the stream via the API forces the stuck output to close within
+	// the API timeout.
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("DELETE", "/streams/stuck", nil))
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+}
+
+// unclosableOutput holds every transaction it consumes until released, and does
+// not close until released regardless of being triggered to close.
+type unclosableOutput struct {
+	release chan struct{}
+}
+
+func (u *unclosableOutput) Consume(ts <-chan message.Transaction) error {
+	go func() {
+		for t := range ts {
+			go func(t message.Transaction) {
+				<-u.release
+				_ = t.Ack(context.Background(), nil)
+			}(t)
+		}
+	}()
+	return nil
+}
+
+func (u *unclosableOutput) ConnectionStatus() component.ConnectionStatuses {
+	return component.ConnectionStatuses{
+		component.ConnectionActive(component.NoopObservability()),
+	}
+}
+
+func (u *unclosableOutput) TriggerCloseNow() {}
+
+func (u *unclosableOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-u.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTypeAPIStreamStallRestartStopFailed(t *testing.T) {
+	var constructed atomic.Int64
+	release := make(chan struct{})
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		constructed.Add(1)
+		return &unclosableOutput{release: release}, nil
+	}, docs.ComponentSpec{
+		Name: "unclosable_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	var stalledEvents atomic.Int64
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Millisecond*100),
+		manager.OptSetHealthCheckInterval(time.Millisecond*10),
+		manager.OptAddLifecycleHook(func(e manager.LifecycleEvent) {
+			if e.Type == manager.LifecycleEventHealth && e.Status != nil && e.Status.State() == manager.StreamStateStalled {
+				stalledEvents.Add(1)
+			}
+		}),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo?stall_timeout=100ms", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"interval": "10ms",
+			},
+		},
+		"output": map[string]any{
+			"unclosable_output": map[string]any{},
+		},
+	}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// The stream cannot be stopped for a restart, which is attempted again
+	// rather than the stream remaining marked as stalled.
+	require.Eventually(t, func() bool {
+		return stalledEvents.Load() >= 2
+	}, time.Second*10, time.Millisecond*10)
+	assert.Equal(t, int64(1), constructed.Load())
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return constructed.Load() >= 2
+	}, time.Second*10, time.Millisecond*10)
+}
+
+func TestTypeAPIStreamQuarantine(t *testing.T) {
+	var broken atomic.Bool
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		if broken.Load() {
+			return nil, errors.New("input is broken")
+		}
+		return &mock.Input{TChan: make(chan message.Transaction)}, nil
+	}, docs.ComponentSpec{
+		Name: "flaky_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetHealthCheckInterval(time.Millisecond*10),
+		manager.OptSetQuarantineThreshold(3),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo?max_uptime=100ms", map[string]any{
+		"input":  map[string]any{"flaky_input": map[string]any{}},
+		"output": map[string]any{"drop": map[string]any{}},
+	}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	getState := func() string {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		var info struct {
+			State string `json:"state"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+		return info.State
+	}
+	assert.Equal(t, manager.StreamStateRunning, getState())
+
+	// The stream fails to be constructed when it is next restarted, and is
+	// quarantined once the retries are exhausted.
+	broken.Store(true)
+	require.Eventually(t, func() bool {
+		return getState() == manager.StreamStateQuarantined
+	}, time.Second*5, time.Millisecond*10)
+
+	type quarantinedStream struct {
+		Reason   string `json:"reason"`
+		Since    string `json:"since"`
+		Failures int    `json:"failures"`
+	}
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/quarantine", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var quarantined map[string]quarantinedStream
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &quarantined))
+	require.Contains(t, quarantined, "foo")
+	assert.Contains(t, quarantined["foo"].Reason, "input is broken")
+	assert.Equal(t, 3, quarantined["foo"].Failures)
+	_, err = time.Parse(time.RFC3339, quarantined["foo"].Since)
+	assert.NoError(t, err)
+
+	// Quarantined streams are not retried, and do not count against readiness.
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, manager.StreamStateQuarantined, getState())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// Releasing a stream that still fails keeps it quarantined.
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/unquarantine", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+	assert.Equal(t, manager.StreamStateQuarantined, getState())
+
+	broken.Store(false)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/unquarantine", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, manager.StreamStateRunning, getState())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/quarantine", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{}`, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/unquarantine", nil))
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+}
+
+func TestTypeAPITimeoutReconfigure(t *testing.T) {
+	stuck := &stuckOutput{
+		pending:  make(chan message.Transaction, 10),
+		closeSig: make(chan struct{}),
+		doneSig:  make(chan struct{}),
+	}
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return stuck, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptSetAPITimeout(time.Second*20))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	r := router(mgr)
+
+	getTimeout := func() string {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/timeout", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		var body struct {
+			Timeout string `json:"timeout"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		return body.Timeout
+	}
+	assert.Equal(t, "20s", getTimeout())
+
+	for _, timeout := range []string{"nope", "0s", "-1s"} {
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("PUT", "/streams/timeout", map[string]any{"timeout": timeout}))
+		assert.Equal(t, http.StatusBadRequest, response.Code, timeout)
+	}
+	assert.Equal(t, "20s", getTimeout())
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "hello world"`,
+				"count":    1,
+				"interval": "",
+			},
+		},
+		"output": map[string]any{
+			"stuck_output": map[string]any{},
+		},
+	}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	require.Eventually(t, func() bool {
+		return len(stuck.pending) == 1
+	}, time.Second*5, time.Millisecond*10)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("PUT", "/streams/timeout", map[string]any{"timeout": "200ms"}))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "200ms", getTimeout())
+	assert.Equal(t, time.Millisecond*200, mgr.APITimeout())
+
+	// The stream cannot shut down gracefully whilst its output holds a
+	// message, and therefore a deletion is bounded by the new timeout rather
+	// than the one the manager was created with.
+	start := time.Now()
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("DELETE", "/streams/foo", nil))
+	elapsed := time.Since(start)
+	assert.Less(t, elapsed, time.Second*10, response.Body.String())
+	assert.GreaterOrEqual(t, elapsed, time.Millisecond*100, response.Body.String())
+}
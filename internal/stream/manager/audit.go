@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultAuditLogSize = 100
+
+// Operations recorded by the audit log.
+const (
+	auditOperationCreate = "create"
+	auditOperationUpdate = "update"
+	auditOperationDelete = "delete"
+)
+
+// Outcomes of operations recorded by the audit log.
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeFailure = "failure"
+)
+
+// OptSetAuditLogSize sets the number of recent operations that create, update
+// or delete streams via the API which are retained in memory, and can be read
+// from the /streams/audit endpoint. The default is 100, and a value of zero
+// disables the audit log.
+func OptSetAuditLogSize(n int) func(*Type) {
+	return func(t *Type) {
+		t.auditLogSize = n
+	}
+}
+
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	ID        string    `json:"id"`
+	Outcome   string    `json:"outcome"`
+	Status    int       `json:"status"`
+}
+
+// auditLog retains a bounded history of the operations performed on streams.
+type auditLog struct {
+	mut     sync.Mutex
+	entries []auditEntry
+}
+
+func (a *auditLog) add(size int, e auditEntry) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	a.entries = append(a.entries, e)
+	if len(a.entries) > size {
+		a.entries = a.entries[len(a.entries)-size:]
+	}
+}
+
+func (a *auditLog) list() []auditEntry {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return append([]auditEntry{}, a.entries...)
+}
+
+// auditOperation returns the operation that a request to the /streams/{id}
+// endpoint performs, or an empty string for requests that do not modify the
+// stream, including dry runs.
+func auditOperation(r *http.Request) string {
+	if r.URL.Query().Get("dry_run") == "true" {
+		return ""
+	}
+	switch r.Method {
+	case "POST":
+		return auditOperationCreate
+	case "PUT", "PATCH":
+		return auditOperationUpdate
+	case "DELETE":
+		return auditOperationDelete
+	}
+	return ""
+}
+
+// wrapAudit records the operations performed by the requests to a handler of
+// the /streams/{id} endpoint in the audit log, where the outcome is determined
+// by the status of the response.
+func (m *Type) wrapAudit(h http.HandlerFunc) http.HandlerFunc {
+	if m.auditLogSize <= 0 {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		op := auditOperation(r)
+		if op == "" {
+			h(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		h(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		outcome := auditOutcomeSuccess
+		if status >= http.StatusBadRequest {
+			outcome = auditOutcomeFailure
+		}
+		m.audit.add(m.auditLogSize, auditEntry{
+			Time:      time.Now(),
+			Operation: op,
+			ID:        mux.Vars(r)["id"],
+			Outcome:   outcome,
+			Status:    status,
+		})
+	}
+}
+
+// HandleStreamAudit is an http.HandleFunc for returning (GET) the recent
+// operations that created, updated or deleted streams via the API, oldest
+// first, along with the time and outcome of each.
+func (m *Type) HandleStreamAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	bodyBytes, err := json.Marshal(m.audit.list())
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bodyBytes)
+}
@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// BootMode determines how stream configs that fail to load when booting a set
+// of streams are treated.
+type BootMode string
+
+// Boot modes, which are mutually exclusive.
+const (
+	// BootModeStrict aborts boot when any stream config fails to be read or
+	// constructed, in which case no streams are run.
+	BootModeStrict BootMode = "strict"
+
+	// BootModeLenient runs each stream that loads successfully, continuing
+	// past those that fail, which are reported collectively.
+	BootModeLenient BootMode = "lenient"
+)
+
+// ParseBootMode returns the boot mode of a name, which is either strict or
+// lenient.
+func ParseBootMode(name string) (BootMode, error) {
+	switch mode := BootMode(name); mode {
+	case BootModeStrict, BootModeLenient:
+		return mode, nil
+	}
+	return "", fmt.Errorf("boot mode '%v' not recognised, expected one of: strict, lenient", name)
+}
+
+// Boot creates a stream for each of a set of stream configs loaded at boot,
+// such as those read from directories with config.Reader.ReadStreamsLenient,
+// where loadErr is the error returned whilst reading them, naming each config
+// that failed to load. The opts func provides the options of each stream,
+// such as their origin, and may be nil.
+//
+// In strict mode any failure aborts boot: configs are validated before any
+// stream is created, and streams that were created before a later stream
+// failed to construct are removed again, such that either every stream is
+// running or none are. In lenient mode the streams that load successfully are
+// run regardless. In both modes the returned error aggregates every failure.
+func (m *Type) Boot(ctx context.Context, mode BootMode, confs map[string]stream.Config, loadErr error, opts func(id string) []StreamOpt) error {
+	if _, err := ParseBootMode(string(mode)); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(confs))
+	for id := range confs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []error
+	if loadErr != nil {
+		errs = append(errs, loadErr)
+	}
+
+	if mode == BootModeStrict {
+		for _, id := range ids {
+			if err := m.ValidateConfig(confs[id]); err != nil {
+				errs = append(errs, fmt.Errorf("stream '%v': %w", id, err))
+			}
+		}
+		if len(errs) > 0 {
+			return bootErr(errs)
+		}
+	}
+
+	var created []string
+	for _, id := range ids {
+		var streamOpts []StreamOpt
+		if opts != nil {
+			streamOpts = opts(id)
+		}
+		if err := m.Create(id, confs[id], streamOpts...); err != nil {
+			errs = append(errs, fmt.Errorf("stream '%v': %w", id, err))
+			if mode == BootModeStrict {
+				break
+			}
+			continue
+		}
+		created = append(created, id)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if mode == BootModeStrict {
+		for _, id := range created {
+			if err := m.Delete(ctx, id); err != nil {
+				m.manager.Logger().Error("Failed to remove stream '%v' after aborting boot: %v\n", id, err)
+			}
+		}
+	}
+	return bootErr(errs)
+}
+
+func bootErr(errs []error) error {
+	return fmt.Errorf("failed to boot streams:\n%w", errors.Join(errs...))
+}
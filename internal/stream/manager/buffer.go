@@ -0,0 +1,33 @@
+package manager
+
+import (
+	"github.com/warpstreamlabs/bento/internal/component/buffer"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// OptSetDefaultBuffer sets a buffer that is given to streams whose config does
+// not specify one, or specifies the none buffer, allowing a policy such as a
+// bounded memory buffer to provide consistent backpressure behaviour without
+// editing every config. Streams that configure a buffer keep their own.
+//
+// The default is applied to the config that a stream is created with, and is
+// therefore not reflected within the configs returned by the API, which are
+// those that were submitted.
+func OptSetDefaultBuffer(conf buffer.Config) func(*Type) {
+	return func(t *Type) {
+		t.defaultBuffer = &conf
+	}
+}
+
+// withDefaultBuffer returns the provided stream config with the default buffer
+// of the manager when it does not configure a buffer of its own.
+func (m *Type) withDefaultBuffer(conf stream.Config) stream.Config {
+	if m.defaultBuffer == nil {
+		return conf
+	}
+	if conf.Buffer.Type != "" && conf.Buffer.Type != "none" {
+		return conf
+	}
+	conf.Buffer = *m.defaultBuffer
+	return conf
+}
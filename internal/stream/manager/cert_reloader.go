@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+// certRecheckInterval is the minimum period between checks of whether the
+// certificate files of a server have been modified, which avoids reading the
+// metadata of both files on every TLS handshake.
+const certRecheckInterval = time.Second
+
+// certReloader provides a certificate and key read from files, which are read
+// again when either file is modified. When a modified certificate cannot be
+// loaded the previous certificate continues to be served.
+type certReloader struct {
+	certFile, keyFile string
+	log               log.Modular
+
+	recheckInterval time.Duration
+	stat            func(name string) (os.FileInfo, error)
+
+	mut          sync.Mutex
+	cert         *tls.Certificate
+	certModTime  time.Time
+	keyModTime   time.Time
+	lastChecked  time.Time
+	lastErrorMod [2]time.Time
+}
+
+func newCertReloader(certFile, keyFile string, logger log.Modular) (*certReloader, error) {
+	c := &certReloader{
+		certFile:        certFile,
+		keyFile:         keyFile,
+		log:             logger,
+		recheckInterval: certRecheckInterval,
+		stat:            os.Stat,
+	}
+	certMod, keyMod, err := c.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(certMod, keyMod); err != nil {
+		return nil, err
+	}
+	c.lastChecked = time.Now()
+	return c, nil
+}
+
+func (c *certReloader) modTimes() (certMod, keyMod time.Time, err error) {
+	var info os.FileInfo
+	if info, err = c.stat(c.certFile); err != nil {
+		return
+	}
+	certMod = info.ModTime()
+	if info, err = c.stat(c.keyFile); err != nil {
+		return
+	}
+	keyMod = info.ModTime()
+	return
+}
+
+func (c *certReloader) load(certMod, keyMod time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.cert, c.certModTime, c.keyModTime = &cert, certMod, keyMod
+	return nil
+}
+
+func (c *certReloader) certificate() *tls.Certificate {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastChecked) < c.recheckInterval {
+		return c.cert
+	}
+	c.lastChecked = now
+
+	certMod, keyMod, err := c.modTimes()
+	if err != nil {
+		// The files may be mid-rotation, in which case the current
+		// certificate is served until they are replaced.
+		return c.cert
+	}
+	if certMod.Equal(c.certModTime) && keyMod.Equal(c.keyModTime) {
+		return c.cert
+	}
+	if err := c.load(certMod, keyMod); err != nil {
+		if c.lastErrorMod != [2]time.Time{certMod, keyMod} {
+			c.lastErrorMod = [2]time.Time{certMod, keyMod}
+			c.log.Error("Failed to reload TLS certificate, continuing to serve the previous certificate: %v\n", err)
+		}
+		return c.cert
+	}
+	c.log.Info("Reloaded TLS certificate from %v\n", c.certFile)
+	return c.cert
+}
@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"crypto/tls"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+func TestCertReloaderRecheckInterval(t *testing.T) {
+	var stats atomic.Int64
+	cert := &tls.Certificate{}
+
+	c := &certReloader{
+		certFile:        "server.crt",
+		keyFile:         "server.key",
+		log:             log.Noop(),
+		recheckInterval: time.Hour,
+		stat: func(name string) (os.FileInfo, error) {
+			stats.Add(1)
+			return nil, os.ErrNotExist
+		},
+		cert:        cert,
+		lastChecked: time.Now(),
+	}
+
+	// Handshakes within the recheck interval do not check the files.
+	for i := 0; i < 100; i++ {
+		assert.Same(t, cert, c.certificate())
+	}
+	assert.Equal(t, int64(0), stats.Load())
+
+	// Once the interval elapses the files are checked, and the current
+	// certificate continues to be served whilst they are missing.
+	c.lastChecked = time.Now().Add(-time.Hour)
+	assert.Same(t, cert, c.certificate())
+	assert.Equal(t, int64(1), stats.Load())
+
+	assert.Same(t, cert, c.certificate())
+	assert.Equal(t, int64(1), stats.Load())
+}
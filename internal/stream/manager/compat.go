@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// compatHeader is the request header with which a compatibility mode can be
+// selected, as an alternative to the query parameter compat.
+const compatHeader = "X-Bento-Compat"
+
+// compatModeLegacy serves stream status bodies with the field names of the
+// legacy streams API schema.
+const compatModeLegacy = "legacy"
+
+// legacyStatusFields maps the fields of stream status bodies to the names that
+// they had in the legacy streams API schema. Fields that are absent keep their
+// name.
+var legacyStatusFields = map[string]string{
+	"active": "running",
+}
+
+// requestCompatMode returns the compatibility mode requested with either the
+// query parameter compat or the X-Bento-Compat header, which is empty when no
+// mode is requested.
+func requestCompatMode(r *http.Request) (string, error) {
+	mode := r.URL.Query().Get("compat")
+	if mode == "" {
+		mode = r.Header.Get(compatHeader)
+	}
+	switch mode {
+	case "", compatModeLegacy:
+		return mode, nil
+	}
+	return "", fmt.Errorf("compatibility mode '%v' not recognised", mode)
+}
+
+// withLegacyStatusFields returns a status body with its fields renamed
+// according to legacyStatusFields, where the body is any value that encodes
+// as a JSON object.
+func withLegacyStatusFields(body any) (map[string]any, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, legacyName := range legacyStatusFields {
+		if v, exists := fields[name]; exists {
+			delete(fields, name)
+			fields[legacyName] = v
+		}
+	}
+	return fields, nil
+}
+
+// listShapeArray serves the list of streams as an array of status bodies that
+// each embed the id of their stream, rather than as an object keyed by id.
+const listShapeArray = "array"
+
+// requestListShape returns the shape of the list of streams requested with the
+// query parameter shape, which is either an object keyed by stream id, the
+// default, or an array.
+func requestListShape(r *http.Request) (string, error) {
+	switch shape := r.URL.Query().Get("shape"); shape {
+	case "", "object":
+		return "", nil
+	case listShapeArray:
+		return shape, nil
+	default:
+		return "", fmt.Errorf("list shape '%v' not recognised", shape)
+	}
+}
+
+// Keys that an array of streams can be sorted by with the query parameter
+// sort, each of which may be prefixed with - in order to reverse the order.
+const (
+	listSortID     = "id"
+	listSortUptime = "uptime"
+	listSortState  = "state"
+)
+
+// requestListSort returns the key that the list of streams is requested to be
+// sorted by with the query parameter sort, along with whether it is in
+// descending order, which is indicated by prefixing the key with -. The key is
+// empty when no order is requested.
+func requestListSort(r *http.Request) (key string, desc bool, err error) {
+	v := r.URL.Query().Get("sort")
+	if v == "" {
+		return "", false, nil
+	}
+	key = strings.TrimPrefix(v, "-")
+	switch key {
+	case listSortID, listSortUptime, listSortState:
+		return key, key != v, nil
+	}
+	return "", false, fmt.Errorf("sort key '%v' not recognised, expected one of: %v, %v, %v", v, listSortID, listSortUptime, listSortState)
+}
+
+// listAsArray converts a list body keyed by stream id, which is any value that
+// encodes as a JSON object of objects, into an array of those objects ordered
+// by stream id, where each has the id of its stream added as the field id.
+// When compare is non-nil the array is instead ordered by it, where streams
+// that compare as equal remain ordered by id.
+func listAsArray(body any, compare func(a, b string) int) ([]map[string]any, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var byID map[string]map[string]any
+	if err := json.Unmarshal(b, &byID); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if compare != nil {
+		slices.SortStableFunc(ids, compare)
+	}
+
+	entries := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		entry := byID[id]
+		if entry == nil {
+			entry = map[string]any{}
+		}
+		entry["id"] = id
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
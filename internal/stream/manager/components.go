@@ -0,0 +1,198 @@
+package manager
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// ValidateConfig checks that each component referenced by a stream config,
+// including those nested within other components such as brokers, is of a
+// type that is registered with the environment of the manager. Returns an
+// error matching ErrStreamConfigInvalid that names the field of the first
+// unknown component found.
+//
+// Stream configs are validated this way when streams are created or updated,
+// such that configs referencing unknown components are rejected before any of
+// the components of the stream are constructed.
+func (m *Type) ValidateConfig(conf stream.Config) error {
+	var node yaml.Node
+	if err := node.Encode(conf); err != nil {
+		return err
+	}
+	if err := checkFieldsComponents(m.manager.Environment(), stream.Spec(), "", &node, nil); err != nil {
+		return withErrorKind(ErrStreamConfigInvalid, err)
+	}
+	return nil
+}
+
+// usesComponents returns whether a stream config uses each of the provided
+// component names somewhere within it, keyed by the type of component, which
+// includes components nested within others such as the outputs of a broker or
+// switch.
+func (m *Type) usesComponents(conf stream.Config, names map[docs.Type]string) (bool, error) {
+	var node yaml.Node
+	if err := node.Encode(conf); err != nil {
+		return false, err
+	}
+
+	found := map[docs.Type]bool{}
+	if err := checkFieldsComponents(m.manager.Environment(), stream.Spec(), "", &node, func(cType docs.Type, name string) {
+		if names[cType] == name {
+			found[cType] = true
+		}
+	}); err != nil {
+		return false, err
+	}
+	return len(found) == len(names), nil
+}
+
+// componentFilterParams are the query parameters with which the list of streams
+// can be filtered by the components that they use.
+var componentFilterParams = map[string]docs.Type{
+	"input_type":     docs.TypeInput,
+	"processor_type": docs.TypeProcessor,
+	"output_type":    docs.TypeOutput,
+}
+
+// parseComponentFilter returns the component names that streams listed by a
+// request must use, keyed by the type of component.
+func parseComponentFilter(r *http.Request) map[docs.Type]string {
+	names := map[docs.Type]string{}
+	for param, cType := range componentFilterParams {
+		if v := r.URL.Query().Get(param); v != "" {
+			names[cType] = v
+		}
+	}
+	return names
+}
+
+func componentFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func unwrapDocumentNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// componentVisitor is called with the type and name of each component found
+// whilst checking the components of a config.
+type componentVisitor func(cType docs.Type, name string)
+
+func checkFieldsComponents(prov docs.Provider, specs docs.FieldSpecs, path string, node *yaml.Node, visit componentVisitor) error {
+	node = unwrapDocumentNode(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		key := node.Content[i].Value
+		for _, f := range specs {
+			if f.Name != key {
+				continue
+			}
+			if err := checkFieldComponents(prov, f, componentFieldPath(path, key), node.Content[i+1], visit); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func checkFieldComponents(prov docs.Provider, f docs.FieldSpec, path string, node *yaml.Node, visit componentVisitor) error {
+	coreType, isCore := f.Type.IsCoreComponent()
+	if !isCore && len(f.Children) == 0 {
+		return nil
+	}
+
+	checkElement := func(path string, node *yaml.Node) error {
+		if isCore {
+			return checkComponent(prov, coreType, path, node, visit)
+		}
+		return checkFieldsComponents(prov, f.Children, path, node, visit)
+	}
+
+	node = unwrapDocumentNode(node)
+	switch f.Kind {
+	case docs.Kind2DArray:
+		for i, row := range node.Content {
+			for j, elem := range row.Content {
+				if err := checkElement(componentFieldPath(path, strconv.Itoa(i)+"."+strconv.Itoa(j)), elem); err != nil {
+					return err
+				}
+			}
+		}
+	case docs.KindArray:
+		for i, elem := range node.Content {
+			if err := checkElement(componentFieldPath(path, strconv.Itoa(i)), elem); err != nil {
+				return err
+			}
+		}
+	case docs.KindMap:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if err := checkElement(componentFieldPath(path, node.Content[i].Value), node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	default:
+		return checkElement(path, node)
+	}
+	return nil
+}
+
+func checkComponent(prov docs.Provider, cType docs.Type, path string, node *yaml.Node, visit componentVisitor) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value != "type" {
+			continue
+		}
+		if tStr := node.Content[i+1].Value; tStr != "" {
+			if _, exists := prov.GetDocs(tStr, cType); !exists {
+				return fmt.Errorf("field %v: unknown %v type '%v'", path, cType, tStr)
+			}
+		}
+	}
+
+	name, spec, err := docs.GetInferenceCandidateFromYAML(prov, cType, node)
+	if err != nil {
+		return fmt.Errorf("field %v: %w", path, err)
+	}
+	if visit != nil {
+		visit(cType, name)
+	}
+
+	reservedFields := docs.ReservedFieldsByType(cType)
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch key {
+		case "type", "label":
+		case name, "plugin":
+			// Configs encoded from structs hold the config of the component
+			// under the field plugin, but are named by their type in errors.
+			if err := checkFieldComponents(prov, spec.Config, componentFieldPath(path, name), value, visit); err != nil {
+				return err
+			}
+		default:
+			if f, exists := reservedFields[key]; exists {
+				if err := checkFieldComponents(prov, f, componentFieldPath(path, key), value, visit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxDecompressedBodySize is the default limit on the size that a
+// compressed request body may decompress to.
+const defaultMaxDecompressedBodySize = 64 << 20
+
+var errBodyTooLarge = errors.New("decompressed request body exceeds the maximum size")
+
+// OptSetMaxDecompressedBodySize sets the maximum number of bytes that a
+// compressed request body may decompress to, beyond which the request is
+// rejected with 413 Request Entity Too Large. This guards against small
+// payloads that decompress to an excessive size. The default is 64MiB, and a
+// value of zero or less removes the limit.
+func OptSetMaxDecompressedBodySize(n int64) func(*Type) {
+	return func(t *Type) {
+		t.maxDecompressedBodySize = n
+	}
+}
+
+// gzipCompress returns the gzip compressed form of b.
+func gzipCompress(b []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	if _, err := gw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// gzipDecompress returns a reader of the decompressed form of a gzip stream,
+// which fails with errBodyTooLarge once more than limit bytes have been read.
+// A limit of zero or less means the decompressed size is unlimited.
+func gzipDecompress(r io.Reader, limit int64) (*gzip.Reader, *sizeLimitedReader, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gr, &sizeLimitedReader{r: gr, remaining: limit, unlimited: limit <= 0}, nil
+}
+
+// sizeLimitedReader reads from an underlying reader until a number of bytes
+// have been read, failing with errBodyTooLarge if there are bytes remaining.
+// Decoders may not preserve the errors of readers, and therefore whether the
+// limit was exceeded is also recorded.
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+	unlimited bool
+	exceeded  bool
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.unlimited {
+		return l.r.Read(p)
+	}
+	if l.remaining <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, errBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+type decompressedBody struct {
+	*sizeLimitedReader
+	closers []io.Closer
+}
+
+func (d *decompressedBody) Close() error {
+	var err error
+	for _, c := range d.closers {
+		if cErr := c.Close(); err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// decompressRequestBody replaces the body of a request with its decompressed
+// form according to the Content-Encoding header, which is either gzip or
+// identity, limited to the maximum decompressed body size of the manager.
+func (m *Type) decompressRequestBody(r *http.Request) error {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		gr, body, err := gzipDecompress(r.Body, m.maxDecompressedBodySize)
+		if err != nil {
+			return fmt.Errorf("failed to decompress request body: %w", err)
+		}
+		r.Body = &decompressedBody{sizeLimitedReader: body, closers: []io.Closer{gr, r.Body}}
+		r.Header.Del("Content-Encoding")
+		r.ContentLength = -1
+		return nil
+	default:
+		return fmt.Errorf("content encoding '%v' not supported", encoding)
+	}
+}
+
+// bodyLimitExceeded returns whether the decompressed body of a request was
+// found to exceed the maximum decompressed body size whilst reading it.
+func bodyLimitExceeded(r *http.Request) bool {
+	body, ok := r.Body.(*decompressedBody)
+	return ok && body.exceeded
+}
+
+// acceptsEncoding returns whether the Accept-Encoding header of a request
+// includes an encoding with a non-zero quality value.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if qStr, isQ := strings.CutPrefix(strings.TrimSpace(param), "q="); isQ {
+				q, err := strconv.ParseFloat(qStr, 64)
+				return err == nil && q > 0
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeNegotiatedBody writes a response body, which is gzip compressed when
+// the request accepts it.
+func writeNegotiatedBody(w http.ResponseWriter, r *http.Request, b []byte) error {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if acceptsEncoding(r, "gzip") {
+		var err error
+		if b, err = gzipCompress(b); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	_, err := w.Write(b)
+	return err
+}
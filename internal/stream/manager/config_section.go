@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// streamConfigSections are the sections of a stream config that can be read
+// and replaced in isolation.
+var streamConfigSections = map[string]struct{}{
+	"input":    {},
+	"buffer":   {},
+	"pipeline": {},
+	"output":   {},
+}
+
+// HandleStreamConfigSection is an http.HandleFunc for reading (GET) and
+// replacing (PUT) an individual section of the config of a stream, which is
+// one of input, buffer, pipeline or output. Sections are served as JSON by
+// default, or YAML when the query parameter format=yaml is provided. A
+// replacement section is validated as part of the whole stream config before
+// the stream is updated.
+func (m *Type) HandleStreamConfigSection(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream config section Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream config section request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id, section := mux.Vars(r)["id"], mux.Vars(r)["section"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+	if _, exists := streamConfigSections[section]; !exists {
+		http.Error(w, "Config section not found", http.StatusNotFound)
+		return
+	}
+
+	var info *StreamStatus
+	if info, serverErr = m.Read(id); serverErr != nil {
+		return
+	}
+
+	conf := info.Config()
+	rawConf, _ := value.IClone(conf.GetRawSource()).(map[string]any)
+	if rawConf == nil {
+		rawConf = map[string]any{}
+	}
+
+	switch r.Method {
+	case "GET":
+		var served any
+		if served, serverErr = m.servedConfig(conf); serverErr != nil {
+			return
+		}
+		servedConf, _ := served.(map[string]any)
+
+		var resBytes []byte
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "json":
+			if resBytes, serverErr = json.Marshal(servedConf[section]); serverErr == nil {
+				w.Header().Set("Content-Type", "application/json")
+			}
+		case "yaml":
+			if resBytes, serverErr = yaml.Marshal(servedConf[section]); serverErr == nil {
+				w.Header().Set("Content-Type", "application/yaml")
+			}
+		default:
+			requestErr = fmt.Errorf("unsupported format: %v", format)
+		}
+		if serverErr == nil && requestErr == nil {
+			_, _ = w.Write(resBytes)
+		}
+	case "PUT":
+		if info.origin == StreamOriginRegistered {
+			serverErr = ErrStreamRegistered
+			return
+		}
+
+		var sectionBytes []byte
+		if sectionBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+
+		ignoreLints := r.URL.Query().Get("chilled") == "true"
+		if sectionBytes, requestErr = config.ReplaceEnvVariables(sectionBytes, os.LookupEnv); requestErr != nil {
+			var errEnvMissing *config.ErrMissingEnvVars
+			if !ignoreLints || !errors.As(requestErr, &errEnvMissing) {
+				return
+			}
+			sectionBytes, requestErr = errEnvMissing.BestAttempt, nil
+		}
+
+		var sectionConf any
+		if requestErr = yaml.Unmarshal(sectionBytes, &sectionConf); requestErr != nil {
+			return
+		}
+		rawConf[section] = sectionConf
+
+		var confNode yaml.Node
+		if requestErr = confNode.Encode(rawConf); requestErr != nil {
+			return
+		}
+
+		if !ignoreLints {
+			if lints := m.lintStreamConfigNode(&confNode); len(lints) > 0 {
+				for _, l := range lints {
+					m.manager.Logger().Info("Stream '%v' config: %v\n", id, l)
+				}
+				errBytes, _ := json.Marshal(lintErrors{
+					LintErrs: lints,
+				})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write(errBytes)
+				return
+			}
+		}
+
+		var pConf *docs.ParsedConfig
+		if pConf, requestErr = stream.Spec().ParsedConfigFromAny(&confNode); requestErr != nil {
+			return
+		}
+		if conf, requestErr = stream.FromParsed(m.manager.Environment(), pConf, rawConf); requestErr != nil {
+			return
+		}
+
+		ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+		defer done()
+		serverErr = m.Update(ctx, id, conf)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// ErrStreamConnectTimeout is returned when a stream created with a connection
+// timeout fails to connect its inputs and outputs within it, and also matches
+// ErrStreamTimeout.
+var ErrStreamConnectTimeout = errors.New("stream failed to connect within the connection timeout")
+
+// CreateWithConnectTimeout attempts to construct and run a new stream under a
+// unique ID, and then waits for the inputs and outputs of the stream to
+// connect. If the stream is not connected once the timeout has elapsed, which
+// includes the time taken to construct its components, then the stream is
+// removed and an error wrapping ErrStreamConnectTimeout is returned.
+//
+// The timeout is independent of the context, which bounds the removal of a
+// stream that fails to connect. Streams that are not started, such as when the
+// manager is configured with OptSetManualStart, are not waited upon.
+func (m *Type) CreateWithConnectTimeout(ctx context.Context, id string, conf stream.Config, timeout time.Duration, opts ...StreamOpt) error {
+	return m.createWithConnectTimeout(ctx, id, conf, !m.manualStart, timeout, opts...)
+}
+
+func (m *Type) createWithConnectTimeout(ctx context.Context, id string, conf stream.Config, start bool, timeout time.Duration, opts ...StreamOpt) error {
+	deadline := time.Now().Add(timeout)
+	if err := m.create(id, conf, nil, start, opts...); err != nil {
+		return err
+	}
+	if !start {
+		return nil
+	}
+
+	m.lock.Lock()
+	wrapper := m.streams[id]
+	m.lock.Unlock()
+	if wrapper == nil {
+		return ErrStreamDoesNotExist
+	}
+
+	if waitForReady(context.Background(), wrapper, time.Until(deadline)) {
+		return nil
+	}
+
+	m.lock.Lock()
+	current := m.streams[id] == wrapper
+	m.lock.Unlock()
+	if current {
+		m.manager.Logger().Warn("Stream '%v' failed to connect within %v, removing it\n", id, timeout)
+		if err := m.ForceDelete(ctx, id); err != nil && !errors.Is(err, ErrStreamDoesNotExist) {
+			return withErrorKind(ErrStreamTimeout, fmt.Errorf("%w: %v, and failed to remove it: %v", ErrStreamConnectTimeout, timeout, err))
+		}
+	}
+	return withErrorKind(ErrStreamTimeout, fmt.Errorf("%w: %v", ErrStreamConnectTimeout, timeout))
+}
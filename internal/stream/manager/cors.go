@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"net/http"
+
+	"github.com/gorilla/handlers"
+)
+
+// OptSetCORS enables Cross-Origin Resource Sharing headers on the stream
+// manager endpoints for a list of allowed origins, where an origin of `*`
+// allows requests from any origin. Preflight OPTIONS requests are answered
+// directly, allowing the request headers that the API accepts, and further
+// headers can be allowed with OptSetCORSAllowedHeaders. Credentials are never
+// advertised as allowed, and an empty list leaves CORS disabled, which is the
+// default.
+func OptSetCORS(allowedOrigins []string) func(*Type) {
+	return func(t *Type) {
+		t.corsOrigins = allowedOrigins
+	}
+}
+
+// corsAllowedHeaders are the request headers that the stream manager API
+// accepts, which are allowed in cross-origin requests by default.
+var corsAllowedHeaders = []string{
+	"Accept",
+	"Content-Encoding",
+	"Content-Type",
+	compatHeader,
+	idempotencyKeyHeader,
+	"If-Modified-Since",
+	"Last-Event-ID",
+}
+
+// corsExposedHeaders are the response headers of the stream manager API that
+// are made readable to cross-origin requests.
+var corsExposedHeaders = []string{
+	idempotentReplayedHeader,
+	"Last-Modified",
+	"Location",
+	"Retry-After",
+}
+
+// OptSetCORSAllowedHeaders adds request headers, beyond those that the stream
+// manager API accepts itself, that are allowed in cross-origin requests when
+// CORS is enabled with OptSetCORS, such as the Authorization or X-Api-Key
+// headers checked by authentication middleware added with
+// OptSetAPIMiddleware.
+func OptSetCORSAllowedHeaders(headers []string) func(*Type) {
+	return func(t *Type) {
+		t.corsHeaders = headers
+	}
+}
+
+func (m *Type) wrapCORS(h http.Handler) http.Handler {
+	if len(m.corsOrigins) == 0 {
+		return h
+	}
+	allowedHeaders := append(append([]string{}, corsAllowedHeaders...), m.corsHeaders...)
+	return handlers.CORS(
+		handlers.AllowedOrigins(m.corsOrigins),
+		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		handlers.AllowedHeaders(allowedHeaders),
+		handlers.ExposedHeaders(corsExposedHeaders),
+	)(h)
+}
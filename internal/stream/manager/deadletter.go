@@ -0,0 +1,472 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+var (
+	errOutputDiverted          = errors.New("stream output has already been diverted")
+	errDeadLetterRequired      = errors.New("a dead-letter output config must be provided as the request body")
+	errOutputDiversionDisabled = errors.New("output diversion is not enabled for this stream manager")
+)
+
+// OptSetOutputDiversion sets whether the outputs of streams are run behind a
+// layer that is able to divert their messages elsewhere, which is required in
+// order for DeleteWithDeadLetter to redirect messages to a dead-letter output
+// and for SwapOutput to replace an output without restarting the stream. This
+// is disabled by default as every message delivered by a stream is tracked
+// until acknowledged whilst enabled.
+func OptSetOutputDiversion(b bool) func(*Type) {
+	return func(t *Type) {
+		t.outputDiversion = b
+	}
+}
+
+type divertedTran struct {
+	tran     message.Transaction
+	target   output.Streamed
+	resolved bool
+}
+
+// divertTarget is an output that transactions are forwarded to along with the
+// channel that it consumes from.
+type divertTarget struct {
+	out     output.Streamed
+	ch      chan message.Transaction
+	sends   sync.WaitGroup
+	retired chan struct{}
+}
+
+func newDivertTarget(out output.Streamed) *divertTarget {
+	return &divertTarget{
+		out:     out,
+		ch:      make(chan message.Transaction),
+		retired: make(chan struct{}),
+	}
+}
+
+// divertOutput sits in front of the output layer of a stream and forwards
+// transactions to it until diverted, at which point transactions are instead
+// delivered to a dead-letter output. Transactions that are pending with the
+// original output at the point of diversion are delivered to the dead-letter
+// output as well, and each transaction is acknowledged upstream only once,
+// according to whichever output delivers it first.
+//
+// Until diverted, the output that transactions are forwarded to can also be
+// replaced with swap, in which case the previous output is drained whilst new
+// transactions are forwarded to its replacement.
+type divertOutput struct {
+	mut        sync.Mutex
+	primary    *divertTarget
+	retiring   map[output.Streamed]struct{}
+	closed     bool
+	pending    map[*divertedTran]struct{}
+	deadLetter output.Streamed
+	dlChan     chan message.Transaction
+	dlSends    sync.WaitGroup
+	divertSig  chan struct{}
+
+	closeNowOnce sync.Once
+	closeNowSig  chan struct{}
+	doneSig      chan struct{}
+}
+
+func newDivertOutput(primary output.Streamed) *divertOutput {
+	return &divertOutput{
+		primary:     newDivertTarget(primary),
+		retiring:    map[output.Streamed]struct{}{},
+		pending:     map[*divertedTran]struct{}{},
+		divertSig:   make(chan struct{}),
+		closeNowSig: make(chan struct{}),
+		doneSig:     make(chan struct{}),
+	}
+}
+
+func (d *divertOutput) getPrimary() output.Streamed {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	return d.primary.out
+}
+
+func (d *divertOutput) Consume(ts <-chan message.Transaction) error {
+	d.mut.Lock()
+	primary := d.primary
+	d.mut.Unlock()
+	if err := primary.out.Consume(primary.ch); err != nil {
+		return err
+	}
+	go d.loop(ts)
+	return nil
+}
+
+func (d *divertOutput) ConnectionStatus() component.ConnectionStatuses {
+	return d.getPrimary().ConnectionStatus()
+}
+
+func (d *divertOutput) ConnectionAddresses() []string {
+	return component.ConnectionAddressesOf(d.getPrimary())
+}
+
+func (d *divertOutput) TriggerCloseNow() {
+	d.closeNowOnce.Do(func() {
+		close(d.closeNowSig)
+	})
+
+	d.mut.Lock()
+	outs := []output.Streamed{d.primary.out}
+	for o := range d.retiring {
+		outs = append(outs, o)
+	}
+	if d.deadLetter != nil {
+		outs = append(outs, d.deadLetter)
+	}
+	d.mut.Unlock()
+
+	for _, o := range outs {
+		o.TriggerCloseNow()
+	}
+}
+
+func (d *divertOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-d.doneSig:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := d.getPrimary().WaitForClose(ctx); err != nil {
+		return err
+	}
+
+	d.mut.Lock()
+	dl := d.deadLetter
+	d.mut.Unlock()
+	if dl != nil {
+		return dl.WaitForClose(ctx)
+	}
+	return nil
+}
+
+func (d *divertOutput) loop(ts <-chan message.Transaction) {
+	defer close(d.doneSig)
+	defer func() {
+		d.mut.Lock()
+		d.closed = true
+		primary := d.primary
+		dlChan := d.dlChan
+		d.mut.Unlock()
+
+		primary.sends.Wait()
+		close(primary.ch)
+		if dlChan != nil {
+			d.dlSends.Wait()
+			close(dlChan)
+		}
+	}()
+
+	for {
+		var t message.Transaction
+		var open bool
+		select {
+		case t, open = <-ts:
+			if !open {
+				return
+			}
+		case <-d.closeNowSig:
+			return
+		}
+		if !d.forward(t) {
+			return
+		}
+	}
+}
+
+// forward delivers a transaction to the dead-letter output when diverted, and
+// otherwise to the original output. Returns false if the output was closed
+// before the transaction could be delivered.
+func (d *divertOutput) forward(t message.Transaction) bool {
+	d.mut.Lock()
+	dlChan := d.dlChan
+	var p *divertedTran
+	if dlChan == nil {
+		p = &divertedTran{tran: t}
+		d.pending[p] = struct{}{}
+	}
+	d.mut.Unlock()
+
+	if dlChan != nil {
+		select {
+		case dlChan <- t:
+			return true
+		case <-d.closeNowSig:
+			return false
+		}
+	}
+	return d.deliver(p)
+}
+
+// deliver sends a pending transaction to the primary output, sending it to the
+// replacement of the primary output instead when it is swapped before the
+// transaction is accepted. Returns false if the output was closed before the
+// transaction could be delivered.
+func (d *divertOutput) deliver(p *divertedTran) bool {
+	for {
+		d.mut.Lock()
+		if d.dlChan != nil {
+			// The transaction is pending and has therefore been delivered to
+			// the dead-letter output by divert.
+			d.mut.Unlock()
+			return true
+		}
+		if d.closed {
+			d.mut.Unlock()
+			return false
+		}
+		primary := d.primary
+		p.target = primary.out
+		primary.sends.Add(1)
+		d.mut.Unlock()
+
+		tran := message.NewTransactionFunc(p.tran.Payload, func(ctx context.Context, err error) error {
+			return d.resolve(ctx, p, err, primary.out)
+		})
+		select {
+		case primary.ch <- *tran.WithContext(p.tran.Context()):
+			primary.sends.Done()
+			return true
+		case <-primary.retired:
+			primary.sends.Done()
+		case <-d.divertSig:
+			// The transaction is pending and has therefore been delivered to
+			// the dead-letter output by divert.
+			primary.sends.Done()
+			return true
+		case <-d.closeNowSig:
+			primary.sends.Done()
+			return false
+		}
+	}
+}
+
+// resolve acknowledges a pending transaction upstream unless it has already
+// been resolved. Errors are ignored from outputs that the transaction is no
+// longer being delivered to, which is the case for primary outputs once
+// diverted and for primary outputs that were swapped out and failed to drain.
+func (d *divertOutput) resolve(ctx context.Context, p *divertedTran, err error, from output.Streamed) error {
+	d.mut.Lock()
+	if p.resolved || (err != nil && from != nil && (d.dlChan != nil || p.target != from)) {
+		d.mut.Unlock()
+		return nil
+	}
+	p.resolved = true
+	delete(d.pending, p)
+	d.mut.Unlock()
+
+	return p.tran.Ack(ctx, err)
+}
+
+// divert begins delivering transactions to a dead-letter output, including
+// those that are pending with the original output, which is then closed.
+func (d *divertOutput) divert(deadLetter output.Streamed) error {
+	d.mut.Lock()
+	if d.dlChan != nil {
+		d.mut.Unlock()
+		return errOutputDiverted
+	}
+
+	dlChan := make(chan message.Transaction)
+	if err := deadLetter.Consume(dlChan); err != nil {
+		d.mut.Unlock()
+		return err
+	}
+	d.deadLetter, d.dlChan = deadLetter, dlChan
+
+	pending := make([]*divertedTran, 0, len(d.pending))
+	for p := range d.pending {
+		pending = append(pending, p)
+	}
+	primary := d.primary.out
+	d.dlSends.Add(1)
+	close(d.divertSig)
+	d.mut.Unlock()
+
+	go func() {
+		defer d.dlSends.Done()
+		for _, p := range pending {
+			tran := message.NewTransactionFunc(p.tran.Payload, func(ctx context.Context, err error) error {
+				return d.resolve(ctx, p, err, nil)
+			})
+			select {
+			case dlChan <- *tran.WithContext(p.tran.Context()):
+			case <-d.closeNowSig:
+				return
+			}
+		}
+	}()
+
+	primary.TriggerCloseNow()
+	return nil
+}
+
+// swap replaces the primary output, such that transactions are forwarded to
+// the replacement from then on, and then drains the previous output until the
+// context ends. If the previous output fails to drain in time it is closed and
+// the transactions pending with it are delivered to the replacement instead.
+// Returns whether the previous output drained gracefully.
+func (d *divertOutput) swap(ctx context.Context, replacement output.Streamed) (drained bool, err error) {
+	d.mut.Lock()
+	if d.dlChan != nil {
+		d.mut.Unlock()
+		return false, errOutputDiverted
+	}
+	if d.closed {
+		d.mut.Unlock()
+		return false, component.ErrTypeClosed
+	}
+
+	next := newDivertTarget(replacement)
+	if err := replacement.Consume(next.ch); err != nil {
+		d.mut.Unlock()
+		return false, err
+	}
+	prev := d.primary
+	d.primary = next
+	d.retiring[prev.out] = struct{}{}
+	close(prev.retired)
+	d.mut.Unlock()
+
+	defer func() {
+		d.mut.Lock()
+		delete(d.retiring, prev.out)
+		d.mut.Unlock()
+	}()
+
+	// Transactions that were in the process of being sent to the previous
+	// output are either accepted by it or sent to the replacement instead.
+	prev.sends.Wait()
+	close(prev.ch)
+
+	if err := prev.out.WaitForClose(ctx); err == nil {
+		return true, nil
+	}
+
+	// Pending transactions are retargeted before the previous output is
+	// closed, as otherwise the errors that it rejects them with would be
+	// propagated upstream.
+	d.mut.Lock()
+	var pending []*divertedTran
+	for p := range d.pending {
+		if p.target == prev.out {
+			p.target = next.out
+			pending = append(pending, p)
+		}
+	}
+	d.mut.Unlock()
+
+	prev.out.TriggerCloseNow()
+	for _, p := range pending {
+		go d.deliver(p)
+	}
+	return false, nil
+}
+
+func (s *StreamStatus) setDivert(d *divertOutput) {
+	s.mut.Lock()
+	s.divert = d
+	s.mut.Unlock()
+}
+
+func (s *StreamStatus) getDivert() *divertOutput {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.divert
+}
+
+//------------------------------------------------------------------------------
+
+// DeleteWithDeadLetter attempts to stop and remove a stream by its ID as with
+// Delete, but if the stream has not drained gracefully after half of the
+// context deadline the messages remaining within the stream, including those
+// pending delivery, are redirected to a dead-letter output constructed from
+// the provided config. This prevents data loss when the output of a stream is
+// unable to deliver messages during shut down. When the context has no
+// deadline messages are redirected immediately. Running streams can only be
+// redirected when the manager is configured with OptSetOutputDiversion.
+func (m *Type) DeleteWithDeadLetter(ctx context.Context, id string, conf output.Config) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.getStream() == nil {
+		return m.Delete(ctx, id)
+	}
+
+	divert := wrapper.getDivert()
+	if divert == nil {
+		return errOutputDiversionDisabled
+	}
+
+	deadLetter, err := m.manager.ForStream(id).IntoPath("deadletter").NewOutput(conf)
+	if err != nil {
+		return err
+	}
+
+	var divertAfter time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		divertAfter = time.Until(deadline) / 2
+	}
+
+	deleteErr := make(chan error, 1)
+	go func() {
+		deleteErr <- m.Delete(ctx, id)
+	}()
+
+	timer := time.NewTimer(divertAfter)
+	defer timer.Stop()
+
+	select {
+	case err := <-deleteErr:
+		deadLetter.TriggerCloseNow()
+		return err
+	case <-timer.C:
+	}
+
+	if err := divert.divert(deadLetter); err != nil {
+		deadLetter.TriggerCloseNow()
+		m.manager.Logger().Error("Failed to redirect messages of stream '%v' to dead-letter output: %v\n", id, err)
+	} else {
+		m.manager.Logger().Warn("Redirecting the remaining messages of stream '%v' to dead-letter output as it failed to drain gracefully\n", id)
+	}
+	return <-deleteErr
+}
+
+// deadLetterConfigFromRequest parses the config of a dead-letter output from
+// the body of a request.
+func (m *Type) deadLetterConfigFromRequest(r *http.Request) (conf output.Config, err error) {
+	var confBytes []byte
+	if confBytes, err = io.ReadAll(r.Body); err != nil {
+		return
+	}
+	if len(bytes.TrimSpace(confBytes)) == 0 {
+		err = errDeadLetterRequired
+		return
+	}
+
+	var node *yaml.Node
+	if node, err = docs.UnmarshalYAML(confBytes); err != nil {
+		return
+	}
+	return output.FromAny(m.manager.Environment(), node)
+}
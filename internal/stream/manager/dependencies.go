@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// ErrStreamDependencyCycle is returned when the dependencies declared by a
+// stream would result in streams that depend on each other.
+var ErrStreamDependencyCycle = errors.New("stream dependencies contain a cycle")
+
+// StreamOptDependsOn declares the streams that a stream depends on, replacing
+// the dependencies of a previous version of the stream. Dependencies are
+// started before the streams that depend on them by StartAll, and are stopped
+// after them when the manager is stopped. Dependencies that do not exist are
+// ignored, allowing streams to be registered in any order.
+func StreamOptDependsOn(ids ...string) StreamOpt {
+	return func(s *StreamStatus) {
+		if len(ids) == 0 {
+			s.dependsOn = nil
+			return
+		}
+		s.dependsOn = append([]string(nil), ids...)
+	}
+}
+
+// DependsOn returns the ids of the streams that the stream depends on.
+func (s *StreamStatus) DependsOn() []string {
+	return append([]string(nil), s.dependsOn...)
+}
+
+// checkDependencyCycle returns an error if registering a stream under an id
+// would result in a dependency cycle, which must be called whilst holding the
+// manager lock.
+func (m *Type) checkDependencyCycle(id string, wrapper *StreamStatus) error {
+	dependsOn := func(sid string) []string {
+		if sid == id {
+			return wrapper.dependsOn
+		}
+		if s, exists := m.streams[sid]; exists {
+			return s.dependsOn
+		}
+		return nil
+	}
+
+	var path []string
+	visiting := map[string]bool{}
+	var visit func(sid string) bool
+	visit = func(sid string) bool {
+		if sid == id && len(path) > 0 {
+			path = append(path, sid)
+			return true
+		}
+		if visiting[sid] {
+			return false
+		}
+		visiting[sid] = true
+		path = append(path, sid)
+		for _, dep := range dependsOn(sid) {
+			if visit(dep) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(id) {
+		return fmt.Errorf("%w: %v", ErrStreamDependencyCycle, strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+// checkUpdateDependencies returns an error if updating a stream with the
+// provided options would result in a dependency cycle, allowing the update to
+// be rejected before the existing version of the stream is stopped.
+func (m *Type) checkUpdateDependencies(id string, conf stream.Config, prev *StreamStatus, opts ...StreamOpt) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.checkDependencyCycle(id, newStreamStatus(conf, nil, prev, opts...))
+}
+
+// dependencyLevels groups streams into levels where each stream only depends on
+// streams within earlier levels, ignoring dependencies outside of the provided
+// set. Streams within each level are sorted by id.
+func dependencyLevels(streams map[string]*StreamStatus) (levels [][]string) {
+	placed := make(map[string]bool, len(streams))
+	for len(placed) < len(streams) {
+		var level []string
+		for id, s := range streams {
+			if placed[id] {
+				continue
+			}
+			ready := true
+			for _, dep := range s.dependsOn {
+				if _, exists := streams[dep]; exists && !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, id)
+			}
+		}
+		if len(level) == 0 {
+			// Cycles are rejected when streams are registered, but in case
+			// one slips through the remaining streams share a final level.
+			for id := range streams {
+				if !placed[id] {
+					level = append(level, id)
+				}
+			}
+		}
+		sort.Strings(level)
+		for _, id := range level {
+			placed[id] = true
+		}
+		levels = append(levels, level)
+	}
+	return
+}
+
+// StartAll starts each stream that was created without being started, in an
+// order where every stream is started only once the streams that it depends on
+// are running and connected. Streams that do not depend on each other are
+// started together. Returns an error if a stream fails to start, or if its
+// dependencies fail to connect before the context is cancelled, in which case
+// the streams that depend on it are not started.
+func (m *Type) StartAll(ctx context.Context) error {
+	streams := m.snapshotStreams()
+
+	dependedOn := map[string]bool{}
+	for _, s := range streams {
+		for _, dep := range s.dependsOn {
+			dependedOn[dep] = true
+		}
+	}
+
+	for _, level := range dependencyLevels(streams) {
+		for _, id := range level {
+			if err := m.Start(id); err != nil && !errors.Is(err, ErrStreamStarted) {
+				if errors.Is(err, component.ErrTypeClosed) {
+					return err
+				}
+				return fmt.Errorf("failed to start stream '%v': %w", id, err)
+			}
+		}
+		for _, id := range level {
+			if dependedOn[id] && !waitForDependency(ctx, streams[id]) {
+				return fmt.Errorf("stream '%v' failed to connect before its dependants were started: %w", id, ctx.Err())
+			}
+		}
+	}
+	return nil
+}
+
+// waitForDependency blocks until a stream is connected or has closed,
+// returning false if the context is cancelled first.
+func waitForDependency(ctx context.Context, s *StreamStatus) bool {
+	ticker := time.NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+
+	for s.IsRunning() && !s.IsReady() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+type configFieldValue struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+type configFieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// configDiff describes the fields that differ between two stream configs,
+// where each field is identified by a dotted path and array elements are
+// identified by their index.
+type configDiff struct {
+	Added   []configFieldValue  `json:"added"`
+	Removed []configFieldValue  `json:"removed"`
+	Changed []configFieldChange `json:"changed"`
+}
+
+// canonicalStreamConfigValue returns the generic form of a stream config as it
+// would be served by the API, with its type fields and default values removed
+// in order for configs to be compared without cosmetic differences.
+func (m *Type) canonicalStreamConfigValue(conf stream.Config) (any, error) {
+	served, err := m.servedConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := node.Encode(served); err != nil {
+		return nil, err
+	}
+
+	sanitConf := docs.NewSanitiseConfig(m.manager.Environment())
+	sanitConf.RemoveTypeField = true
+	sanitConf.RemoveDefaults = true
+	if err := stream.Spec().SanitiseYAML(&node, sanitConf); err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := node.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// diffConfigValues compares two generic config structures, appending the
+// fields that differ to the diff. Objects that consist of a single field,
+// which is the form of a component config, are reported as a change of the
+// object as a whole when the field differs, such that a change in the type of
+// a component is reported as one change rather than a removal and an addition.
+func diffConfigValues(diff *configDiff, path string, oldV, newV any) {
+	oldMap, oldIsMap := oldV.(map[string]any)
+	newMap, newIsMap := newV.(map[string]any)
+	if oldIsMap && newIsMap {
+		if len(oldMap) == 1 && len(newMap) == 1 {
+			for k := range oldMap {
+				if _, exists := newMap[k]; !exists {
+					diff.Changed = append(diff.Changed, configFieldChange{Path: path, Old: oldV, New: newV})
+					return
+				}
+			}
+		}
+
+		keys := map[string]struct{}{}
+		for k := range oldMap {
+			keys[k] = struct{}{}
+		}
+		for k := range newMap {
+			keys[k] = struct{}{}
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			fieldPath := joinDiffPath(path, k)
+			o, inOld := oldMap[k]
+			n, inNew := newMap[k]
+			switch {
+			case !inOld:
+				diff.Added = append(diff.Added, configFieldValue{Path: fieldPath, Value: n})
+			case !inNew:
+				diff.Removed = append(diff.Removed, configFieldValue{Path: fieldPath, Value: o})
+			default:
+				diffConfigValues(diff, fieldPath, o, n)
+			}
+		}
+		return
+	}
+
+	oldArr, oldIsArr := oldV.([]any)
+	newArr, newIsArr := newV.([]any)
+	if oldIsArr && newIsArr {
+		for i := 0; i < len(oldArr) || i < len(newArr); i++ {
+			elemPath := joinDiffPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(oldArr):
+				diff.Added = append(diff.Added, configFieldValue{Path: elemPath, Value: newArr[i]})
+			case i >= len(newArr):
+				diff.Removed = append(diff.Removed, configFieldValue{Path: elemPath, Value: oldArr[i]})
+			default:
+				diffConfigValues(diff, elemPath, oldArr[i], newArr[i])
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldV, newV) {
+		diff.Changed = append(diff.Changed, configFieldChange{Path: path, Old: oldV, New: newV})
+	}
+}
+
+func joinDiffPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// diffStreamConfigs returns the fields that differ between the running config
+// of a stream and a proposed config.
+func (m *Type) diffStreamConfigs(running, proposed stream.Config) (configDiff, error) {
+	diff := configDiff{
+		Added:   []configFieldValue{},
+		Removed: []configFieldValue{},
+		Changed: []configFieldChange{},
+	}
+
+	oldV, err := m.canonicalStreamConfigValue(running)
+	if err != nil {
+		return diff, err
+	}
+	newV, err := m.canonicalStreamConfigValue(proposed)
+	if err != nil {
+		return diff, err
+	}
+
+	diffConfigValues(&diff, "", oldV, newV)
+	return diff, nil
+}
+
+// HandleStreamDiff is an http.HandleFunc that accepts a proposed config for a
+// stream and returns the fields that would change were the stream updated with
+// it, without applying the update.
+func (m *Type) HandleStreamDiff(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream diff Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream diff request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	var info *StreamStatus
+	if info, serverErr = m.Read(id); serverErr != nil {
+		return
+	}
+
+	var confBytes []byte
+	if confBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+	if confBytes, requestErr = config.ReplaceEnvVariables(confBytes, os.LookupEnv); requestErr != nil {
+		return
+	}
+
+	var node *yaml.Node
+	if node, requestErr = docs.UnmarshalYAML(confBytes); requestErr != nil {
+		return
+	}
+	var rawSource any
+	_ = node.Decode(&rawSource)
+
+	var proposed stream.Config
+	if proposed, requestErr = m.streamConfigFromAny(rawSource); requestErr != nil {
+		return
+	}
+
+	var diff configDiff
+	if diff, serverErr = m.diffStreamConfigs(info.Config(), proposed); serverErr != nil {
+		return
+	}
+
+	var resBytes []byte
+	if resBytes, serverErr = json.Marshal(diff); serverErr != nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+)
+
+// kindError associates an error with the kind of failure that it represents,
+// matching both with errors.Is whilst retaining the message of the error.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+func withErrorKind(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// stopErr wraps an error returned when stopping a stream, marking those that
+// are the result of the context ending as timeouts.
+func stopErr(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, component.ErrTimeout) {
+		return withErrorKind(ErrStreamTimeout, err)
+	}
+	return err
+}
+
+// streamErrorStatus returns the HTTP status code and message that an error
+// returned by the manager is served with, or false when the error is not of a
+// known kind.
+func streamErrorStatus(err error) (status int, msg string, ok bool) {
+	switch {
+	case err == nil:
+		return 0, "", false
+	case errors.Is(err, ErrStreamDoesNotExist):
+		return http.StatusNotFound, "Stream not found", true
+	case errors.Is(err, ErrStreamExists):
+		return http.StatusConflict, "Stream already exists", true
+	case errors.Is(err, ErrStreamLimitReached):
+		return http.StatusTooManyRequests, "Maximum number of streams reached", true
+	case errors.Is(err, ErrStreamStarted), errors.Is(err, ErrStreamRegistered), errors.Is(err, ErrStreamNotQuarantined):
+		return http.StatusConflict, fmt.Sprintf("Error: %v", err), true
+	case errors.Is(err, ErrStreamDependencyCycle), errors.Is(err, ErrStreamConfigInvalid):
+		return http.StatusBadRequest, fmt.Sprintf("Error: %v", err), true
+	case errors.Is(err, ErrStreamTimeout):
+		return http.StatusGatewayTimeout, fmt.Sprintf("Error: %v", err), true
+	case errors.Is(err, component.ErrTypeClosed):
+		return http.StatusServiceUnavailable, fmt.Sprintf("Error: %v", err), true
+	}
+	return 0, "", false
+}
+
+// writeStreamError writes the response for an error returned by the manager
+// when it is of a known kind, returning false otherwise.
+func writeStreamError(w http.ResponseWriter, err error) bool {
+	status, msg, ok := streamErrorStatus(err)
+	if ok {
+		http.Error(w, msg, status)
+	}
+	return ok
+}
@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	eventFeedHistoryLimit = 1000
+	eventFeedKeepAlive    = 15 * time.Second
+)
+
+type feedEvent struct {
+	seq       uint64
+	eventType string
+	data      []byte
+}
+
+// eventFeed retains a bounded history of lifecycle events in order for
+// subscribers to be able to resume from a given event ID.
+type eventFeed struct {
+	mut     sync.Mutex
+	seq     uint64
+	history []feedEvent
+	notify  chan struct{}
+	closed  bool
+}
+
+func newEventFeed() *eventFeed {
+	return &eventFeed{
+		notify: make(chan struct{}),
+	}
+}
+
+type eventStatusBody struct {
+	Active bool   `json:"active"`
+	State  string `json:"state"`
+	Ready  bool   `json:"ready"`
+}
+
+type eventBody struct {
+	ID     string           `json:"id"`
+	Type   string           `json:"type"`
+	Time   string           `json:"time"`
+	Status *eventStatusBody `json:"status,omitempty"`
+}
+
+func (f *eventFeed) add(e LifecycleEvent) {
+	body := eventBody{
+		ID:   e.ID,
+		Type: e.Type,
+		Time: e.Time.UTC().Format(time.RFC3339Nano),
+	}
+	if e.Status != nil {
+		active := e.Status.IsRunning()
+		body.Status = &eventStatusBody{
+			Active: active,
+			State:  e.Status.State(),
+			Ready:  active && e.Status.IsReady(),
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.closed {
+		return
+	}
+
+	f.seq++
+	f.history = append(f.history, feedEvent{
+		seq:       f.seq,
+		eventType: e.Type,
+		data:      data,
+	})
+	if len(f.history) > eventFeedHistoryLimit {
+		f.history = f.history[len(f.history)-eventFeedHistoryLimit:]
+	}
+
+	close(f.notify)
+	f.notify = make(chan struct{})
+}
+
+// since returns all retained events following the provided event ID, along
+// with a channel that is closed when further events are added. If the event ID
+// is ahead of the feed, which can happen when a client resumes against a
+// restarted service, then all retained events are returned.
+func (f *eventFeed) since(lastID uint64) (events []feedEvent, next <-chan struct{}, closed bool) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if lastID > f.seq {
+		lastID = 0
+	}
+	for i, e := range f.history {
+		if e.seq > lastID {
+			events = f.history[i:]
+			break
+		}
+	}
+	return events, f.notify, f.closed
+}
+
+func (f *eventFeed) close() {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.notify)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// HandleStreamEvents is an http.HandleFunc for subscribing to a feed of
+// Server-Sent Events describing changes to streams. Clients are able to resume
+// a feed by providing the ID of the last event received with the Last-Event-ID
+// header.
+func (m *Type) HandleStreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var lastID uint64
+	if lastIDStr := r.Header.Get("Last-Event-ID"); lastIDStr != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastIDStr, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("Error: failed to parse Last-Event-ID: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventFeedKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		events, next, closed := m.events.since(lastID)
+		for _, e := range events {
+			if _, err := fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", e.seq, e.eventType, e.data); err != nil {
+				return
+			}
+			lastID = e.seq
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+		}
+		if closed {
+			return
+		}
+
+		select {
+		case <-next:
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte(":\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package manager_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream/manager"
+)
+
+// This example demonstrates how to serve the stream manager API over TLS with
+// a certificate and key read from files, which are reloaded when modified.
+func ExampleType_NewAPIServer() {
+	panicOnErr := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "bento")
+	panicOnErr(err)
+	defer os.RemoveAll(dir)
+
+	certPEM, keyPEM, err := selfSignedCert(1)
+	panicOnErr(err)
+
+	certPath, keyPath := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	panicOnErr(os.WriteFile(certPath, certPEM, 0o600))
+	panicOnErr(os.WriteFile(keyPath, keyPEM, 0o600))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	panicOnErr(err)
+
+	mgr := manager.New(res, manager.OptAPIEnabled(false))
+
+	srv, err := mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetTLSCertFiles(certPath, keyPath),
+	)
+	panicOnErr(err)
+
+	go func() {
+		panicOnErr(srv.Serve())
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, ServerName: "localhost"},
+		},
+	}
+
+	res2, err := client.Get("https://" + srv.Addr().String() + "/ready")
+	panicOnErr(err)
+	res2.Body.Close()
+	fmt.Println(res2.StatusCode)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	panicOnErr(srv.Shutdown(ctx))
+	panicOnErr(mgr.Stop(ctx))
+
+	// Output: 200
+}
@@ -0,0 +1,65 @@
+package manager_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream/manager"
+)
+
+// This example demonstrates how to serve the stream manager API over a Unix
+// domain socket.
+func ExampleType_NewAPIServer_unixSocket() {
+	panicOnErr := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "bento")
+	panicOnErr(err)
+	defer os.RemoveAll(dir)
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	panicOnErr(err)
+
+	// The API of the stream manager is served over the socket only, and is
+	// therefore not registered with the service wide HTTP server.
+	mgr := manager.New(res, manager.OptAPIEnabled(false))
+
+	sockPath := filepath.Join(dir, "bento.sock")
+	srv, err := mgr.NewAPIServer(manager.ServerOptSetUnixSocket(sockPath, 0o600))
+	panicOnErr(err)
+
+	go func() {
+		panicOnErr(srv.Serve())
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", sockPath)
+			},
+		},
+	}
+
+	res2, err := client.Get("http://unix/ready")
+	panicOnErr(err)
+	res2.Body.Close()
+	fmt.Println(res2.StatusCode)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	panicOnErr(srv.Shutdown(ctx))
+	panicOnErr(mgr.Stop(ctx))
+
+	// Output: 200
+}
@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"archive/tar"
+	"bytes"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+)
+
+const exportFormatTar = "tar"
+
+// acceptsTar returns whether the Accept header of a request names a tar
+// archive.
+func acceptsTar(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/x-tar" || mediaType == "application/tar" {
+			return true
+		}
+	}
+	return false
+}
+
+// streamsExportTar writes a snapshot of stream configs as a tar archive of
+// YAML files, named such that loading the extracted files as a directory of
+// stream configs results in the same stream ids.
+func streamsExportTar(snapshot map[string]any) ([]byte, error) {
+	ids := make([]string, 0, len(snapshot))
+	for id := range snapshot {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	modTime := time.Now()
+	for _, id := range ids {
+		confBytes, err := yaml.Marshal(snapshot[id])
+		if err != nil {
+			return nil, err
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     config.StreamConfigFileName(id),
+			Mode:     0o644,
+			Size:     int64(len(confBytes)),
+			ModTime:  modTime,
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(confBytes); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
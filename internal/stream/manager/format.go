@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Formats in which stream configs can be submitted and served.
+const (
+	configFormatJSON = "json"
+	configFormatYAML = "yaml"
+)
+
+var yamlMediaTypes = map[string]struct{}{
+	"application/x-yaml": {},
+	"application/yaml":   {},
+	"text/yaml":          {},
+	"text/x-yaml":        {},
+}
+
+// requestConfigFormat determines the format of a submitted config from the
+// Content-Type header of the request, falling back to inspecting the config
+// itself when the header is absent or ambiguous.
+func requestConfigFormat(r *http.Request, confBytes []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		if mediaType == "application/json" {
+			return configFormatJSON
+		}
+		if _, isYAML := yamlMediaTypes[mediaType]; isYAML {
+			return configFormatYAML
+		}
+	}
+	if trimmed := bytes.TrimSpace(confBytes); len(trimmed) > 0 && trimmed[0] == '{' {
+		return configFormatJSON
+	}
+	return configFormatYAML
+}
+
+// responseConfigFormat determines the format in which to serve a config from
+// the Accept header of the request, returning the format along with the media
+// type to respond with. When the header is absent, or accepts either format,
+// the provided default format is used.
+func responseConfigFormat(r *http.Request, defaultFormat string) (format, contentType string) {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/json" {
+			return configFormatJSON, mediaType
+		}
+		if _, isYAML := yamlMediaTypes[mediaType]; isYAML {
+			return configFormatYAML, mediaType
+		}
+	}
+	if defaultFormat == configFormatYAML {
+		return configFormatYAML, "application/x-yaml"
+	}
+	return configFormatJSON, "application/json"
+}
+
+// streamOptConfigFormat sets the format in which the config of a stream was
+// submitted, which is the format it is served in by default.
+func streamOptConfigFormat(format string) StreamOpt {
+	return func(s *StreamStatus) {
+		s.configFormat = format
+	}
+}
+
+// getConfigFormat returns the format in which the config of the stream was
+// submitted, which is JSON unless the config was submitted as YAML.
+func (s *StreamStatus) getConfigFormat() string {
+	if s.configFormat == "" {
+		return configFormatJSON
+	}
+	return s.configFormat
+}
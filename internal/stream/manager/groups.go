@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// StreamGroupLabel is the key of the label that assigns streams to a group,
+// allowing related streams to be paused, resumed or deleted as a unit.
+const StreamGroupLabel = "group"
+
+// Possible outcomes of a group operation for each stream within the group,
+// in addition to failed.
+const (
+	streamGroupPaused  = "paused"
+	streamGroupResumed = "resumed"
+	streamGroupDeleted = "deleted"
+)
+
+// GroupMembers returns the ids of the streams that belong to a group, which
+// are those with the label StreamGroupLabel set to the name of the group, in
+// lexicographical order.
+func (m *Type) GroupMembers(group string) []string {
+	var ids []string
+	for id, info := range m.snapshotStreams() {
+		if info.HasLabels(map[string]string{StreamGroupLabel: group}) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// HandleStreamGroupAction is an http.HandleFunc for performing an operation on
+// all streams of a group with a POST, where the operation is one of pause,
+// resume or delete. The response body maps each stream id to its outcome, with
+// a status of 207 Multi-Status when the operation failed for some streams and
+// succeeded for others.
+func (m *Type) HandleStreamGroupAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	group, action := mux.Vars(r)["group"], mux.Vars(r)["action"]
+
+	var fn func(ctx context.Context, id string) error
+	var outcome string
+	switch action {
+	case "pause":
+		duration, err := pauseDurationFromRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+			return
+		}
+		fn = func(_ context.Context, id string) error {
+			if duration > 0 {
+				return m.PauseFor(id, duration)
+			}
+			return m.Pause(id)
+		}
+		outcome = streamGroupPaused
+	case "resume":
+		fn = func(_ context.Context, id string) error { return m.Resume(id) }
+		outcome = streamGroupResumed
+	case "delete":
+		fn = m.Delete
+		outcome = streamGroupDeleted
+	default:
+		http.Error(w, fmt.Sprintf("Error: group operation '%v' not recognised, expected one of: pause, resume, delete", action), http.StatusBadRequest)
+		return
+	}
+
+	ids := m.GroupMembers(group)
+	if len(ids) == 0 {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	m.writeStreamsAction(w, r, ids, outcome, func(ctx context.Context, id string) error {
+		err := fn(ctx, id)
+		if err != nil {
+			m.manager.Logger().Error("Failed to %v stream '%v' of group '%v': %v\n", action, id, group, err)
+		}
+		return err
+	})
+}
+
+// writeStreamsAction applies an operation to each of the provided streams
+// concurrently and writes a response body mapping each stream id to its
+// outcome, with a status of 207 Multi-Status when the operation failed for
+// some streams and succeeded for others.
+func (m *Type) writeStreamsAction(w http.ResponseWriter, r *http.Request, ids []string, outcome string, fn func(ctx context.Context, id string) error) {
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+
+	var (
+		outcomesMut sync.Mutex
+		wg          sync.WaitGroup
+	)
+	outcomes := make(map[string]streamSetOutcome, len(ids))
+	var failed int
+
+	wg.Add(len(ids))
+	for _, id := range ids {
+		go func(sid string) {
+			defer wg.Done()
+			err := fn(ctx, sid)
+
+			outcomesMut.Lock()
+			defer outcomesMut.Unlock()
+			if err != nil {
+				outcomes[sid] = streamSetOutcome{Outcome: streamSetFailed, Error: err.Error()}
+				failed++
+				return
+			}
+			outcomes[sid] = streamSetOutcome{Outcome: outcome}
+		}(id)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if failed > 0 && failed == len(ids) {
+		status = http.StatusBadGateway
+	} else if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	resBytes, err := json.Marshal(outcomes)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(resBytes)
+}
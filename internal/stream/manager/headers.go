@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// OptSetResponseHeaders sets a func that provides headers to add to the
+// responses of the endpoints of individual streams, such as GET /streams/{id},
+// allowing policies such as caching to be set per stream. The func is called
+// for each request with the id of the stream and may return nil. Headers that
+// are set by an endpoint itself, such as Content-Type, take precedence over
+// those provided.
+func OptSetResponseHeaders(fn func(id string) http.Header) func(*Type) {
+	return func(t *Type) {
+		t.responseHeaders = fn
+	}
+}
+
+func (m *Type) wrapResponseHeaders(path string, h http.Handler) http.Handler {
+	if m.responseHeaders == nil || !strings.HasPrefix(path, "/streams/{id}") {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := mux.Vars(r)["id"]; id != "" {
+			// Headers are added before the endpoint is served such that those
+			// set by the endpoint take precedence.
+			for k, v := range m.responseHeaders(id) {
+				w.Header()[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	defaultIdempotencyWindow = 10 * time.Minute
+)
+
+// OptSetIdempotencyWindow sets the period of time for which the responses to
+// successful stream creations made with an Idempotency-Key header are retained.
+// A repeated request with the same key and body within this window receives
+// the original response rather than being applied again, making creations safe
+// to retry, and carries the header Idempotent-Replayed: true. The default is
+// ten minutes, and a value of zero disables support for idempotency keys.
+func OptSetIdempotencyWindow(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.idempotencyWindow = d
+	}
+}
+
+type idempotentResponse struct {
+	requestHash [sha256.Size]byte
+	expiresAt   time.Time
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+// idempotencyCache retains the responses to requests made with idempotency
+// keys, where expired responses are removed as new responses are added. Keys
+// of requests that are still being handled are tracked as in flight, such that
+// concurrent requests with the same key are not applied more than once.
+type idempotencyCache struct {
+	mut       sync.Mutex
+	responses map[string]idempotentResponse
+	inFlight  map[string]struct{}
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{
+		responses: map[string]idempotentResponse{},
+		inFlight:  map[string]struct{}{},
+	}
+}
+
+// begin returns the response recorded for a key when there is one. Otherwise,
+// unless a request with the key is already in flight, the key is marked as in
+// flight and must be released with finish once the request has been handled.
+func (c *idempotencyCache) begin(key string, now time.Time) (res idempotentResponse, exists, inFlight bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if res, exists := c.responses[key]; exists && now.Before(res.expiresAt) {
+		return res, true, false
+	}
+	if _, inFlight := c.inFlight[key]; inFlight {
+		return idempotentResponse{}, false, true
+	}
+	c.inFlight[key] = struct{}{}
+	return idempotentResponse{}, false, false
+}
+
+// finish releases a key marked as in flight by begin, recording the response
+// to its request when it is non-nil.
+func (c *idempotencyCache) finish(key string, res *idempotentResponse, now time.Time) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	delete(c.inFlight, key)
+	if res == nil {
+		return
+	}
+	for k, v := range c.responses {
+		if !now.Before(v.expiresAt) {
+			delete(c.responses, k)
+		}
+	}
+	c.responses[key] = *res
+}
+
+// responseCapture writes a response through whilst retaining a copy of it.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// serveIdempotent serves a request that was made with an idempotency key. When
+// a successful response to a request with the same key was recorded within the
+// idempotency window it is replayed, provided the request is identical,
+// otherwise the request is handled and a successful response is recorded.
+// Requests made whilst another with the same key is being handled are rejected
+// with 409 Conflict, and may be retried in order to receive the response once
+// it has been recorded.
+func (m *Type) serveIdempotent(w http.ResponseWriter, r *http.Request, h http.HandlerFunc) {
+	key := r.Header.Get(idempotencyKeyHeader)
+
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(reqBytes))
+
+	hasher := sha256.New()
+	_, _ = io.WriteString(hasher, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = hasher.Write(reqBytes)
+	var reqHash [sha256.Size]byte
+	copy(reqHash[:], hasher.Sum(nil))
+
+	res, exists, inFlight := m.idempotency.begin(key, time.Now())
+	if inFlight {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "A request with this idempotency key is already in progress", http.StatusConflict)
+		return
+	}
+	if exists {
+		if res.requestHash != reqHash {
+			http.Error(w, "Idempotency key has already been used for a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		for k, v := range res.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(res.status)
+		_, _ = w.Write(res.body)
+		return
+	}
+
+	var recorded *idempotentResponse
+	defer func() {
+		m.idempotency.finish(key, recorded, time.Now())
+	}()
+
+	capture := &responseCapture{ResponseWriter: w}
+	h(capture, r)
+
+	if capture.status == 0 {
+		capture.status = http.StatusOK
+	}
+	if capture.status < 200 || capture.status > 299 {
+		return
+	}
+	recorded = &idempotentResponse{
+		requestHash: reqHash,
+		expiresAt:   time.Now().Add(m.idempotencyWindow),
+		status:      capture.status,
+		header:      w.Header().Clone(),
+		body:        capture.body.Bytes(),
+	}
+}
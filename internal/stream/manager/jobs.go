@@ -0,0 +1,212 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Operations and states of asynchronous stream jobs.
+const (
+	jobOperationCreate = "create"
+	jobOperationUpdate = "update"
+
+	jobStatusPending   = "pending"
+	jobStatusSucceeded = "succeeded"
+	jobStatusFailed    = "failed"
+)
+
+const defaultJobRetention = time.Minute * 10
+
+type streamJob struct {
+	id          string
+	streamID    string
+	operation   string
+	status      string
+	err         error
+	createdAt   time.Time
+	completedAt time.Time
+}
+
+type streamJobBody struct {
+	ID          string `json:"id"`
+	StreamID    string `json:"stream_id"`
+	Operation   string `json:"operation"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+func (j *streamJob) body() streamJobBody {
+	b := streamJobBody{
+		ID:        j.id,
+		StreamID:  j.streamID,
+		Operation: j.operation,
+		Status:    j.status,
+		CreatedAt: j.createdAt.UTC().Format(time.RFC3339Nano),
+	}
+	if j.err != nil {
+		b.Error = j.err.Error()
+	}
+	if !j.completedAt.IsZero() {
+		b.CompletedAt = j.completedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return b
+}
+
+// jobTracker retains the outcome of asynchronous stream operations until they
+// expire, which happens once the retention period has passed since they
+// completed. Expired jobs are pruned lazily.
+type jobTracker struct {
+	mut       sync.Mutex
+	retention time.Duration
+	jobs      map[string]*streamJob
+}
+
+func newJobTracker() *jobTracker {
+	return &jobTracker{
+		retention: defaultJobRetention,
+		jobs:      map[string]*streamJob{},
+	}
+}
+
+func (t *jobTracker) pruneLocked(now time.Time) {
+	for id, j := range t.jobs {
+		if !j.completedAt.IsZero() && now.Sub(j.completedAt) >= t.retention {
+			delete(t.jobs, id)
+		}
+	}
+}
+
+func (t *jobTracker) add(streamID, operation string) (streamJob, error) {
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return streamJob{}, err
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	now := time.Now()
+	t.pruneLocked(now)
+
+	j := &streamJob{
+		id:        u4.String(),
+		streamID:  streamID,
+		operation: operation,
+		status:    jobStatusPending,
+		createdAt: now,
+	}
+	t.jobs[j.id] = j
+	return *j, nil
+}
+
+func (t *jobTracker) complete(id string, err error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	j, exists := t.jobs[id]
+	if !exists {
+		return
+	}
+	j.completedAt = time.Now()
+	if err != nil {
+		j.status = jobStatusFailed
+		j.err = err
+	} else {
+		j.status = jobStatusSucceeded
+	}
+}
+
+func (t *jobTracker) get(id string) (streamJob, bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.pruneLocked(time.Now())
+	j, exists := t.jobs[id]
+	if !exists {
+		return streamJob{}, false
+	}
+	return *j, true
+}
+
+// OptSetJobRetention sets the period of time for which the outcome of an
+// asynchronous create or update operation remains available from the API after
+// it completes. The default is ten minutes.
+func OptSetJobRetention(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.jobs.retention = d
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// runJob executes an operation on a stream in the background and responds to
+// the request with 202 Accepted and the id of a job that can be polled for the
+// outcome. The operation is bounded by the API timeout, but not by the lifetime
+// of the request, and is cancelled when the manager is stopped.
+func (m *Type) runJob(w http.ResponseWriter, streamID, operation string, fn func(ctx context.Context) error) error {
+	job, err := m.jobs.add(streamID, operation)
+	if err != nil {
+		return err
+	}
+
+	bodyBytes, err := json.Marshal(job.body())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, done := context.WithTimeout(context.Background(), m.APITimeout())
+		defer done()
+		go func() {
+			select {
+			case <-m.shutSig:
+				done()
+			case <-ctx.Done():
+			}
+		}()
+
+		err := fn(ctx)
+		if err != nil {
+			m.manager.Logger().Error("Failed to %v stream '%v' for job '%v': %v\n", operation, streamID, job.id, err)
+		}
+		m.jobs.complete(job.id, err)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/streams/jobs/"+job.id)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(bodyBytes)
+	return nil
+}
+
+// HandleStreamJob is an http.HandleFunc for obtaining the status of an
+// asynchronous create or update operation on a stream.
+func (m *Type) HandleStreamJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	job, exists := m.jobs.get(mux.Vars(r)["jobid"])
+	if !exists {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	bodyBytes, err := json.Marshal(job.body())
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bodyBytes)
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"time"
+)
+
+// Types of lifecycle events emitted by a stream manager.
+const (
+	LifecycleEventCreated = "created"
+	LifecycleEventUpdated = "updated"
+	LifecycleEventDeleted = "deleted"
+	LifecycleEventHealth  = "health"
+)
+
+// LifecycleEvent describes a change to a stream managed by a stream manager.
+type LifecycleEvent struct {
+	// ID is the identifier of the stream that changed.
+	ID string
+
+	// Type is the type of change, one of LifecycleEventCreated,
+	// LifecycleEventUpdated, LifecycleEventDeleted or LifecycleEventHealth.
+	Type string
+
+	// Time is the time at which the change was observed.
+	Time time.Time
+
+	// Status is the status of the stream after the change, which is nil for
+	// deletions.
+	Status *StreamStatus
+}
+
+// LifecycleHook is a closure that is called with each lifecycle event emitted
+// by a stream manager. Hooks are called synchronously and therefore must not
+// block, nor attempt to call back into the stream manager.
+type LifecycleHook func(e LifecycleEvent)
+
+// OptAddLifecycleHook adds a closure to be called whenever a stream is created,
+// updated, deleted, or changes health state. Health state changes include a
+// stream being started, shutting down, or its inputs and outputs becoming
+// connected or disconnected.
+func OptAddLifecycleHook(hook LifecycleHook) func(*Type) {
+	return func(t *Type) {
+		t.hooks = append(t.hooks, hook)
+	}
+}
+
+func (m *Type) emitEvent(id, eventType string, status *StreamStatus) {
+	e := LifecycleEvent{
+		ID:     id,
+		Type:   eventType,
+		Time:   time.Now(),
+		Status: status,
+	}
+	for _, hook := range m.hooks {
+		hook(e)
+	}
+}
+
+// startHealthLoop runs the health loop unless it is already running. The loop
+// is started by New when the API is enabled, as the throughput and health of
+// streams are reported by it, or when automatic restarts, the unhealthy grace
+// period or lifecycle hooks are configured. Otherwise it is started once a
+// stream with a stall timeout or max uptime is started, or a stream fails to
+// be constructed and must be retried.
+func (m *Type) startHealthLoop() {
+	if m.healthLoopStarted.CompareAndSwap(false, true) {
+		go m.healthLoop()
+	}
+}
+
+// healthLoop periodically checks the readiness of each stream and emits a
+// health event whenever its health changes, where streams reconnecting within
+// the unhealthy grace period remain healthy. Streams that remain unready when
+// automatic restarts are enabled, that have exceeded their max uptime or that
+// have stalled are restarted, and the throughput of each stream is sampled,
+// until the manager is stopped.
+func (m *Type) healthLoop() {
+	ticker := time.NewTicker(m.healthCheckInterval)
+	defer ticker.Stop()
+
+	lastHealthy := map[*StreamStatus]bool{}
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.shutSig:
+			return
+		}
+
+		now := time.Now()
+		streams := m.snapshotStreams()
+		nextHealthy := make(map[*StreamStatus]bool, len(streams))
+		for id, status := range streams {
+			ready := status.IsRunning() && status.IsReady()
+			healthy := status.observeHealth(now, ready, m.unhealthyGrace)
+			if prev, exists := lastHealthy[status]; exists && prev != healthy {
+				m.emitEvent(id, LifecycleEventHealth, status)
+			}
+			nextHealthy[status] = healthy
+			status.sampleThroughput(now, m.throughputWindow)
+			m.checkStall(id, status, now)
+			if m.restartTimeout > 0 {
+				m.checkAutoRestart(id, status, ready)
+			}
+			m.checkScheduledRestart(id, status, now)
+			m.checkQuarantine(id, status)
+		}
+		lastHealthy = nextHealthy
+	}
+}
@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	readAPIMethods  = []string{"GET", "HEAD", "OPTIONS"}
+	writeAPIMethods = []string{"POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+)
+
+func (m *Type) routerForMethods(methods []string) *mux.Router {
+	router := mux.NewRouter()
+	m.registerEndpointsTo(func(path, desc string, h http.HandlerFunc) {
+		route := router.HandleFunc(path, h)
+		if len(methods) > 0 {
+			route.Methods(methods...)
+		}
+	}, true)
+	return router
+}
+
+// ReadRouter returns a router serving only the GET and HEAD requests of the
+// stream manager endpoints, where requests of any other method are rejected
+// with a 405 Method Not Allowed. This allows status reads to be served
+// separately from mutations.
+func (m *Type) ReadRouter() *mux.Router {
+	return m.routerForMethods(readAPIMethods)
+}
+
+// WriteRouter returns a router serving only the POST, PUT, PATCH and DELETE
+// requests of the stream manager endpoints, where requests of any other method
+// are rejected with a 405 Method Not Allowed. This allows mutations to be
+// served on a listener that is not exposed as broadly as status reads.
+func (m *Type) WriteRouter() *mux.Router {
+	return m.routerForMethods(writeAPIMethods)
+}
+
+// ServerOptReadOnly limits the server to the endpoints of ReadRouter, such that
+// status reads can be served on a separate listener from mutations.
+func ServerOptReadOnly() ServerOpt {
+	return func(c *httpServerConfig) {
+		c.methods = readAPIMethods
+	}
+}
+
+// ServerOptWriteOnly limits the server to the endpoints of WriteRouter, such
+// that mutations can be served on a listener that is not exposed as broadly as
+// status reads.
+func ServerOptWriteOnly() ServerOpt {
+	return func(c *httpServerConfig) {
+		c.methods = writeAPIMethods
+	}
+}
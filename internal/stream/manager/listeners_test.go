@@ -0,0 +1,96 @@
+package manager_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream/manager"
+)
+
+func TestReadWriteAPIServers(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	readSrv, err := mgr.NewAPIServer(manager.ServerOptSetAddress("127.0.0.1:0"), manager.ServerOptReadOnly())
+	require.NoError(t, err)
+
+	writeSrv, err := mgr.NewAPIServer(manager.ServerOptSetAddress("127.0.0.1:0"), manager.ServerOptWriteOnly())
+	require.NoError(t, err)
+
+	serveErrs := make(chan error, 2)
+	go func() {
+		serveErrs <- readSrv.Serve()
+	}()
+	go func() {
+		serveErrs <- writeSrv.Serve()
+	}()
+
+	readURL := "http://" + readSrv.Addr().String()
+	writeURL := "http://" + writeSrv.Addr().String()
+
+	conf := `
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  drop: {}
+`
+
+	// Mutations are rejected by the read listener.
+	res2, err := http.Post(readURL+"/streams/foo", "application/yaml", strings.NewReader(conf))
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res2.StatusCode)
+
+	res2, err = http.Post(writeURL+"/streams/foo", "application/yaml", strings.NewReader(conf))
+	require.NoError(t, err)
+	res2.Body.Close()
+	require.Equal(t, http.StatusOK, res2.StatusCode)
+
+	// Reads are rejected by the write listener.
+	res2, err = http.Get(writeURL + "/streams")
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res2.StatusCode)
+
+	res2, err = http.Get(readURL + "/streams")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res2.Body)
+	res2.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res2.StatusCode)
+	assert.Contains(t, string(body), `"foo"`)
+
+	req, err := http.NewRequest("DELETE", readURL+"/streams/foo", http.NoBody)
+	require.NoError(t, err)
+	res2, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res2.StatusCode)
+
+	req, err = http.NewRequest("DELETE", writeURL+"/streams/foo", http.NoBody)
+	require.NoError(t, err)
+	res2, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, readSrv.Shutdown(ctx))
+	require.NoError(t, writeSrv.Shutdown(ctx))
+	require.NoError(t, <-serveErrs)
+	require.NoError(t, <-serveErrs)
+	require.NoError(t, mgr.Stop(ctx))
+}
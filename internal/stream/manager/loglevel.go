@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+// streamLogLevel is an override of the level at which a stream emits log
+// events, which is applied to the logger of the stream whilst it is running
+// and is shared by each version of a stream. The override can be given a TTL
+// after which the stream reverts to the level of the manager logger.
+type streamLogLevel struct {
+	override *log.LevelOverride
+
+	mut     sync.Mutex
+	gen     uint64
+	timer   *time.Timer
+	expires time.Time
+}
+
+func newStreamLogLevel() *streamLogLevel {
+	return &streamLogLevel{override: log.NewLevelOverride()}
+}
+
+func (l *streamLogLevel) set(level string, ttl time.Duration) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if err := l.override.Set(level); err != nil {
+		return err
+	}
+
+	l.gen++
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.expires = time.Time{}
+	if ttl > 0 {
+		gen := l.gen
+		l.expires = time.Now().Add(ttl)
+		l.timer = time.AfterFunc(ttl, func() {
+			l.mut.Lock()
+			defer l.mut.Unlock()
+			if l.gen == gen {
+				l.clearLocked()
+			}
+		})
+	}
+	return nil
+}
+
+func (l *streamLogLevel) clear() {
+	l.mut.Lock()
+	l.clearLocked()
+	l.mut.Unlock()
+}
+
+func (l *streamLogLevel) clearLocked() {
+	l.gen++
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.expires = time.Time{}
+	l.override.Clear()
+}
+
+func (l *streamLogLevel) get() (level string, expires time.Time) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	level, _ = l.override.Get()
+	return level, l.expires
+}
+
+// streamLogLevelOverrider is implemented by managers capable of overriding the
+// level at which a stream emits log events.
+type streamLogLevelOverrider interface {
+	WithLogLevelOverride(o *log.LevelOverride) bundle.NewManagement
+}
+
+// LogLevel returns the level at which the stream emits log events when it has
+// been overridden, along with the time at which the override expires, which is
+// zero when the override has no TTL. An empty level means the stream logs at
+// the level of the manager.
+func (s *StreamStatus) LogLevel() (level string, expires time.Time) {
+	return s.logLevel.get()
+}
+
+// SetLogLevel overrides the level at which a stream emits log events, which
+// takes effect immediately without restarting the stream. When the ttl is
+// greater than zero the override is reverted once it elapses. An empty level
+// removes any existing override.
+func (m *Type) SetLogLevel(id, level string, ttl time.Duration) error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return component.ErrTypeClosed
+	}
+	wrapper, exists := m.streams[id]
+	m.lock.Unlock()
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+
+	if level == "" {
+		wrapper.logLevel.clear()
+		return nil
+	}
+	if _, ok := m.manager.(streamLogLevelOverrider); !ok {
+		return errors.New("overriding stream log levels is not supported by this manager")
+	}
+	return wrapper.logLevel.set(level, ttl)
+}
+
+type logLevelBody struct {
+	Level     string `json:"level"`
+	TTL       string `json:"ttl,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// HandleStreamLogLevel is an http.HandleFunc for reading (GET), setting (POST)
+// and removing (DELETE) an override of the level at which a stream emits log
+// events.
+func (m *Type) HandleStreamLogLevel(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream log level Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream log level request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		var info *StreamStatus
+		if info, serverErr = m.Read(id); serverErr != nil {
+			return
+		}
+		var body logLevelBody
+		var expires time.Time
+		if body.Level, expires = info.LogLevel(); !expires.IsZero() {
+			body.ExpiresAt = expires.UTC().Format(time.RFC3339)
+		}
+		var resBytes []byte
+		if resBytes, serverErr = json.Marshal(body); serverErr != nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+	case "POST":
+		var reqBytes []byte
+		if reqBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		var body logLevelBody
+		if requestErr = json.Unmarshal(reqBytes, &body); requestErr != nil {
+			return
+		}
+		if body.Level == "" {
+			requestErr = errors.New("level must be set")
+			return
+		}
+		var ttl time.Duration
+		if body.TTL != "" {
+			if ttl, requestErr = time.ParseDuration(body.TTL); requestErr != nil {
+				requestErr = fmt.Errorf("failed to parse ttl: %w", requestErr)
+				return
+			}
+			if ttl < 0 {
+				requestErr = errors.New("ttl must not be negative")
+				return
+			}
+		}
+		if err := m.SetLogLevel(id, strings.TrimSpace(body.Level), ttl); err != nil {
+			if errors.Is(err, ErrStreamDoesNotExist) || errors.Is(err, component.ErrTypeClosed) {
+				serverErr = err
+			} else {
+				requestErr = err
+			}
+		}
+	case "DELETE":
+		serverErr = m.SetLogLevel(id, "", 0)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+}
@@ -0,0 +1,248 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+const defaultStreamLogBufferSize = 100
+
+type logLine struct {
+	seq uint64
+
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// logRing retains a bounded number of the most recent log lines emitted by a
+// stream. The ring is shared by each version of a stream, and therefore lines
+// are retained across updates.
+type logRing struct {
+	mut    sync.Mutex
+	size   int
+	seq    uint64
+	lines  []logLine
+	notify chan struct{}
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{
+		size:   size,
+		notify: make(chan struct{}),
+	}
+}
+
+func (r *logRing) add(level string, fields map[string]string, format string, v ...any) {
+	line := logLine{
+		Time:    time.Now(),
+		Level:   level,
+		Message: strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"),
+		Fields:  fields,
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.seq++
+	line.seq = r.seq
+	r.lines = append(r.lines, line)
+	if len(r.lines) > r.size {
+		r.lines = r.lines[len(r.lines)-r.size:]
+	}
+
+	close(r.notify)
+	r.notify = make(chan struct{})
+}
+
+// since returns all retained lines following the provided sequence number,
+// along with a channel that is closed when further lines are added.
+func (r *logRing) since(lastSeq uint64) (lines []logLine, next <-chan struct{}) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for i, l := range r.lines {
+		if l.seq > lastSeq {
+			lines = append(lines, r.lines[i:]...)
+			break
+		}
+	}
+	return lines, r.notify
+}
+
+//------------------------------------------------------------------------------
+
+// ringLogger is a log.Modular that writes all log events at the info level and
+// above into a logRing.
+type ringLogger struct {
+	ring   *logRing
+	fields map[string]string
+}
+
+var _ log.Modular = &ringLogger{}
+
+func (l *ringLogger) WithFields(fields map[string]string) log.Modular {
+	newFields := make(map[string]string, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		newFields[k] = v
+	}
+	for k, v := range fields {
+		newFields[k] = v
+	}
+	return &ringLogger{ring: l.ring, fields: newFields}
+}
+
+func (l *ringLogger) With(keyValues ...any) log.Modular {
+	fields := make(map[string]string, len(keyValues)/2)
+	for i := 0; i < len(keyValues)-1; i += 2 {
+		fields[fmt.Sprint(keyValues[i])] = fmt.Sprint(keyValues[i+1])
+	}
+	return l.WithFields(fields)
+}
+
+func (l *ringLogger) Fatal(format string, v ...any) {
+	l.ring.add("fatal", l.fields, format, v...)
+}
+
+func (l *ringLogger) Error(format string, v ...any) {
+	l.ring.add("error", l.fields, format, v...)
+}
+
+func (l *ringLogger) Warn(format string, v ...any) {
+	l.ring.add("warn", l.fields, format, v...)
+}
+
+func (l *ringLogger) Info(format string, v ...any) {
+	l.ring.add("info", l.fields, format, v...)
+}
+
+func (l *ringLogger) Debug(format string, v ...any) {}
+
+func (l *ringLogger) Trace(format string, v ...any) {}
+
+// streamLoggerAdder is implemented by managers capable of emitting the logs of
+// a stream to an additional logger.
+type streamLoggerAdder interface {
+	WithAddedLogger(l log.Modular) bundle.NewManagement
+}
+
+// OptSetStreamLogBufferSize sets the number of recent log lines at the info
+// level and above that are retained in memory for each stream, which can be
+// read from the /streams/{id}/logs endpoint. The default is 100, and a value of
+// zero disables the retention of logs.
+func OptSetStreamLogBufferSize(n int) func(*Type) {
+	return func(t *Type) {
+		t.logBufferSize = n
+	}
+}
+
+// logLines returns the most recent log lines emitted by the stream, oldest
+// first.
+func (s *StreamStatus) logLines() []logLine {
+	if s.logs == nil {
+		return nil
+	}
+	lines, _ := s.logs.since(0)
+	return lines
+}
+
+//------------------------------------------------------------------------------
+
+// HandleStreamLogs is an http.HandleFunc for reading the most recent log lines
+// emitted by a stream. When the query parameter follow=true is provided the
+// retained lines are followed by any new lines as they are emitted, delivered
+// as Server-Sent Events.
+func (m *Type) HandleStreamLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	info, err := m.Read(id)
+	if writeStreamError(w, err) {
+		return
+	}
+	if err != nil {
+		m.manager.Logger().Error("Stream logs Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	if r.URL.Query().Get("follow") != "true" || info.logs == nil {
+		lines := info.logLines()
+		if lines == nil {
+			lines = []logLine{}
+		}
+		resBytes, err := json.Marshal(lines)
+		if err != nil {
+			m.manager.Logger().Error("Stream logs Error: %v\n", err)
+			http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventFeedKeepAlive)
+	defer keepAlive.Stop()
+
+	var lastSeq uint64
+	for {
+		lines, next := info.logs.since(lastSeq)
+		for _, l := range lines {
+			data, err := json.Marshal(l)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %v\nevent: log\ndata: %s\n\n", l.seq, data); err != nil {
+				return
+			}
+			lastSeq = l.seq
+		}
+		if len(lines) > 0 {
+			flusher.Flush()
+		}
+
+		select {
+		case <-next:
+		case <-keepAlive.C:
+			if _, err := w.Write([]byte(":\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-m.shutSig:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
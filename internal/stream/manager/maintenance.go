@@ -0,0 +1,260 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultMaintenanceQueueSize = 100
+
+// OptSetMaintenanceQueueSize sets the maximum number of stream mutations that
+// are queued whilst the manager is in maintenance mode, beyond which mutation
+// requests are rejected with 503 Service Unavailable. The default is 100.
+func OptSetMaintenanceQueueSize(n int) func(*Type) {
+	return func(t *Type) {
+		t.maintenanceQueueSize = n
+	}
+}
+
+type queuedMutation struct {
+	method   string
+	target   string
+	header   http.Header
+	body     []byte
+	vars     map[string]string
+	queuedAt time.Time
+}
+
+type queuedMutationInfo struct {
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+func (q queuedMutation) info() queuedMutationInfo {
+	return queuedMutationInfo{
+		Method:   q.method,
+		Path:     q.target,
+		QueuedAt: q.queuedAt,
+	}
+}
+
+type appliedMutationInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// maintenanceMode tracks whether stream mutations are being deferred, along
+// with the queue of mutations awaiting application. The queue continues to
+// accept mutations whilst it is being drained in order for them to be applied
+// in the order that they were received.
+type maintenanceMode struct {
+	mut      sync.Mutex
+	enabled  bool
+	since    time.Time
+	draining bool
+	queue    []queuedMutation
+}
+
+// queueMutation queues a stream mutation request when the manager is in
+// maintenance mode, responding with 202 Accepted. Returns false if the request
+// should be applied immediately instead.
+func (m *Type) queueMutation(w http.ResponseWriter, r *http.Request) bool {
+	mm := &m.maintenance
+	mm.mut.Lock()
+	defer mm.mut.Unlock()
+
+	switch r.Method {
+	case "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return false
+	}
+	if !mm.enabled && !mm.draining {
+		return false
+	}
+	if len(mm.queue) >= m.maintenanceQueueSize {
+		http.Error(w, "Maintenance queue is full", http.StatusServiceUnavailable)
+		return true
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+			return true
+		}
+	}
+
+	mm.queue = append(mm.queue, queuedMutation{
+		method:   r.Method,
+		target:   r.URL.RequestURI(),
+		header:   r.Header.Clone(),
+		body:     body,
+		vars:     mux.Vars(r),
+		queuedAt: time.Now(),
+	})
+
+	bodyBytes, err := json.Marshal(struct {
+		Queued int `json:"queued"`
+	}{
+		Queued: len(mm.queue),
+	})
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(bodyBytes)
+	return true
+}
+
+// liftMaintenance disables maintenance mode and applies the queued mutations
+// in the order that they were received, including any that are queued whilst
+// the queue is being drained.
+func (m *Type) liftMaintenance(ctx context.Context) (applied []appliedMutationInfo) {
+	mm := &m.maintenance
+	mm.mut.Lock()
+	if !mm.enabled || mm.draining {
+		mm.mut.Unlock()
+		return nil
+	}
+	mm.enabled = false
+	mm.draining = true
+	mm.mut.Unlock()
+
+	for {
+		mm.mut.Lock()
+		if len(mm.queue) == 0 || mm.enabled {
+			// Remaining mutations are left queued when maintenance is
+			// enabled again whilst draining.
+			mm.draining = false
+			mm.mut.Unlock()
+			return
+		}
+		q := mm.queue[0]
+		mm.queue = mm.queue[1:]
+		mm.mut.Unlock()
+
+		applied = append(applied, m.applyMutation(ctx, q))
+	}
+}
+
+func (m *Type) applyMutation(ctx context.Context, q queuedMutation) appliedMutationInfo {
+	info := appliedMutationInfo{
+		Method: q.method,
+		Path:   q.target,
+	}
+
+	r, err := http.NewRequestWithContext(ctx, q.method, q.target, bytes.NewReader(q.body))
+	if err != nil {
+		info.Status = http.StatusBadRequest
+		info.Error = err.Error()
+		return info
+	}
+	r.Header = q.header
+	r = mux.SetURLVars(r, q.vars)
+
+	capture := &responseCapture{ResponseWriter: discardResponseWriter{header: http.Header{}}}
+	m.serveStreamCRUD(capture, r)
+
+	if info.Status = capture.status; info.Status == 0 {
+		info.Status = http.StatusOK
+	}
+	if info.Status < 200 || info.Status > 299 {
+		info.Error = string(bytes.TrimSpace(capture.body.Bytes()))
+		m.manager.Logger().Error("Failed to apply %v %v queued during maintenance: %v\n", q.method, q.target, info.Error)
+	}
+	return info
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header {
+	return d.header
+}
+
+func (d discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (d discardResponseWriter) WriteHeader(int) {}
+
+// HandleStreamMaintenance is an http.HandleFunc for obtaining the maintenance
+// status of the manager with a GET, or for toggling maintenance mode with a
+// POST of the form {"enabled":true}. Lifting maintenance applies the queued
+// mutations before responding.
+func (m *Type) HandleStreamMaintenance(w http.ResponseWriter, r *http.Request) {
+	var applied []appliedMutationInfo
+
+	switch r.Method {
+	case "GET":
+	case "POST":
+		var req struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Enabled == nil {
+			http.Error(w, "Error: field `enabled` must be set", http.StatusBadRequest)
+			return
+		}
+		if *req.Enabled {
+			m.maintenance.mut.Lock()
+			if !m.maintenance.enabled {
+				m.maintenance.enabled = true
+				m.maintenance.since = time.Now()
+			}
+			m.maintenance.mut.Unlock()
+		} else {
+			applied = m.liftMaintenance(context.Background())
+		}
+	default:
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	m.maintenance.mut.Lock()
+	body := struct {
+		Enabled   bool                  `json:"enabled"`
+		Since     *time.Time            `json:"since,omitempty"`
+		MaxQueued int                   `json:"max_queued"`
+		Queued    []queuedMutationInfo  `json:"queued"`
+		Applied   []appliedMutationInfo `json:"applied,omitempty"`
+	}{
+		Enabled:   m.maintenance.enabled,
+		MaxQueued: m.maintenanceQueueSize,
+		Queued:    []queuedMutationInfo{},
+		Applied:   applied,
+	}
+	if m.maintenance.enabled {
+		since := m.maintenance.since
+		body.Since = &since
+	}
+	for _, q := range m.maintenance.queue {
+		body.Queued = append(body.Queued, q.info())
+	}
+	m.maintenance.mut.Unlock()
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bodyBytes)
+}
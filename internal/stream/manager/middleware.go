@@ -0,0 +1,24 @@
+package manager
+
+import (
+	"net/http"
+)
+
+// OptSetAPIMiddleware adds middleware that wraps each of the stream manager
+// endpoints, allowing concerns such as authentication or tracing to be
+// implemented by the embedding application. Middleware is applied in the order
+// provided, with the first being the outermost, and sees the full path of each
+// request. Middleware runs after CORS preflight requests have been answered and
+// within the access log, so rejected requests are still logged.
+func OptSetAPIMiddleware(middleware ...func(http.Handler) http.Handler) func(*Type) {
+	return func(t *Type) {
+		t.apiMiddleware = append(t.apiMiddleware, middleware...)
+	}
+}
+
+func (m *Type) wrapMiddleware(h http.Handler) http.Handler {
+	for i := len(m.apiMiddleware) - 1; i >= 0; i-- {
+		h = m.apiMiddleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// normalizeStreamConfig reduces a stream config down to a canonical form, where
+// fields are ordered according to the config spec, map keys are sorted, type
+// fields are removed, fields that match their default values are omitted and
+// quoting styles are chosen consistently. The result is functionally identical
+// to the provided config.
+func (m *Type) normalizeStreamConfig(confBytes []byte) ([]byte, error) {
+	node, err := docs.UnmarshalYAML(confBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the config is valid before normalizing it.
+	var rawSource any
+	_ = node.Decode(&rawSource)
+	pConf, err := stream.Spec().ParsedConfigFromAny(node)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stream.FromParsed(m.manager.Environment(), pConf, rawSource); err != nil {
+		return nil, err
+	}
+
+	sanitConf := docs.NewSanitiseConfig(m.manager.Environment())
+	sanitConf.RemoveTypeField = true
+	sanitConf.RemoveDefaults = true
+	sanitConf.SortMapKeys = true
+	if err := stream.Spec().SanitiseYAML(node, sanitConf); err != nil {
+		return nil, err
+	}
+	resetYAMLStyles(node)
+	return docs.MarshalYAML(*node)
+}
+
+// resetYAMLStyles clears the quoting and flow styles of a YAML document in
+// order for them to be chosen by the encoder rather than the author.
+func resetYAMLStyles(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyles(child)
+	}
+}
+
+// HandleStreamNormalize is an http.HandleFunc that accepts a stream config and
+// returns it in a canonical YAML form, suitable for keeping config files
+// consistently formatted. Environment variable interpolations are left intact.
+func (m *Type) HandleStreamNormalize(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream normalize Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream normalize request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	var confBytes []byte
+	if confBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+
+	if r.URL.Query().Get("chilled") != "true" {
+		node, err := docs.UnmarshalYAML(confBytes)
+		if err != nil {
+			requestErr = err
+			return
+		}
+		// Fields that should be omitted are removed by normalizing and are
+		// therefore not considered errors.
+		var lints []string
+		for _, l := range stream.Spec().LintYAML(m.lintCtx(), node) {
+			if l.Type != docs.LintShouldOmit {
+				lints = append(lints, l.Error())
+			}
+		}
+		if len(lints) > 0 {
+			errBytes, _ := json.Marshal(lintErrors{
+				LintErrs: lints,
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(errBytes)
+			return
+		}
+	}
+
+	var resBytes []byte
+	if resBytes, requestErr = m.normalizeStreamConfig(confBytes); requestErr != nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(resBytes)
+}
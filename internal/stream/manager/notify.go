@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// changeNotificationQueueSize is the number of change notifications that may
+// be awaiting delivery to the change notification output, beyond which
+// further notifications are dropped.
+const changeNotificationQueueSize = 1000
+
+// OptSetChangeNotificationOutput sets an output, configured as with the output
+// of a stream, that receives a JSON message describing each stream that is
+// created, updated or deleted, allowing changes to be routed into any sink
+// supported by an output such as Kafka or an HTTP endpoint. Each message has
+// the fields id, type, time and labels of the stream, along with the metadata
+// fields stream_id and event_type. Notifications are delivered in order and
+// asynchronously, such that a slow output does not block changes, and are
+// dropped when the output falls too far behind.
+//
+// The output is constructed when the manager is created, and is closed once
+// the notifications remaining are delivered when the manager is stopped. An
+// output that fails to construct is logged and notifications are disabled.
+func OptSetChangeNotificationOutput(conf output.Config) func(*Type) {
+	return func(t *Type) {
+		t.changeNotificationConf = &conf
+	}
+}
+
+type changeNotification struct {
+	ID     string            `json:"id"`
+	Type   string            `json:"type"`
+	Time   string            `json:"time"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// changeNotifier delivers change notifications to an output.
+type changeNotifier struct {
+	out      output.Streamed
+	log      log.Modular
+	tranChan chan message.Transaction
+
+	mut     sync.Mutex
+	queue   chan changeNotification
+	closed  bool
+	drained chan struct{}
+}
+
+func newChangeNotifier(mgr bundle.NewManagement, conf output.Config) (*changeNotifier, error) {
+	out, err := mgr.IntoPath("change_notifications").NewOutput(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &changeNotifier{
+		out:      out,
+		log:      mgr.Logger(),
+		tranChan: make(chan message.Transaction),
+		queue:    make(chan changeNotification, changeNotificationQueueSize),
+		drained:  make(chan struct{}),
+	}
+	if err := out.Consume(n.tranChan); err != nil {
+		out.TriggerCloseNow()
+		return nil, err
+	}
+	go n.loop()
+	return n, nil
+}
+
+func (n *changeNotifier) add(e LifecycleEvent) {
+	note := changeNotification{
+		ID:   e.ID,
+		Type: e.Type,
+		Time: e.Time.UTC().Format(time.RFC3339Nano),
+	}
+	switch e.Type {
+	case LifecycleEventCreated, LifecycleEventUpdated:
+		note.Labels = e.Status.Labels()
+	case LifecycleEventDeleted:
+	default:
+		return
+	}
+
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.queue <- note:
+	default:
+		n.log.Warn("Dropping change notification of stream '%v' as the change notification output is not keeping up\n", e.ID)
+	}
+}
+
+func (n *changeNotifier) loop() {
+	defer close(n.drained)
+	defer close(n.tranChan)
+
+	resChan := make(chan error, 1)
+	for note := range n.queue {
+		body, err := json.Marshal(note)
+		if err != nil {
+			n.log.Error("Failed to encode change notification of stream '%v': %v\n", note.ID, err)
+			continue
+		}
+		part := message.NewPart(body)
+		part.MetaSetMut("stream_id", note.ID)
+		part.MetaSetMut("event_type", note.Type)
+
+		n.tranChan <- message.NewTransaction(message.Batch{part}, resChan)
+		if err := <-resChan; err != nil {
+			n.log.Error("Failed to deliver change notification of stream '%v': %v\n", note.ID, err)
+		}
+	}
+}
+
+// close stops accepting notifications and waits for those remaining to be
+// delivered before closing the output, which is closed ungracefully once the
+// context ends.
+func (n *changeNotifier) close(ctx context.Context) error {
+	n.mut.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mut.Unlock()
+
+	select {
+	case <-n.drained:
+	case <-ctx.Done():
+		n.out.TriggerCloseNow()
+		return ctx.Err()
+	}
+	if err := n.out.WaitForClose(ctx); err != nil {
+		n.out.TriggerCloseNow()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package manager
+
+// Origins of a stream, describing where the config of the stream was defined.
+const (
+	// StreamOriginAPI is the origin of streams created via the HTTP API or
+	// otherwise created without a recorded origin.
+	StreamOriginAPI = "api"
+
+	// StreamOriginDirectory is the origin of streams loaded from config files,
+	// typically found within a directory of stream configs.
+	StreamOriginDirectory = "directory"
+
+	// StreamOriginRegistered is the origin of streams that were constructed
+	// programmatically and registered with RegisterStream, which therefore
+	// have no config.
+	StreamOriginRegistered = "registered"
+)
+
+// StreamOptOriginDirectory records that a stream was loaded from a config file
+// at the provided path. Streams without a recorded origin are considered to
+// have been created via the API. As with other options the origin of a
+// previous version of the stream is retained unless overridden.
+func StreamOptOriginDirectory(path string) StreamOpt {
+	return func(s *StreamStatus) {
+		s.origin = StreamOriginDirectory
+		s.originPath = path
+	}
+}
+
+// Origin returns where the config of the stream was defined, which is one of
+// StreamOriginAPI, StreamOriginDirectory or StreamOriginRegistered, along with
+// the path of the file it was loaded from in the case of a directory.
+func (s *StreamStatus) Origin() (origin, path string) {
+	if s.origin == "" {
+		return StreamOriginAPI, ""
+	}
+	return s.origin, s.originPath
+}
@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+var errOutputRequired = errors.New("an output config must be provided as the request body")
+
+// withOutputSection returns a copy of the raw config of a stream with its
+// output section replaced.
+func withOutputSection(info *StreamStatus, outputConf any) map[string]any {
+	conf := info.Config()
+	rawConf, _ := value.IClone(conf.GetRawSource()).(map[string]any)
+	if rawConf == nil {
+		rawConf = map[string]any{}
+	}
+	rawConf["output"] = outputConf
+	return rawConf
+}
+
+// SwapOutput replaces the output of a running stream with one constructed from
+// the provided output config, which is the value of the output field of a
+// stream config. Messages are sent to the new output as soon as it has been
+// constructed whilst the previous output is drained, and therefore the input,
+// buffer and pipeline of the stream keep running throughout. When the previous
+// output fails to drain before the context ends it is closed, and any messages
+// that it had not delivered are sent to the new output instead.
+//
+// The config of the stream is updated to reference the new output. Streams
+// that have not been started, or that are run by a manager without
+// OptSetOutputDiversion, are updated with Update instead, and streams
+// registered with RegisterStream result in ErrStreamRegistered.
+func (m *Type) SwapOutput(ctx context.Context, id string, outputConf any) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.origin == StreamOriginRegistered {
+		return ErrStreamRegistered
+	}
+
+	conf, err := m.streamConfigFromAny(withOutputSection(wrapper, outputConf))
+	if err != nil {
+		return withErrorKind(ErrStreamConfigInvalid, err)
+	}
+	if err := m.ValidateConfig(conf); err != nil {
+		return err
+	}
+	if err := m.checkUpdateDependencies(id, conf, wrapper); err != nil {
+		return err
+	}
+
+	divert := wrapper.getDivert()
+	if wrapper.getStream() == nil || divert == nil {
+		return m.Update(ctx, id, conf)
+	}
+
+	out, err := m.streamManager(id, wrapper).IntoPath("output").NewOutput(conf.Output)
+	if err != nil {
+		return withErrorKind(ErrStreamConfigInvalid, err)
+	}
+
+	drained, err := divert.swap(ctx, out)
+	if err != nil {
+		out.TriggerCloseNow()
+		return err
+	}
+	if !drained {
+		m.manager.Logger().Warn("Previous output of stream '%v' failed to drain in time, its remaining messages are being sent to the new output\n", id)
+	}
+
+	m.lock.Lock()
+	current := !m.closed && m.streams[id] == wrapper
+	m.lock.Unlock()
+	if !current {
+		return errors.New("stream was modified during output swap")
+	}
+
+	wrapper.setConfig(conf)
+	m.emitEvent(id, LifecycleEventUpdated, wrapper)
+	return nil
+}
+
+// HandleStreamOutput is an http.HandleFunc for replacing (POST) the output of
+// a running stream without restarting its input, buffer or pipeline. The body
+// of the request is the config of the new output as either YAML or JSON.
+func (m *Type) HandleStreamOutput(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream output Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream output request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	var info *StreamStatus
+	if info, serverErr = m.Read(id); serverErr != nil {
+		return
+	}
+
+	var outputBytes []byte
+	if outputBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+	if len(bytes.TrimSpace(outputBytes)) == 0 {
+		requestErr = errOutputRequired
+		return
+	}
+
+	ignoreLints := r.URL.Query().Get("chilled") == "true"
+	if outputBytes, requestErr = config.ReplaceEnvVariables(outputBytes, os.LookupEnv); requestErr != nil {
+		var errEnvMissing *config.ErrMissingEnvVars
+		if !ignoreLints || !errors.As(requestErr, &errEnvMissing) {
+			return
+		}
+		outputBytes, requestErr = errEnvMissing.BestAttempt, nil
+	}
+
+	var outputConf any
+	if requestErr = yaml.Unmarshal(outputBytes, &outputConf); requestErr != nil {
+		return
+	}
+
+	if !ignoreLints {
+		var confNode yaml.Node
+		if requestErr = confNode.Encode(withOutputSection(info, outputConf)); requestErr != nil {
+			return
+		}
+		if lints := m.lintStreamConfigNode(&confNode); len(lints) > 0 {
+			for _, l := range lints {
+				m.manager.Logger().Info("Stream '%v' config: %v\n", id, l)
+			}
+			errBytes, _ := json.Marshal(lintErrors{
+				LintErrs: lints,
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(errBytes)
+			return
+		}
+	}
+
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+	serverErr = m.SwapOutput(ctx, id, outputConf)
+}
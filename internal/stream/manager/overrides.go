@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// applyConfigOverrides sets fields of a submitted stream config, where each
+// override is of the form path=value and the path is a dot separated path of
+// the field to set. Values are coerced to the type of the field they target,
+// and an override with a value that cannot be represented as that type is
+// rejected.
+func (m *Type) applyConfigOverrides(node *yaml.Node, overrides []string) error {
+	env := m.manager.Environment()
+	spec := stream.Spec()
+	for _, override := range overrides {
+		path, value, found := strings.Cut(override, "=")
+		if !found || path == "" {
+			return fmt.Errorf("invalid set expression '%v': expected path=value syntax", override)
+		}
+		pathSlice := gabs.DotPathToSlice(path)
+
+		field, err := spec.GetDocsForPath(env, pathSlice...)
+		if err != nil {
+			return fmt.Errorf("invalid set expression '%v': %w", override, err)
+		}
+		valNode, err := overrideValueNode(field, value)
+		if err != nil {
+			return fmt.Errorf("invalid set expression '%v': %w", override, err)
+		}
+		if err := spec.SetYAMLPath(env, node, valNode, pathSlice...); err != nil {
+			return fmt.Errorf("failed to set config field override '%v': %w", override, err)
+		}
+	}
+	return nil
+}
+
+// overrideValueNode returns a YAML node of an override value coerced to the
+// type of the targeted field. Values targeting fields that are not scalars of a
+// known type are parsed as YAML.
+func overrideValueNode(field docs.FieldSpec, value string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if field.Kind != docs.KindScalar && field.Kind != "" {
+		return node, nil
+	}
+	switch field.Type {
+	case docs.FieldTypeString:
+		node.Tag = "!!str"
+	case docs.FieldTypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("%v: expected int value, got '%v'", field.Name, value)
+		}
+		node.Tag = "!!int"
+	case docs.FieldTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("%v: expected float value, got '%v'", field.Name, value)
+		}
+		node.Tag = "!!float"
+	case docs.FieldTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%v: expected bool value, got '%v'", field.Name, value)
+		}
+		node.Tag = "!!bool"
+	}
+	return node, nil
+}
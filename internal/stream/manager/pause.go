@@ -0,0 +1,280 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+)
+
+// pause halts consumption until resumed, returning false if already paused.
+// When the duration is greater than zero consumption is resumed automatically
+// once it elapses, at which point onResume is called. Pausing a stream that is
+// already paused replaces any scheduled resume with the new duration, where a
+// duration of zero means the stream remains paused until resumed manually.
+func (t *streamThrottle) pause(duration time.Duration, onResume func()) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	changed := t.resumeSig == nil
+	if changed {
+		t.resumeSig = make(chan struct{})
+	}
+
+	t.stopResumeTimerLocked()
+	if duration > 0 {
+		gen := t.pauseGen
+		t.resumeAt = time.Now().Add(duration)
+		t.resumeTimer = time.AfterFunc(duration, func() {
+			t.mut.Lock()
+			resumed := t.pauseGen == gen && t.resumeLocked()
+			t.mut.Unlock()
+			if resumed && onResume != nil {
+				onResume()
+			}
+		})
+	}
+	return changed
+}
+
+// resume continues consumption, returning false if not paused.
+func (t *streamThrottle) resume() bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return t.resumeLocked()
+}
+
+func (t *streamThrottle) resumeLocked() bool {
+	if t.resumeSig == nil {
+		return false
+	}
+	t.stopResumeTimerLocked()
+	close(t.resumeSig)
+	t.resumeSig = nil
+	return true
+}
+
+func (t *streamThrottle) stopResumeTimerLocked() {
+	t.pauseGen++
+	if t.resumeTimer != nil {
+		t.resumeTimer.Stop()
+		t.resumeTimer = nil
+	}
+	t.resumeAt = time.Time{}
+}
+
+func (t *streamThrottle) getResumeSig() chan struct{} {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return t.resumeSig
+}
+
+func (t *streamThrottle) getResumeAt() time.Time {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return t.resumeAt
+}
+
+// IsPaused returns whether the stream has been paused, in which case it is
+// running but does not consume messages from its input.
+func (s *StreamStatus) IsPaused() bool {
+	return s.throttle.getResumeSig() != nil
+}
+
+// PauseRemaining returns the period of time remaining until a stream that was
+// paused for a duration is resumed automatically, and false if the stream is
+// not paused for a duration.
+func (s *StreamStatus) PauseRemaining() (time.Duration, bool) {
+	resumeAt := s.throttle.getResumeAt()
+	if resumeAt.IsZero() {
+		return 0, false
+	}
+	remaining := time.Until(resumeAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// Pause halts the consumption of messages from the input of a stream without
+// stopping it, such that messages already consumed continue to be processed
+// and delivered. The stream remains paused across updates until resumed.
+// Pausing a stream that is already paused cancels any scheduled resume.
+func (m *Type) Pause(id string) error {
+	return m.setPaused(id, true, 0)
+}
+
+// PauseFor halts the consumption of messages from the input of a stream as
+// with Pause, and then resumes the stream automatically once the duration has
+// elapsed unless it is resumed manually beforehand. Pausing a stream that is
+// already paused replaces any scheduled resume, such that the latest call
+// determines when the stream is resumed.
+func (m *Type) PauseFor(id string, duration time.Duration) error {
+	if duration <= 0 {
+		return errors.New("pause duration must be greater than zero")
+	}
+	return m.setPaused(id, true, duration)
+}
+
+// Resume continues the consumption of messages from the input of a stream that
+// was paused. Resuming a stream that is not paused has no effect.
+func (m *Type) Resume(id string) error {
+	return m.setPaused(id, false, 0)
+}
+
+func (m *Type) setPaused(id string, paused bool, duration time.Duration) error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return component.ErrTypeClosed
+	}
+	wrapper, exists := m.streams[id]
+	m.lock.Unlock()
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+	if wrapper.origin == StreamOriginRegistered {
+		return ErrStreamRegistered
+	}
+
+	var changed bool
+	if paused {
+		changed = wrapper.throttle.pause(duration, func() {
+			m.emitAutoResumed(id, wrapper.throttle)
+		})
+	} else {
+		changed = wrapper.throttle.resume()
+	}
+	if changed || duration > 0 {
+		m.emitEvent(id, LifecycleEventUpdated, wrapper)
+	}
+	return nil
+}
+
+// emitAutoResumed emits an event for a stream that was resumed automatically,
+// provided that the stream still exists with the throttle that was resumed.
+func (m *Type) emitAutoResumed(id string, throttle *streamThrottle) {
+	m.lock.Lock()
+	wrapper, exists := m.streams[id]
+	closed := m.closed
+	m.lock.Unlock()
+	if closed || !exists || wrapper.throttle != throttle {
+		return
+	}
+	m.manager.Logger().Info("Stream '%v' resumed after its pause duration elapsed\n", id)
+	m.emitEvent(id, LifecycleEventUpdated, wrapper)
+}
+
+// pauseDurationFromRequest parses the optional duration query parameter of a
+// pause request, returning zero when it is absent.
+func pauseDurationFromRequest(r *http.Request) (time.Duration, error) {
+	durationStr := r.URL.Query().Get("duration")
+	if durationStr == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration: %w", err)
+	}
+	if duration <= 0 {
+		return 0, errors.New("duration must be greater than zero")
+	}
+	return duration, nil
+}
+
+// HandleStreamPause is an http.HandleFunc for pausing the consumption of
+// messages from the input of a stream with a POST. When the query parameter
+// duration is provided the stream is resumed automatically once it elapses.
+func (m *Type) HandleStreamPause(w http.ResponseWriter, r *http.Request) {
+	duration, err := pauseDurationFromRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+		return
+	}
+	m.handleStreamPaused(w, r, func(id string) error {
+		if duration > 0 {
+			return m.PauseFor(id, duration)
+		}
+		return m.Pause(id)
+	})
+}
+
+// HandleStreamResume is an http.HandleFunc for resuming the consumption of
+// messages from the input of a paused stream with a POST.
+func (m *Type) HandleStreamResume(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamPaused(w, r, m.Resume)
+}
+
+func (m *Type) handleStreamPaused(w http.ResponseWriter, r *http.Request, fn func(id string) error) {
+	if r.Method != "POST" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	err := fn(id)
+	if writeStreamError(w, err) {
+		return
+	}
+	if err != nil {
+		m.manager.Logger().Error("Stream pause Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+	}
+}
+
+// HandleStreamsPause is an http.HandleFunc for pausing the consumption of
+// messages from the inputs of all streams with a POST, where streams that are
+// already paused remain so. When the query parameter duration is provided the
+// streams are resumed automatically once it elapses. The response body maps
+// each stream id to its outcome as with group operations.
+func (m *Type) HandleStreamsPause(w http.ResponseWriter, r *http.Request) {
+	duration, err := pauseDurationFromRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+		return
+	}
+	m.handleStreamsPaused(w, r, streamGroupPaused, func(id string) error {
+		if duration > 0 {
+			return m.PauseFor(id, duration)
+		}
+		return m.Pause(id)
+	})
+}
+
+// HandleStreamsResume is an http.HandleFunc for resuming the consumption of
+// messages from the inputs of all paused streams with a POST. The response
+// body maps each stream id to its outcome as with group operations.
+func (m *Type) HandleStreamsResume(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamsPaused(w, r, streamGroupResumed, m.Resume)
+}
+
+func (m *Type) handleStreamsPaused(w http.ResponseWriter, r *http.Request, outcome string, fn func(id string) error) {
+	if r.Method != "POST" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	var ids []string
+	m.ForEachStream(func(id string, _ *StreamStatus) bool {
+		ids = append(ids, id)
+		return true
+	})
+
+	m.writeStreamsAction(w, r, ids, outcome, func(_ context.Context, id string) error {
+		err := fn(id)
+		if err != nil {
+			m.manager.Logger().Error("Failed to set pause state of stream '%v': %v\n", id, err)
+		}
+		return err
+	})
+}
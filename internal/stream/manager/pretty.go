@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// prettyJSONWriter buffers a JSON response in order for it to be indented once
+// the handler has finished writing it. Responses of any other type, such as
+// errors and event streams, are passed through untouched.
+type prettyJSONWriter struct {
+	http.ResponseWriter
+	status  int
+	decided bool
+	buf     *bytes.Buffer
+}
+
+func (p *prettyJSONWriter) decide() {
+	if p.decided {
+		return
+	}
+	p.decided = true
+	if mediaType, _, err := mime.ParseMediaType(p.Header().Get("Content-Type")); err == nil && mediaType == "application/json" {
+		p.buf = &bytes.Buffer{}
+	}
+}
+
+func (p *prettyJSONWriter) WriteHeader(code int) {
+	p.decide()
+	if p.buf != nil {
+		if p.status == 0 {
+			p.status = code
+		}
+		return
+	}
+	p.ResponseWriter.WriteHeader(code)
+}
+
+func (p *prettyJSONWriter) Write(b []byte) (int, error) {
+	p.decide()
+	if p.buf != nil {
+		return p.buf.Write(b)
+	}
+	return p.ResponseWriter.Write(b)
+}
+
+func (p *prettyJSONWriter) Flush() {
+	if p.buf != nil {
+		return
+	}
+	if f, ok := p.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes a buffered JSON response in its indented form, falling back to
+// the response as written when it is not valid JSON.
+func (p *prettyJSONWriter) finish() {
+	if p.buf == nil {
+		return
+	}
+
+	body := p.buf.Bytes()
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err == nil {
+		indented.WriteByte('\n')
+		body = indented.Bytes()
+	}
+
+	p.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if p.status != 0 {
+		p.ResponseWriter.WriteHeader(p.status)
+	}
+	_, _ = p.ResponseWriter.Write(body)
+}
+
+// wrapPretty indents the JSON responses of GET requests that provide the query
+// parameter pretty=true, in order for them to be easier to read by humans. Only
+// whitespace is changed, and responses are compact by default.
+func (m *Type) wrapPretty(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Query().Get("pretty") != "true" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		pw := &prettyJSONWriter{ResponseWriter: w}
+		h.ServeHTTP(pw, r)
+		pw.finish()
+	})
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// OptSetGlobalProcessors sets processors that are added to the pipeline of
+// every stream when it is constructed, where pre processors run ahead of the
+// processors of the stream and post processors run after them, allowing
+// cross-cutting concerns such as tagging messages or enforcing a schema to be
+// applied in one place. Individual streams opt out with
+// StreamOptGlobalProcessors.
+//
+// The processors are applied to the config that a stream is constructed with,
+// and are therefore not reflected within the configs returned by the API.
+func OptSetGlobalProcessors(pre, post []processor.Config) func(*Type) {
+	return func(t *Type) {
+		t.globalPreProcessors = pre
+		t.globalPostProcessors = post
+	}
+}
+
+// StreamOptGlobalProcessors sets whether the global processors of the manager,
+// set with OptSetGlobalProcessors, are added to the pipeline of the stream,
+// which they are by default. As with other options the choice of a previous
+// version of the stream is retained unless overridden.
+func StreamOptGlobalProcessors(enabled bool) StreamOpt {
+	return func(s *StreamStatus) {
+		s.skipGlobalProcessors = !enabled
+	}
+}
+
+// withGlobalProcessors returns the config that a stream is constructed with,
+// which has the global processors of the manager added to its pipeline unless
+// the stream has opted out.
+func (m *Type) withGlobalProcessors(wrapper *StreamStatus) stream.Config {
+	conf := wrapper.Config()
+	if wrapper.skipGlobalProcessors || (len(m.globalPreProcessors) == 0 && len(m.globalPostProcessors) == 0) {
+		return conf
+	}
+
+	procs := make([]processor.Config, 0, len(m.globalPreProcessors)+len(conf.Pipeline.Processors)+len(m.globalPostProcessors))
+	procs = append(procs, m.globalPreProcessors...)
+	procs = append(procs, conf.Pipeline.Processors...)
+	procs = append(procs, m.globalPostProcessors...)
+	conf.Pipeline.Processors = procs
+	return conf
+}
@@ -0,0 +1,221 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+)
+
+const defaultQuarantineThreshold = 3
+
+// OptSetQuarantineThreshold sets the number of consecutive times that a stream
+// may fail to be constructed when it is started in the background, such as
+// when it is restarted, before it is quarantined. A stream that fails to be
+// constructed is retried at each health check until it either starts or is
+// quarantined, after which it is no longer run until it is released with
+// Unquarantine. The default is three failures, and a value of zero disables
+// quarantine such that failed streams are retried indefinitely.
+func OptSetQuarantineThreshold(n int) func(*Type) {
+	return func(t *Type) {
+		t.quarantineThreshold = n
+	}
+}
+
+// streamQuarantine tracks the failures to construct a stream in the background,
+// guarded by the mutex of the stream status.
+type streamQuarantine struct {
+	failures int
+	reason   string
+	since    time.Time
+}
+
+// IsQuarantined returns whether the stream has been quarantined after
+// repeatedly failing to be constructed, in which case it is not run until it
+// is released with Unquarantine.
+func (s *StreamStatus) IsQuarantined() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return !s.quarantine.since.IsZero()
+}
+
+// QuarantineReason returns the error that most recently prevented the stream
+// from being constructed, along with the time that it was quarantined and the
+// number of consecutive failures. Returns false if the stream is not
+// quarantined.
+func (s *StreamStatus) QuarantineReason() (reason string, since time.Time, failures int, ok bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.quarantine.since.IsZero() {
+		return "", time.Time{}, 0, false
+	}
+	return s.quarantine.reason, s.quarantine.since, s.quarantine.failures, true
+}
+
+func (s *StreamStatus) constructionFailures() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.quarantine.failures
+}
+
+func (s *StreamStatus) resetConstructionFailures() {
+	s.mut.Lock()
+	s.quarantine = streamQuarantine{}
+	s.mut.Unlock()
+}
+
+// constructionFailed records that a stream failed to be constructed when being
+// started in the background, quarantining it once it has failed too many
+// times in a row. The stopped stream is discarded so that the failed stream is
+// not mistaken for one that is running. Must be called whilst holding the
+// manager lock.
+func (m *Type) constructionFailed(id string, status *StreamStatus, err error) {
+	status.mut.Lock()
+	status.strm = nil
+	status.quarantine.failures++
+	status.quarantine.reason = err.Error()
+	failures := status.quarantine.failures
+	quarantined := m.quarantineThreshold > 0 && failures >= m.quarantineThreshold
+	if quarantined {
+		status.quarantine.since = time.Now()
+	}
+	status.mut.Unlock()
+
+	if quarantined {
+		m.manager.Logger().Error("Stream '%v' failed to be constructed %v times in a row and has been quarantined: %v\n", id, failures, err)
+	} else {
+		m.manager.Logger().Error("Failed to construct stream '%v', it will be retried: %v\n", id, err)
+		m.startHealthLoop()
+	}
+	m.emitEvent(id, LifecycleEventHealth, status)
+}
+
+// checkQuarantine is called from the health loop, and retries starting a
+// stream that failed to be constructed in the background unless it has been
+// quarantined.
+func (m *Type) checkQuarantine(id string, status *StreamStatus) {
+	if status.constructionFailures() == 0 || status.IsQuarantined() {
+		return
+	}
+
+	b := &status.breaker
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if b.open || b.restarting {
+		return
+	}
+	b.restarting = true
+	m.manager.Logger().Info("Retrying stream '%v' after it failed to be constructed\n", id)
+	go m.restartStream(id, status, nil)
+}
+
+// Unquarantine releases a stream from quarantine and attempts to start it
+// again, such as once the cause of its construction failures has been fixed.
+// Returns ErrStreamNotQuarantined if the stream is not quarantined, and the
+// construction error if the stream fails to start again, in which case it
+// remains quarantined.
+func (m *Type) Unquarantine(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return component.ErrTypeClosed
+	}
+	wrapper, exists := m.streams[id]
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+	if !wrapper.IsQuarantined() {
+		return ErrStreamNotQuarantined
+	}
+
+	if err := m.startStream(id, wrapper); err != nil {
+		wrapper.mut.Lock()
+		wrapper.strm = nil
+		wrapper.quarantine.reason = err.Error()
+		wrapper.quarantine.since = time.Now()
+		wrapper.mut.Unlock()
+		return err
+	}
+	wrapper.resetConstructionFailures()
+	m.manager.Logger().Info("Stream '%v' has been released from quarantine\n", id)
+	m.emitEvent(id, LifecycleEventHealth, wrapper)
+	return nil
+}
+
+// HandleStreamsQuarantine is an http.HandleFunc for listing (GET) the streams
+// that are quarantined, along with the reason that each failed to be
+// constructed.
+func (m *Type) HandleStreamsQuarantine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	type quarantinedStream struct {
+		Reason   string `json:"reason"`
+		Since    string `json:"since"`
+		Failures int    `json:"failures"`
+	}
+
+	quarantined := map[string]quarantinedStream{}
+	for id, status := range m.snapshotStreams() {
+		if reason, since, failures, ok := status.QuarantineReason(); ok {
+			quarantined[id] = quarantinedStream{
+				Reason:   reason,
+				Since:    since.UTC().Format(time.RFC3339),
+				Failures: failures,
+			}
+		}
+	}
+
+	resBytes, err := json.Marshal(quarantined)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+// HandleStreamUnquarantine is an http.HandleFunc for releasing (POST) a stream
+// from quarantine, attempting to start it again.
+func (m *Type) HandleStreamUnquarantine(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream unquarantine Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream unquarantine request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	serverErr = m.Unquarantine(id)
+}
@@ -0,0 +1,241 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// streamThrottle limits the rate at which a stream consumes messages from its
+// input, or halts consumption entirely whilst the stream is paused. The limit
+// can be changed at any time, including whilst the stream is running, and is
+// shared by each version of a stream, each applying it with a throttleGate.
+type streamThrottle struct {
+	mut  sync.Mutex
+	rate float64
+	next time.Time
+
+	// Non-nil whilst paused, and closed when resumed.
+	resumeSig chan struct{}
+
+	// Set whilst paused for a duration, after which the stream is resumed
+	// automatically. The generation is incremented by each pause and resume
+	// such that a timer only resumes the pause that scheduled it.
+	resumeTimer *time.Timer
+	resumeAt    time.Time
+	pauseGen    uint64
+}
+
+func (t *streamThrottle) setRate(rate float64) {
+	t.mut.Lock()
+	t.rate = rate
+	t.next = time.Time{}
+	t.mut.Unlock()
+}
+
+func (t *streamThrottle) getRate() float64 {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return t.rate
+}
+
+// reserve returns the period to wait before a batch of n messages may be
+// consumed, reserving the capacity for them.
+func (t *streamThrottle) reserve(n int) time.Duration {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.rate <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(time.Duration(float64(n) / t.rate * float64(time.Second)))
+	return wait
+}
+
+// wait blocks whilst the stream is paused and until a batch of n messages may
+// be consumed, returning early once released is closed.
+func (t *streamThrottle) wait(ctx context.Context, n int, released <-chan struct{}) error {
+	if resumeSig := t.getResumeSig(); resumeSig != nil {
+		select {
+		case <-resumeSig:
+		case <-released:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if wait := t.reserve(n); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// throttleGate is a processor that applies the throttle of a stream to a single
+// version of it. Once the version begins to stop, batches held by a pause or
+// rate limit are released so that it can shut down without waiting for its
+// stop timeout, whilst the throttle remains paused for the next version.
+type throttleGate struct {
+	throttle *streamThrottle
+	released chan struct{}
+	once     sync.Once
+}
+
+func newThrottleGate(t *streamThrottle) *throttleGate {
+	return &throttleGate{
+		throttle: t,
+		released: make(chan struct{}),
+	}
+}
+
+func (g *throttleGate) release() {
+	g.once.Do(func() {
+		close(g.released)
+	})
+}
+
+func (g *throttleGate) ProcessBatch(ctx context.Context, b message.Batch) ([]message.Batch, error) {
+	if err := g.throttle.wait(ctx, b.Len(), g.released); err != nil {
+		return nil, err
+	}
+	return []message.Batch{b}, nil
+}
+
+func (g *throttleGate) Close(ctx context.Context) error {
+	g.release()
+	return nil
+}
+
+// StreamOptRateLimit limits the rate at which a stream consumes messages from
+// its input to a number of messages per second, without modifying the config
+// of the stream. A rate of zero removes the limit.
+func StreamOptRateLimit(messagesPerSecond float64) StreamOpt {
+	return func(s *StreamStatus) {
+		s.throttle.setRate(messagesPerSecond)
+	}
+}
+
+// RateLimit returns the maximum number of messages per second that the stream
+// consumes from its input, where zero means there is no limit.
+func (s *StreamStatus) RateLimit() float64 {
+	return s.throttle.getRate()
+}
+
+// SetRateLimit changes the maximum number of messages per second that a stream
+// consumes from its input, which takes effect immediately without restarting
+// the stream. A rate of zero removes the limit. Streams registered with
+// RegisterStream are not throttled, and result in ErrStreamRegistered.
+func (m *Type) SetRateLimit(id string, messagesPerSecond float64) error {
+	if messagesPerSecond < 0 {
+		return errors.New("rate limit must not be negative")
+	}
+
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return component.ErrTypeClosed
+	}
+	wrapper, exists := m.streams[id]
+	m.lock.Unlock()
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+	if wrapper.origin == StreamOriginRegistered {
+		return ErrStreamRegistered
+	}
+
+	wrapper.throttle.setRate(messagesPerSecond)
+	m.emitEvent(id, LifecycleEventUpdated, wrapper)
+	return nil
+}
+
+type rateLimitBody struct {
+	MessagesPerSecond float64 `json:"messages_per_second"`
+}
+
+// HandleStreamRateLimit is an http.HandleFunc for reading (GET), setting (PUT)
+// and removing (DELETE) the limit on the number of messages per second that a
+// stream consumes from its input.
+func (m *Type) HandleStreamRateLimit(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream rate limit Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream rate limit request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		var info *StreamStatus
+		if info, serverErr = m.Read(id); serverErr != nil {
+			return
+		}
+		var resBytes []byte
+		if resBytes, serverErr = json.Marshal(rateLimitBody{
+			MessagesPerSecond: info.RateLimit(),
+		}); serverErr != nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+	case "PUT":
+		var reqBytes []byte
+		if reqBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		var body rateLimitBody
+		if requestErr = json.Unmarshal(reqBytes, &body); requestErr != nil {
+			return
+		}
+		if body.MessagesPerSecond < 0 {
+			requestErr = errors.New("messages_per_second must not be negative")
+			return
+		}
+		serverErr = m.SetRateLimit(id, body.MessagesPerSecond)
+	case "DELETE":
+		serverErr = m.SetRateLimit(id, 0)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+}
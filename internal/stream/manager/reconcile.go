@@ -0,0 +1,216 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// ReconcileResult describes the changes made to the set of streams by a call
+// to Reconcile, where each field lists stream ids in lexicographical order.
+type ReconcileResult struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+	Failed    []string
+}
+
+// Reconcile brings the set of streams in line with a full set of stream
+// configs, such as those loaded from a directory of config files. Streams that
+// are not present within the set are deleted, streams that are new to the set
+// are created, and existing streams are updated only when their config differs
+// from the running config. Configs are compared in their sanitised form, and
+// therefore streams that differ only cosmetically are left running untouched.
+//
+// All changes are attempted even when some of them fail, in which case the
+// returned error combines each of the failures. The outcome of the most recent
+// reconcile is recorded in metrics and served from the /streams/reloads
+// endpoint.
+func (m *Type) Reconcile(ctx context.Context, confs map[string]stream.Config) (ReconcileResult, error) {
+	return m.reconcileRecorded(ctx, confs, nil, nil)
+}
+
+func (m *Type) reconcileRecorded(ctx context.Context, confs map[string]stream.Config, optsFor func(id string) []StreamOpt, deletable func(*StreamStatus) bool) (ReconcileResult, error) {
+	startedAt := time.Now()
+	res, errs := m.reconcile(ctx, confs, optsFor, deletable)
+	m.reloads.record(startedAt, time.Since(startedAt), res, errs)
+	return res, errors.Join(errs...)
+}
+
+// reconcile applies a set of stream configs, where optsFor, when non-nil,
+// provides the options to apply to each stream. Streams absent from the set
+// are deleted, limited to those for which deletable returns true when it is
+// non-nil.
+func (m *Type) reconcile(ctx context.Context, confs map[string]stream.Config, optsFor func(id string) []StreamOpt, deletable func(*StreamStatus) bool) (res ReconcileResult, errs []error) {
+	existing := m.snapshotStreams()
+
+	var toDelete []string
+	for id, status := range existing {
+		if _, exists := confs[id]; exists {
+			continue
+		}
+		if deletable == nil || deletable(status) {
+			toDelete = append(toDelete, id)
+		}
+	}
+
+	var (
+		resMut sync.Mutex
+		wg     sync.WaitGroup
+	)
+	record := func(ids *[]string, id string, err error, action string) {
+		resMut.Lock()
+		defer resMut.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to %v stream '%v': %w", action, id, err))
+			res.Failed = append(res.Failed, id)
+			return
+		}
+		*ids = append(*ids, id)
+	}
+
+	// Deletions are completed before creating new streams so that the new set
+	// is not rejected by a stream limit due to streams that are being removed.
+	wg.Add(len(toDelete))
+	for _, id := range toDelete {
+		go func(sid string) {
+			defer wg.Done()
+			err := m.Delete(ctx, sid)
+			if errors.Is(err, ErrStreamDoesNotExist) {
+				err = nil
+			}
+			record(&res.Deleted, sid, err, "delete")
+		}(id)
+	}
+	wg.Wait()
+
+	wg.Add(len(confs))
+	for id, conf := range confs {
+		_, wasRunning := existing[id]
+		go func(sid string, sconf stream.Config) {
+			defer wg.Done()
+			var opts []StreamOpt
+			if optsFor != nil {
+				opts = optsFor(sid)
+			}
+			changed, err := m.Apply(ctx, sid, sconf, opts...)
+			switch {
+			case !wasRunning:
+				record(&res.Created, sid, err, "create")
+			case changed:
+				record(&res.Updated, sid, err, "update")
+			default:
+				record(&res.Unchanged, sid, err, "update")
+			}
+		}(id, conf)
+	}
+	wg.Wait()
+
+	sort.Strings(res.Created)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Deleted)
+	sort.Strings(res.Unchanged)
+	sort.Strings(res.Failed)
+	return res, errs
+}
+
+//------------------------------------------------------------------------------
+
+type reloadSummary struct {
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration"`
+	Created   int       `json:"created"`
+	Updated   int       `json:"updated"`
+	Deleted   int       `json:"deleted"`
+	Unchanged int       `json:"unchanged"`
+	Failed    int       `json:"failed"`
+	Errors    []string  `json:"errors,omitempty"`
+}
+
+// reloadTracker records the outcome of each call to Reconcile, both as metrics
+// and as a summary of the most recent reconcile.
+type reloadTracker struct {
+	mReloads   metrics.StatCounter
+	mStreams   metrics.StatGaugeVec
+	mTimestamp metrics.StatGauge
+	mDuration  metrics.StatTimer
+
+	mut   sync.Mutex
+	count int
+	last  *reloadSummary
+}
+
+func newReloadTracker(stats metrics.Type) *reloadTracker {
+	return &reloadTracker{
+		mReloads:   stats.GetCounter("stream_reloads"),
+		mStreams:   stats.GetGaugeVec("stream_reload_streams", "result"),
+		mTimestamp: stats.GetGauge("stream_reload_timestamp"),
+		mDuration:  stats.GetTimer("stream_reload_duration_ns"),
+	}
+}
+
+func (t *reloadTracker) record(startedAt time.Time, duration time.Duration, res ReconcileResult, errs []error) {
+	summary := &reloadSummary{
+		StartedAt: startedAt,
+		Duration:  duration.Seconds(),
+		Created:   len(res.Created),
+		Updated:   len(res.Updated),
+		Deleted:   len(res.Deleted),
+		Unchanged: len(res.Unchanged),
+		Failed:    len(res.Failed),
+	}
+	for _, err := range errs {
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+
+	t.mReloads.Incr(1)
+	t.mStreams.With("created").Set(int64(summary.Created))
+	t.mStreams.With("updated").Set(int64(summary.Updated))
+	t.mStreams.With("deleted").Set(int64(summary.Deleted))
+	t.mStreams.With("unchanged").Set(int64(summary.Unchanged))
+	t.mStreams.With("failed").Set(int64(summary.Failed))
+	t.mTimestamp.Set(startedAt.Unix())
+	t.mDuration.Timing(duration.Nanoseconds())
+
+	t.mut.Lock()
+	t.count++
+	t.last = summary
+	t.mut.Unlock()
+}
+
+// HandleStreamReloads is an http.HandleFunc for obtaining the number of times
+// that the set of streams has been reconciled, along with a summary of the
+// changes made by the most recent reconcile.
+func (m *Type) HandleStreamReloads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	m.reloads.mut.Lock()
+	body := struct {
+		Reloads int            `json:"reloads"`
+		Last    *reloadSummary `json:"last,omitempty"`
+	}{
+		Reloads: m.reloads.count,
+		Last:    m.reloads.last,
+	}
+	bodyBytes, err := json.Marshal(body)
+	m.reloads.mut.Unlock()
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bodyBytes)
+}
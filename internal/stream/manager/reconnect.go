@@ -0,0 +1,50 @@
+package manager
+
+import (
+	"time"
+)
+
+// OptSetUnhealthyGracePeriod sets a period for which a running stream that
+// loses the connection of its input or output, having previously been
+// connected, is reported as reconnecting rather than unhealthy. This gives the
+// reconnect logic of components time to recover from transient failures
+// before health events are emitted and the ready check fails. A value of zero
+// (the default) reports streams as unhealthy as soon as they disconnect.
+func OptSetUnhealthyGracePeriod(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.unhealthyGrace = d
+	}
+}
+
+// observeHealth is called from the health loop with whether the stream is
+// running and connected, and returns whether the stream is considered healthy,
+// which includes streams that are reconnecting within the grace period.
+func (s *StreamStatus) observeHealth(now time.Time, ready bool, grace time.Duration) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if ready {
+		s.connectedOnce = true
+		s.disconnectedAt = time.Time{}
+		s.reconnecting = false
+		return true
+	}
+	if !s.connectedOnce || grace <= 0 || s.strm == nil || s.closed {
+		s.reconnecting = false
+		return false
+	}
+	if s.disconnectedAt.IsZero() {
+		s.disconnectedAt = now
+	}
+	s.reconnecting = now.Sub(s.disconnectedAt) < grace
+	return s.reconnecting
+}
+
+// IsReconnecting returns whether the stream is running but has lost the
+// connection of its input or output within the unhealthy grace period, during
+// which it is not yet considered unhealthy.
+func (s *StreamStatus) IsReconnecting() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.reconnecting && s.strm != nil && !s.closed
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"errors"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// RegisterStream adds a stream that has already been constructed, such as one
+// created with stream.NewFromComponents, to the manager under a unique ID
+// rather than constructing it from a config. The stream is listed and can be
+// deleted like any other, but as the manager is unable to reconstruct it the
+// stream is neither paused nor restarted, and its config is empty. Updating
+// the stream replaces it with one constructed from the config provided.
+//
+// The manager takes ownership of the stream, which is stopped when the stream
+// is deleted or the manager is stopped. If the ID already exists
+// ErrStreamExists is returned.
+func (m *Type) RegisterStream(id string, strm *stream.Type, opts ...StreamOpt) error {
+	if strm == nil {
+		return errors.New("a stream must be provided")
+	}
+	if err := checkStreamID(id); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return component.ErrTypeClosed
+	}
+
+	if _, exists := m.streams[id]; exists {
+		return ErrStreamExists
+	}
+	if m.maxStreams > 0 && len(m.streams) >= m.maxStreams {
+		return ErrStreamLimitReached
+	}
+
+	wrapper := newStreamStatus(stream.Config{}, metrics.NewLocal(), nil, opts...)
+	wrapper.origin, wrapper.originPath = StreamOriginRegistered, ""
+	wrapper.setStarting()
+	wrapper.setStream(strm)
+
+	m.streams[id] = wrapper
+	m.emitEvent(id, LifecycleEventCreated, wrapper)
+	return nil
+}
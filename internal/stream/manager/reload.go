@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// OptSetStreamReaderOpts sets options applied to the config readers that read
+// stream configs again when streams are reloaded from a directory or file,
+// such as those obtained with config.Reader.StreamOpts from the reader that
+// the streams were booted with. This ensures that reloaded streams are given
+// the same ids and have access to the same shared anchors as when booted.
+func OptSetStreamReaderOpts(opts ...config.OptFunc) func(*Type) {
+	return func(t *Type) {
+		t.streamReaderOpts = opts
+	}
+}
+
+// streamReader returns a config reader of the stream configs at a path, with
+// the stream reader options of the manager applied.
+func (m *Type) streamReader(path string) *config.Reader {
+	opts := []config.OptFunc{
+		config.OptSetLintConfig(docs.NewLintConfig(m.manager.Environment())),
+		config.OptSetLogger(m.manager.Logger()),
+	}
+	opts = append(opts, m.streamReaderOpts...)
+	opts = append(opts, config.OptSetStreamPaths(path))
+	return config.NewReader("", nil, opts...)
+}
+
+// ReloadFromDirectory reads the stream configs found within a directory and
+// reconciles the running streams against them. When the configs cannot be read,
+// for example due to a parse error, the running streams are left untouched and
+// the error is returned. Streams loaded from the directory are given the
+// directory origin along with the path of their config file, and only streams
+// of the directory origin are deleted when their config files are removed,
+// leaving streams created via the API or registered untouched. Stream ids are
+// inferred with the options set with OptSetStreamReaderOpts, and conflicting
+// ids result in an error unless those options set a collision strategy.
+func (m *Type) ReloadFromDirectory(ctx context.Context, dir string) (ReconcileResult, error) {
+	rdr := m.streamReader(dir)
+
+	confs := map[string]stream.Config{}
+	lints, err := rdr.ReadStreamsCtx(ctx, confs)
+	if err != nil {
+		return ReconcileResult{}, err
+	}
+	for _, lint := range lints {
+		m.manager.Logger().Warn("Config lint error: %v\n", lint)
+	}
+
+	paths := rdr.StreamConfigPaths()
+	return m.reconcileRecorded(ctx, confs, func(id string) []StreamOpt {
+		return []StreamOpt{StreamOptOriginDirectory(paths[id])}
+	}, func(s *StreamStatus) bool {
+		origin, _ := s.Origin()
+		return origin == StreamOriginDirectory
+	})
+}
+
+// errStreamOriginNotFile is returned when reloading a stream that was not
+// loaded from a config file.
+var errStreamOriginNotFile = errors.New("stream was not loaded from a config file")
+
+// ReloadStream reads the config file that a stream was loaded from again and
+// updates the stream with it, leaving all other streams untouched. The new
+// version of the stream is swapped in without downtime where possible. Returns
+// an error if the stream was not loaded from a file, or if the file can no
+// longer be read, in which case the running stream is left untouched. The file
+// is read with the options set with OptSetStreamReaderOpts.
+func (m *Type) ReloadStream(ctx context.Context, id string) error {
+	info, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+
+	origin, path := info.Origin()
+	if origin != StreamOriginDirectory || path == "" {
+		return errStreamOriginNotFile
+	}
+
+	rdr := m.streamReader(path)
+
+	confs := map[string]stream.Config{}
+	lints, err := rdr.ReadStreamsCtx(ctx, confs)
+	if err != nil {
+		return fmt.Errorf("failed to read stream config file %v: %w", path, err)
+	}
+	for _, lint := range lints {
+		m.manager.Logger().Warn("Config lint error: %v\n", lint)
+	}
+	if len(confs) != 1 {
+		return fmt.Errorf("expected stream config file %v to contain one stream, found %v", path, len(confs))
+	}
+
+	for _, conf := range confs {
+		if err := m.Swap(ctx, id, conf); err != nil {
+			return err
+		}
+	}
+	m.manager.Logger().Info("Reloaded stream '%v' config from %v\n", id, path)
+	return nil
+}
+
+// HandleStreamReload is an http.HandleFunc for reloading the config of a
+// stream from the file that it was loaded from with a POST.
+func (m *Type) HandleStreamReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+
+	err := m.ReloadStream(ctx, id)
+	switch {
+	case err == nil:
+	case writeStreamError(w, err):
+	case errors.Is(err, errStreamOriginNotFile):
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusNotFound)
+	default:
+		m.manager.Logger().Error("Stream reload Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+	}
+}
+
+// HandleReloadOnSignal reloads the stream configs of a directory each time the
+// process receives a SIGHUP, reconciling the running streams against them and
+// logging the result. Failed reloads are logged and the prior configs are kept.
+// Signal handling is opt-in as embedders may wish to handle signals themselves.
+// The returned func stops handling the signal.
+func HandleReloadOnSignal(mgr *Type, dir string) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	stopChan := make(chan struct{})
+	doneChan := make(chan struct{})
+	go func() {
+		defer close(doneChan)
+		for {
+			select {
+			case <-sigChan:
+			case <-stopChan:
+				return
+			}
+
+			log := mgr.manager.Logger()
+			log.Info("Received SIGHUP, reloading stream configs from %v\n", dir)
+
+			ctx, done := context.WithTimeout(context.Background(), mgr.APITimeout())
+			res, err := mgr.ReloadFromDirectory(ctx, dir)
+			done()
+			if err != nil {
+				log.Error("Failed to reload stream configs from %v: %v\n", dir, err)
+				continue
+			}
+			log.Info("Reloaded stream configs from %v: %v created, %v updated, %v deleted, %v unchanged\n",
+				dir, len(res.Created), len(res.Updated), len(res.Deleted), len(res.Unchanged))
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(stopChan)
+		<-doneChan
+	}
+}
@@ -0,0 +1,22 @@
+package manager
+
+import (
+	"fmt"
+)
+
+// reservedStreamIDs are the stream ids that collide with the bulk endpoints
+// registered beneath /streams, such as POST /streams/pause, which would
+// otherwise shadow the /streams/{id} endpoint of a stream with that id.
+var reservedStreamIDs = map[string]struct{}{
+	"pause":  {},
+	"resume": {},
+}
+
+// checkStreamID returns an error matching ErrStreamConfigInvalid when a stream
+// id is reserved by the streams API.
+func checkStreamID(id string) error {
+	if _, reserved := reservedStreamIDs[id]; reserved {
+		return withErrorKind(ErrStreamConfigInvalid, fmt.Errorf("stream id %q is reserved by the streams API", id))
+	}
+	return nil
+}
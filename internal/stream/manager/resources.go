@@ -0,0 +1,149 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// ErrResourceInUse is returned when attempting to remove a resource that is
+// referenced by the config of one or more streams.
+type ErrResourceInUse []string
+
+// Error implements the standard error interface.
+func (e ErrResourceInUse) Error() string {
+	return "resource is in use by streams: " + strings.Join(e, ", ")
+}
+
+// streamReferencesResource returns whether the config of a stream references
+// a resource of a given type. Input, output and processor resources are
+// referenced by resource components, which can be found exactly. Caches and
+// rate limits are referenced by fields of components that aren't marked as
+// such, and therefore a stream is considered to reference one when any field
+// within its config has the resource label as its value.
+func (m *Type) streamReferencesResource(conf stream.Config, docType docs.Type, label string) (bool, error) {
+	var node yaml.Node
+	if err := node.Encode(conf.GetRawSource()); err != nil {
+		return false, err
+	}
+
+	switch docType {
+	case docs.TypeInput, docs.TypeOutput, docs.TypeProcessor:
+		var found bool
+		err := stream.Spec().WalkYAML(&node, m.manager.Environment(), func(c docs.WalkedYAMLComponent) error {
+			if found || c.ComponentType != docType || c.Name != "resource" {
+				return nil
+			}
+			for i := 0; i < len(c.Conf.Content)-1; i += 2 {
+				if c.Conf.Content[i].Value == "resource" && c.Conf.Content[i+1].Value == label {
+					found = true
+				}
+			}
+			return nil
+		})
+		return found, err
+	}
+	return yamlHasFieldValue(&node, label), nil
+}
+
+func yamlHasFieldValue(node *yaml.Node, v string) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.ScalarNode {
+				if key.Value != "label" && value.Value == v {
+					return true
+				}
+				continue
+			}
+			if yamlHasFieldValue(value, v) {
+				return true
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, child := range node.Content {
+			if child.Kind == yaml.ScalarNode {
+				if child.Value == v {
+					return true
+				}
+				continue
+			}
+			if yamlHasFieldValue(child, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resourceUsers returns the sorted identifiers of all streams that reference a
+// resource of a given type.
+func (m *Type) resourceUsers(docType docs.Type, label string) ([]string, error) {
+	m.lock.Lock()
+	confs := make(map[string]stream.Config, len(m.streams))
+	for id, status := range m.streams {
+		confs[id] = status.Config()
+	}
+	m.lock.Unlock()
+
+	var users []string
+	for id, conf := range confs {
+		referenced, err := m.streamReferencesResource(conf, docType, label)
+		if err != nil {
+			return nil, err
+		}
+		if referenced {
+			users = append(users, id)
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// ErrResourceDoesNotExist is returned when attempting to remove a resource that
+// does not exist.
+var ErrResourceDoesNotExist = errors.New("resource does not exist")
+
+// RemoveResource attempts to close and remove a resource of a given type, which
+// is one of cache, input, output, processor or rate_limit. Returns
+// ErrResourceInUse if the resource is referenced by the config of any streams,
+// in which case the resource is left intact.
+func (m *Type) RemoveResource(ctx context.Context, docType docs.Type, label string) error {
+	var probeFn func(string) bool
+	var removeFn func(context.Context, string) error
+	switch docType {
+	case docs.TypeCache:
+		probeFn, removeFn = m.manager.ProbeCache, m.manager.RemoveCache
+	case docs.TypeInput:
+		probeFn, removeFn = m.manager.ProbeInput, m.manager.RemoveInput
+	case docs.TypeOutput:
+		probeFn, removeFn = m.manager.ProbeOutput, m.manager.RemoveOutput
+	case docs.TypeProcessor:
+		probeFn, removeFn = m.manager.ProbeProcessor, m.manager.RemoveProcessor
+	case docs.TypeRateLimit:
+		probeFn, removeFn = m.manager.ProbeRateLimit, m.manager.RemoveRateLimit
+	default:
+		return fmt.Errorf("resource type %v not supported", docType)
+	}
+
+	if !probeFn(label) {
+		return ErrResourceDoesNotExist
+	}
+
+	users, err := m.resourceUsers(docType, label)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return ErrResourceInUse(users)
+	}
+	return removeFn(ctx, label)
+}
@@ -0,0 +1,292 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+const (
+	defaultRestartMaxFailures = 3
+	defaultRestartWindow      = 10 * time.Minute
+)
+
+// restartBreaker tracks the automatic restarts of a stream. Restarts that are
+// not followed by the stream becoming ready are considered failed, and once
+// too many restarts fail within a window the breaker opens, at which point the
+// stream is stopped and is no longer restarted until it is reset.
+type restartBreaker struct {
+	mut          sync.Mutex
+	unreadySince time.Time
+	restarts     []time.Time
+	open         bool
+	restarting   bool
+}
+
+// IsFailed returns whether the stream has been stopped after repeatedly failing
+// to become ready following automatic restarts, in which case it will not be
+// restarted again until it is reset.
+func (s *StreamStatus) IsFailed() bool {
+	s.breaker.mut.Lock()
+	defer s.breaker.mut.Unlock()
+	return s.breaker.open
+}
+
+// RestartFailures returns the number of consecutive automatic restarts of the
+// stream that have failed to result in the stream becoming ready.
+func (s *StreamStatus) RestartFailures() int {
+	s.breaker.mut.Lock()
+	defer s.breaker.mut.Unlock()
+	return len(s.breaker.restarts)
+}
+
+// OptSetAutoRestart sets a period after which a running stream that has failed
+// to become ready, meaning its input and output are connected, is restarted.
+// Streams that have closed of their own accord are not restarted. A value of
+// zero (the default) disables automatic restarts.
+func OptSetAutoRestart(unreadyTimeout time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.restartTimeout = unreadyTimeout
+	}
+}
+
+// OptSetRestartCircuitBreaker sets the maximum number of consecutive automatic
+// restarts of a stream that may fail within a window of time before the stream
+// is marked as failed and stopped, after which it is no longer restarted until
+// it is reset with ResetStream. The default is three failures within ten
+// minutes.
+func OptSetRestartCircuitBreaker(maxFailures int, window time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.restartMaxFailures = maxFailures
+		t.restartWindow = window
+	}
+}
+
+// OptSetHealthCheckInterval sets the interval at which the readiness of each
+// stream is checked in order to emit health events and trigger automatic
+// restarts. The default is one second.
+func OptSetHealthCheckInterval(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.healthCheckInterval = d
+	}
+}
+
+// checkAutoRestart is called from the health loop with the current readiness
+// of a stream, and restarts the stream or opens its circuit breaker once it has
+// been unready for longer than the restart timeout.
+func (m *Type) checkAutoRestart(id string, status *StreamStatus, ready bool) {
+	b := &status.breaker
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if ready {
+		b.unreadySince = time.Time{}
+		b.restarts = nil
+		return
+	}
+	if b.open || b.restarting || !status.IsRunning() || status.origin == StreamOriginRegistered {
+		b.unreadySince = time.Time{}
+		return
+	}
+
+	now := time.Now()
+	if b.unreadySince.IsZero() {
+		b.unreadySince = now
+		return
+	}
+	if now.Sub(b.unreadySince) < m.restartTimeout {
+		return
+	}
+	b.unreadySince = time.Time{}
+
+	cutoff := now.Add(-m.restartWindow)
+	recent := b.restarts[:0]
+	for _, t := range b.restarts {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	b.restarts = recent
+
+	strm := status.getStream()
+	if len(b.restarts) >= m.restartMaxFailures {
+		b.open = true
+		m.manager.Logger().Error("Stream '%v' failed to become ready after %v consecutive restarts and will be stopped until it is reset\n", id, len(b.restarts))
+		go m.stopFailedStream(id, status, strm)
+		return
+	}
+
+	b.restarts = append(b.restarts, now)
+	b.restarting = true
+	m.manager.Logger().Warn("Restarting stream '%v' as it has not been ready for %v\n", id, m.restartTimeout)
+	go m.restartStream(id, status, strm)
+}
+
+func (m *Type) restartStream(id string, status *StreamStatus, strm *stream.Type) {
+	defer func() {
+		status.breaker.mut.Lock()
+		status.breaker.restarting = false
+		status.breaker.mut.Unlock()
+	}()
+
+	ctx, done := context.WithTimeout(context.Background(), m.APITimeout())
+	defer done()
+
+	if strm != nil {
+		if err := strm.Stop(ctx); err != nil {
+			m.manager.Logger().Error("Failed to stop stream '%v' for a restart, the restart will be attempted again: %v\n", id, err)
+			m.restartStopFailed(id, status)
+			return
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// The stream may have been updated or deleted whilst it was stopping.
+	if m.closed || m.streams[id] != status {
+		return
+	}
+	status.resetProgress(time.Now())
+	if err := m.startStream(id, status); err != nil {
+		m.constructionFailed(id, status, err)
+		return
+	}
+	status.resetConstructionFailures()
+	status.mut.Lock()
+	status.restartCount++
+	status.mut.Unlock()
+	m.emitEvent(id, LifecycleEventHealth, status)
+}
+
+// restartStopFailed clears the stall flag and restart state of a stream that
+// could not be stopped for a restart, such that the restart is attempted again
+// at the next health check rather than the stream remaining marked as stalled.
+func (m *Type) restartStopFailed(id string, status *StreamStatus) {
+	status.mut.Lock()
+	wasStalled := status.stalled
+	status.stalled = false
+	status.mut.Unlock()
+
+	b := &status.breaker
+	b.mut.Lock()
+	b.restarting = false
+	if m.restartTimeout > 0 {
+		// The stream has been unready for at least the restart timeout.
+		b.unreadySince = time.Now().Add(-m.restartTimeout)
+	}
+	b.mut.Unlock()
+
+	if wasStalled {
+		m.emitEvent(id, LifecycleEventHealth, status)
+	}
+}
+
+func (m *Type) stopFailedStream(id string, status *StreamStatus, strm *stream.Type) {
+	ctx, done := context.WithTimeout(context.Background(), m.APITimeout())
+	defer done()
+
+	if strm != nil {
+		if err := strm.Stop(ctx); err != nil {
+			m.manager.Logger().Error("Failed to stop failed stream '%v': %v\n", id, err)
+		}
+	}
+	m.emitEvent(id, LifecycleEventHealth, status)
+}
+
+// ResetStream clears the restart failures of a stream and, if the stream was
+// stopped after repeatedly failing to become ready, starts it again. Returns an
+// error if the stream does not exist.
+func (m *Type) ResetStream(ctx context.Context, id string) error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return component.ErrTypeClosed
+	}
+	wrapper, exists := m.streams[id]
+	m.lock.Unlock()
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+
+	b := &wrapper.breaker
+	b.mut.Lock()
+	wasOpen := b.open
+	b.open = false
+	b.restarts = nil
+	b.unreadySince = time.Time{}
+	b.mut.Unlock()
+
+	if !wasOpen {
+		return nil
+	}
+
+	if strm := wrapper.getStream(); strm != nil {
+		if err := strm.Stop(ctx); err != nil {
+			return stopErr(err)
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return component.ErrTypeClosed
+	}
+	if m.streams[id] != wrapper {
+		return ErrStreamDoesNotExist
+	}
+	if err := m.startStream(id, wrapper); err != nil {
+		return err
+	}
+	m.emitEvent(id, LifecycleEventHealth, wrapper)
+	return nil
+}
+
+// HandleStreamReset is an http.HandleFunc for resetting (POST) the restart
+// failures of a stream, starting it again if it was stopped after repeatedly
+// failing to become ready.
+func (m *Type) HandleStreamReset(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream reset Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream reset request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+	serverErr = m.ResetStream(ctx, id)
+}
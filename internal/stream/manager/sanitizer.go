@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// OptSetConfigSanitizer sets a function that is called with the config of a
+// stream before it is served by the API, returning the structure to serialize
+// in its place. This allows embedders to redact secrets such as passwords, or
+// strip fields, before configs are exposed to less trusted clients. Sanitized
+// configs are served by stream reads, config section reads and exports, and
+// therefore exports may no longer be restored faithfully. When unset the config
+// is served as it was submitted.
+func OptSetConfigSanitizer(fn func(stream.Config) (any, error)) func(*Type) {
+	return func(t *Type) {
+		t.configSanitizer = fn
+	}
+}
+
+// OptSetAllowRawConfig sets whether the complete config of a stream can be
+// read from the /streams/{id}/config/raw endpoint, bypassing any sanitizer set
+// with OptSetConfigSanitizer. This is dangerous as raw configs include secrets
+// such as passwords, and must only be enabled when the API is restricted to
+// trusted clients. This is disabled by default.
+func OptSetAllowRawConfig(b bool) func(*Type) {
+	return func(t *Type) {
+		t.allowRawConfig = b
+	}
+}
+
+// servedConfig returns the form of a stream config to be served by the API.
+func (m *Type) servedConfig(conf stream.Config) (any, error) {
+	if m.configSanitizer == nil {
+		return conf.GetRawSource(), nil
+	}
+	return m.configSanitizer(conf)
+}
+
+// HandleStreamRawConfig is an http.HandleFunc for reading the complete config
+// of a stream with a GET, as it was submitted and without being sanitized.
+// Requests are rejected with 403 Forbidden unless the manager is configured
+// with OptSetAllowRawConfig. The config is JSON by default, or YAML with the
+// query parameter format=yaml.
+func (m *Type) HandleStreamRawConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+	if !m.allowRawConfig {
+		http.Error(w, "Reading raw stream configs is disabled", http.StatusForbidden)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	info, err := m.Read(id)
+	if writeStreamError(w, err) {
+		return
+	}
+	if err != nil {
+		m.manager.Logger().Error("Stream raw config Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	conf := info.Config()
+	var resBytes []byte
+	var contentType string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		resBytes, err = json.Marshal(conf.GetRawSource())
+		contentType = "application/json"
+	case "yaml":
+		resBytes, err = yaml.Marshal(conf.GetRawSource())
+		contentType = "application/yaml"
+	default:
+		http.Error(w, fmt.Sprintf("Error: unsupported format: %v", format), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		m.manager.Logger().Error("Stream raw config Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	m.manager.Logger().Warn("Serving the raw config of stream '%v', which may include secrets\n", id)
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(resBytes)
+}
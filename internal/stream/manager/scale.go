@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+var errInvalidThreads = errors.New("threads must be either a positive number or -1")
+
+// Parallelism returns the number of threads that the pipeline of the stream
+// processes messages with.
+func (s *StreamStatus) Parallelism() int {
+	if threads := s.Config().Pipeline.Threads; threads > 0 {
+		return threads
+	}
+	return runtime.NumCPU()
+}
+
+// Scale changes the number of threads that the pipeline of a stream processes
+// messages with, where -1 matches the number of logical CPUs. The config of the
+// stream is modified accordingly and the new version of the stream is swapped
+// in as with Swap, which keeps the stream consuming whilst the new pipeline is
+// brought up where possible. Streams registered with RegisterStream have no
+// config to modify, and result in ErrStreamRegistered.
+func (m *Type) Scale(ctx context.Context, id string, threads int) error {
+	if threads == 0 || threads < -1 {
+		return errInvalidThreads
+	}
+
+	info, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if info.origin == StreamOriginRegistered {
+		return ErrStreamRegistered
+	}
+
+	conf := info.Config()
+	rawConf, _ := value.IClone(conf.GetRawSource()).(map[string]any)
+	if rawConf == nil {
+		rawConf = map[string]any{}
+	}
+	pipelineConf, _ := rawConf["pipeline"].(map[string]any)
+	if pipelineConf == nil {
+		pipelineConf = map[string]any{}
+	}
+	pipelineConf["threads"] = threads
+	rawConf["pipeline"] = pipelineConf
+
+	if conf, err = m.streamConfigFromAny(rawConf); err != nil {
+		return err
+	}
+	return m.Swap(ctx, id, conf)
+}
+
+type scaleBody struct {
+	Threads int `json:"threads"`
+}
+
+// HandleStreamScale is an http.HandleFunc for reading (GET) and changing (PUT)
+// the number of threads that the pipeline of a stream processes messages with.
+func (m *Type) HandleStreamScale(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if writeStreamError(w, serverErr) {
+			return
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("Stream scale Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Stream scale request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		var info *StreamStatus
+		if info, serverErr = m.Read(id); serverErr != nil {
+			return
+		}
+		var resBytes []byte
+		if resBytes, serverErr = json.Marshal(scaleBody{
+			Threads: info.Parallelism(),
+		}); serverErr != nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+	case "PUT":
+		var reqBytes []byte
+		if reqBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		var body scaleBody
+		if requestErr = json.Unmarshal(reqBytes, &body); requestErr != nil {
+			return
+		}
+		if body.Threads == 0 || body.Threads < -1 {
+			requestErr = errInvalidThreads
+			return
+		}
+
+		ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+		defer done()
+		serverErr = m.Scale(ctx, id, body.Threads)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+}
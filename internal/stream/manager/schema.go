@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+func componentSchemaDefinition(specs []docs.ComponentSpec, ctype docs.Type) map[string]any {
+	generalFields := map[string]any{}
+	for k, v := range docs.ReservedFieldsByType(ctype) {
+		generalFields[k] = v.JSONSchema()
+	}
+
+	names := make([]string, 0, len(specs))
+	componentDefs := make([]any, 0, len(specs))
+	for _, s := range specs {
+		names = append(names, s.Name)
+		componentDefs = append(componentDefs, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				s.Name: s.Config.JSONSchema(),
+			},
+		})
+	}
+	sort.Strings(names)
+
+	generalFields["type"] = map[string]any{
+		"type": "string",
+		"enum": names,
+	}
+
+	return map[string]any{
+		"allOf": []any{
+			map[string]any{
+				"anyOf": componentDefs,
+			},
+			map[string]any{
+				"type":       "object",
+				"properties": generalFields,
+			},
+		},
+	}
+}
+
+// streamConfigSchema generates a JSON Schema for stream configs from the same
+// field specs that are used for parsing them, where the definitions of each
+// component type are limited to those registered with the environment of the
+// manager.
+func (m *Type) streamConfigSchema() ([]byte, error) {
+	env := m.manager.Environment()
+	return json.Marshal(map[string]any{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"type":                 "object",
+		"properties":           stream.Spec().JSONSchema(),
+		"additionalProperties": false,
+		"definitions": map[string]any{
+			"input":      componentSchemaDefinition(env.InputDocs(), docs.TypeInput),
+			"buffer":     componentSchemaDefinition(env.BufferDocs(), docs.TypeBuffer),
+			"cache":      componentSchemaDefinition(env.CacheDocs(), docs.TypeCache),
+			"processor":  componentSchemaDefinition(env.ProcessorDocs(), docs.TypeProcessor),
+			"rate_limit": componentSchemaDefinition(env.RateLimitDocs(), docs.TypeRateLimit),
+			"output":     componentSchemaDefinition(env.OutputDocs(), docs.TypeOutput),
+			"scanner":    componentSchemaDefinition(env.ScannerDocs(), docs.TypeScanner),
+		},
+	})
+}
+
+// HandleStreamSchema is an http.HandleFunc for obtaining a JSON Schema of
+// stream configs.
+func (m *Type) HandleStreamSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	m.schemaOnce.Do(func() {
+		m.schemaBytes, m.schemaErr = m.streamConfigSchema()
+	})
+	if m.schemaErr != nil {
+		m.manager.Logger().Error("Stream schema Error: %v\n", m.schemaErr)
+		http.Error(w, "Error: "+m.schemaErr.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(m.schemaBytes)
+}
+
+type componentBody struct {
+	Name       string         `json:"name"`
+	Status     docs.Status    `json:"status"`
+	Summary    string         `json:"summary,omitempty"`
+	Categories []string       `json:"categories,omitempty"`
+	Config     docs.FieldSpec `json:"config"`
+}
+
+func componentBodies(specs []docs.ComponentSpec) []componentBody {
+	bodies := make([]componentBody, 0, len(specs))
+	for _, s := range specs {
+		bodies = append(bodies, componentBody{
+			Name:       s.Name,
+			Status:     s.Status,
+			Summary:    s.Summary,
+			Categories: s.Categories,
+			Config:     s.Config,
+		})
+	}
+	sort.Slice(bodies, func(i, j int) bool {
+		return bodies[i].Name < bodies[j].Name
+	})
+	return bodies
+}
+
+// HandleStreamComponents is an http.HandleFunc for listing the input, buffer,
+// processor and output types that the manager is able to construct, along with
+// the fields of their configs. The listing can be limited to specific types of
+// component with the query parameter type, e.g. type=input.
+func (m *Type) HandleStreamComponents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	env := m.manager.Environment()
+	specsByType := map[string]func() []docs.ComponentSpec{
+		string(docs.TypeInput):     env.InputDocs,
+		string(docs.TypeBuffer):    env.BufferDocs,
+		string(docs.TypeProcessor): env.ProcessorDocs,
+		string(docs.TypeOutput):    env.OutputDocs,
+	}
+
+	types := r.URL.Query()["type"]
+	if len(types) == 0 {
+		for k := range specsByType {
+			types = append(types, k)
+		}
+	}
+
+	components := map[string][]componentBody{}
+	for _, t := range types {
+		specsFn, exists := specsByType[t]
+		if !exists {
+			http.Error(w, "Error: unsupported component type: "+t, http.StatusBadRequest)
+			return
+		}
+		components[t] = componentBodies(specsFn())
+	}
+
+	resBytes, err := json.Marshal(components)
+	if err != nil {
+		m.manager.Logger().Error("Stream components Error: %v\n", err)
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
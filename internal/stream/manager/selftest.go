@@ -0,0 +1,168 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// selfTestStreamID is the id that the components of a self test stream are
+// constructed under, which is never registered with the manager.
+const selfTestStreamID = "__selftest"
+
+// selfTestSuffix is appended to the message of a self test by the pipeline of
+// the stream, proving that the message was processed.
+const selfTestSuffix = " processed"
+
+// selfTestConf returns the config of a self test stream, which generates a
+// single message, processes it, and delivers it to an output that discards it.
+func selfTestConf(msg string) map[string]any {
+	return map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"count":    1,
+				"interval": "",
+				"mapping":  fmt.Sprintf("root = %q", msg),
+			},
+		},
+		"pipeline": map[string]any{
+			"processors": []any{
+				map[string]any{
+					"mapping": fmt.Sprintf("root = content().string() + %q", selfTestSuffix),
+				},
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}
+}
+
+// selfTestOutput wraps the output of a self test stream, reporting the
+// payloads of messages once they have been delivered by the output.
+type selfTestOutput struct {
+	output.Streamed
+	delivered chan string
+}
+
+func (o *selfTestOutput) Consume(ts <-chan message.Transaction) error {
+	fwd := make(chan message.Transaction)
+	if err := o.Streamed.Consume(fwd); err != nil {
+		return err
+	}
+	go func() {
+		defer close(fwd)
+		for t := range ts {
+			payload := t.Payload
+			fwd <- message.NewTransactionFunc(payload, func(ctx context.Context, err error) error {
+				if err == nil {
+					for _, p := range payload {
+						select {
+						case o.delivered <- string(p.AsBytes()):
+						default:
+						}
+					}
+				}
+				return t.Ack(ctx, err)
+			})
+		}
+	}()
+	return nil
+}
+
+// SelfTest verifies that the manager is able to process messages end to end by
+// running a transient stream that generates a known message, processes it and
+// delivers it to an output, which is then torn down. The stream is not visible
+// to other operations of the manager. Returns an error describing the failure
+// when the message does not traverse the stream before the context ends,
+// including when the components that the stream requires are not available.
+func (m *Type) SelfTest(ctx context.Context) error {
+	u4, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	msg := "selftest " + u4.String()
+
+	conf, err := m.streamConfigFromAny(selfTestConf(msg))
+	if err != nil {
+		return fmt.Errorf("failed to parse self test stream config: %w", err)
+	}
+
+	out := &selfTestOutput{delivered: make(chan string, 1)}
+	strm, err := stream.New(conf, m.manager.ForStream(selfTestStreamID), stream.OptWrapOutput(func(o output.Streamed) output.Streamed {
+		out.Streamed = o
+		return out
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to construct self test stream: %w", err)
+	}
+
+	var testErr error
+	select {
+	case got := <-out.delivered:
+		if want := msg + selfTestSuffix; got != want {
+			testErr = fmt.Errorf("self test message was delivered as %q, expected %q", got, want)
+		}
+	case <-ctx.Done():
+		testErr = errors.New("self test message was not delivered in time")
+	}
+
+	stopCtx, done := context.WithTimeout(context.Background(), m.APITimeout())
+	defer done()
+	if err := strm.Stop(stopCtx); err != nil && testErr == nil {
+		testErr = fmt.Errorf("failed to stop self test stream: %w", err)
+	}
+	return testErr
+}
+
+// HandleStreamsSelfTest is an http.HandleFunc for running (POST) a self test of
+// the manager, where a transient stream verifies that a message is generated,
+// processed and delivered. Responds with whether the test passed, along with
+// its duration and the reason for a failure, with a status of 503 when it
+// fails.
+func (m *Type) HandleStreamsSelfTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	ctx, done := context.WithTimeout(r.Context(), m.APITimeout())
+	defer done()
+
+	start := time.Now()
+	err := m.SelfTest(ctx)
+
+	body := struct {
+		Passed   bool   `json:"passed"`
+		Duration string `json:"duration"`
+		Error    string `json:"error,omitempty"`
+	}{
+		Passed:   err == nil,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		m.manager.Logger().Warn("Self test failed: %v\n", err)
+		body.Error = err.Error()
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !body.Passed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write(bodyBytes)
+}
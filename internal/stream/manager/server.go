@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+)
+
+var (
+	errServerListenerRequired = errors.New("either an address or a unix socket path must be provided")
+	errServerListenerConflict = errors.New("an address and a unix socket path cannot both be provided")
+)
+
+type httpServerConfig struct {
+	address string
+
+	socketPath string
+	socketPerm os.FileMode
+
+	tlsConf      *tls.Config
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	methods []string
+}
+
+// ServerOpt configures the server built by NewAPIServer.
+type ServerOpt func(*httpServerConfig)
+
+// ServerOptSetAddress sets the TCP address that the server listens on, where a
+// port of zero results in a port being chosen automatically, which can be
+// obtained with Addr once the server is created.
+func ServerOptSetAddress(address string) ServerOpt {
+	return func(c *httpServerConfig) {
+		c.address = address
+	}
+}
+
+// APIServer serves the endpoints of a stream manager on a listener, which
+// allows the API to be served independently of the service wide HTTP server.
+type APIServer struct {
+	listener   net.Listener
+	server     *http.Server
+	socketPath string
+}
+
+// NewAPIServer creates a listener that serves the endpoints of the stream
+// manager once Serve is called. Either a TCP address must be provided with
+// ServerOptSetAddress or a Unix domain socket with ServerOptSetUnixSocket, and
+// connections are served over TLS when a TLS config or certificate files are
+// provided. By default all endpoints are served, which can be limited to reads
+// or mutations with ServerOptReadOnly or ServerOptWriteOnly.
+func (m *Type) NewAPIServer(opts ...ServerOpt) (*APIServer, error) {
+	var conf httpServerConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.address != "" && conf.socketPath != "" {
+		return nil, errServerListenerConflict
+	}
+
+	tlsConf, err := m.serverTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var listener net.Listener
+	switch {
+	case conf.address != "":
+		listener, err = net.Listen("tcp", conf.address)
+	case conf.socketPath != "":
+		listener, err = listenUnixSocket(conf.socketPath, conf.socketPerm)
+	default:
+		err = errServerListenerRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIServer{
+		listener: listener,
+		server: &http.Server{
+			Handler:   m.routerForMethods(conf.methods),
+			TLSConfig: tlsConf,
+		},
+		socketPath: conf.socketPath,
+	}, nil
+}
+
+// Addr returns the address that the server is listening on.
+func (a *APIServer) Addr() net.Addr {
+	return a.listener.Addr()
+}
+
+// Serve accepts connections on the listener and blocks until the server is
+// shut down, in which case nil is returned, or fails.
+func (a *APIServer) Serve() error {
+	var err error
+	if a.server.TLSConfig != nil {
+		err = a.server.ServeTLS(a.listener, "", "")
+	} else {
+		err = a.server.Serve(a.listener)
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the server, removing the socket file when
+// serving over a Unix domain socket.
+func (a *APIServer) Shutdown(ctx context.Context) error {
+	err := a.server.Shutdown(ctx)
+	if a.socketPath == "" {
+		return err
+	}
+	if rmErr := os.Remove(a.socketPath); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"time"
+)
+
+// StreamOptStallTimeout sets a duration after which a running stream that has
+// not delivered any messages to its output is considered stalled, such as when
+// a processor hangs or the output is blocked downstream, in which case the
+// stream is marked as stalled and restarted. When whileInputActive is true a
+// stream is only considered stalled once its input has consumed messages that
+// the output has not made progress on for the duration, avoiding restarts of
+// streams that are legitimately idle. Otherwise any stream that delivers no
+// messages for the duration is restarted. A duration of zero disables stall
+// detection, and as with other options the stall timeout of a previous
+// version of the stream is retained unless overridden.
+func StreamOptStallTimeout(d time.Duration, whileInputActive bool) StreamOpt {
+	return func(s *StreamStatus) {
+		s.stallTimeout = d
+		s.stallWhileInputActive = whileInputActive
+	}
+}
+
+// StallTimeout returns the duration without output after which the stream is
+// restarted, which is zero when stall detection is disabled, and whether it
+// only applies whilst the input of the stream is active.
+func (s *StreamStatus) StallTimeout() (d time.Duration, whileInputActive bool) {
+	return s.stallTimeout, s.stallWhileInputActive
+}
+
+// IsStalled returns whether the stream has been detected as stalled and is
+// being restarted.
+func (s *StreamStatus) IsStalled() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.stalled
+}
+
+// trackProgress is called with each throughput sample of the stream, and
+// records when the output of the stream last made progress and since when the
+// input has consumed messages without the output making progress, which must
+// be called whilst holding the lock of the tracker.
+func (t *throughputTracker) trackProgress(sample throughputSample) {
+	if len(t.samples) == 0 {
+		return
+	}
+	prev := t.samples[len(t.samples)-1]
+	if sample.sent > prev.sent {
+		t.progressAt = sample.at
+		t.pendingSince = time.Time{}
+	} else if sample.received > prev.received && t.pendingSince.IsZero() {
+		t.pendingSince = sample.at
+	}
+}
+
+// stalledSince returns the time from which the stream has been stalled, which
+// is zero when the stream is not stalled.
+func (s *StreamStatus) stalledSince(startedAt time.Time) time.Time {
+	t := &s.throughput
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if s.stallWhileInputActive {
+		return t.pendingSince
+	}
+	if t.progressAt.After(startedAt) {
+		return t.progressAt
+	}
+	return startedAt
+}
+
+// resetProgress discards the progress recorded for the stream, such that a
+// restarted stream has the full stall timeout in which to make progress.
+func (s *StreamStatus) resetProgress(now time.Time) {
+	t := &s.throughput
+	t.mut.Lock()
+	t.progressAt = now
+	t.pendingSince = time.Time{}
+	t.mut.Unlock()
+}
+
+// checkStall is called from the health loop after the throughput of a stream
+// has been sampled, and marks a stream as stalled and restarts it once it has
+// made no progress for longer than its stall timeout.
+func (m *Type) checkStall(id string, status *StreamStatus, now time.Time) {
+	if status.stallTimeout <= 0 || status.origin == StreamOriginRegistered {
+		return
+	}
+
+	status.mut.Lock()
+	running, startedAt := status.strm != nil && !status.closed && !status.stalled, status.startedAt
+	status.mut.Unlock()
+	if !running {
+		return
+	}
+
+	since := status.stalledSince(startedAt)
+	if since.IsZero() || now.Sub(since) < status.stallTimeout {
+		return
+	}
+
+	b := &status.breaker
+	b.mut.Lock()
+	if b.open || b.restarting {
+		b.mut.Unlock()
+		return
+	}
+	b.restarting = true
+	b.mut.Unlock()
+
+	status.mut.Lock()
+	status.stalled = true
+	status.mut.Unlock()
+
+	m.manager.Logger().Warn("Restarting stream '%v' as it has stalled, having made no progress for %v\n", id, status.stallTimeout)
+	m.emitEvent(id, LifecycleEventHealth, status)
+	go m.restartStream(id, status, status.getStream())
+}
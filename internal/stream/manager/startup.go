@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"math/rand"
+	"time"
+)
+
+// OptSetStartupJitter sets a maximum delay applied to newly created streams
+// before their components are constructed and begin connecting, where each
+// stream is given a random delay up to the maximum. This staggers the start of
+// large numbers of streams created at once, such as when a manager is
+// restarted, which would otherwise connect to the same downstream systems
+// simultaneously. Updates to existing streams are not delayed. Errors
+// encountered when a delayed stream is started are logged rather than returned
+// by Create. A value of zero (the default) means streams are started
+// immediately.
+func OptSetStartupJitter(max time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.startupJitter = max
+	}
+}
+
+// startupDelay returns a random delay to apply before starting a new stream,
+// which is zero when no startup jitter is configured.
+func (m *Type) startupDelay() time.Duration {
+	if m.startupJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(m.startupJitter)))
+}
+
+// startStreamAfter starts a stream once a delay has elapsed, unless it is
+// deleted or the manager is stopped beforehand, which must be called whilst
+// holding the manager lock.
+func (m *Type) startStreamAfter(id string, wrapper *StreamStatus, delay time.Duration) {
+	cancelChan := make(chan struct{})
+	wrapper.pendingStart = cancelChan
+
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-cancelChan:
+			return
+		case <-m.shutSig:
+			return
+		}
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		if m.closed || m.streams[id] != wrapper || wrapper.pendingStart != cancelChan {
+			return
+		}
+		wrapper.pendingStart = nil
+
+		// The stream may have been started explicitly in the meantime.
+		if wrapper.getStream() != nil {
+			return
+		}
+		if err := m.startStream(id, wrapper); err != nil {
+			m.constructionFailed(id, wrapper, err)
+			return
+		}
+		m.emitEvent(id, LifecycleEventHealth, wrapper)
+	}()
+}
+
+// cancelPendingStart prevents a delayed start of a stream from taking place,
+// which must be called whilst holding the manager lock.
+func (s *StreamStatus) cancelPendingStart() {
+	if s.pendingStart != nil {
+		close(s.pendingStart)
+		s.pendingStart = nil
+	}
+}
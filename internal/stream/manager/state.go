@@ -0,0 +1,537 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// The persisted state of a manager is a header followed by a gzip compressed
+// JSON document. The header consists of a magic string, the version of the
+// format as a big endian uint16, and the CRC32 (IEEE) checksum of the
+// compressed document as a big endian uint32.
+const (
+	stateFileMagic   = "BNTO"
+	stateFileVersion = uint16(1)
+
+	stateFileHeaderLen = len(stateFileMagic) + 2 + 4
+)
+
+// ErrStateCorrupt is returned when a persisted state cannot be decoded due to
+// it being truncated, failing its checksum, or otherwise malformed.
+var ErrStateCorrupt = errors.New("state is corrupt")
+
+// persistedStream is the JSON representation of a stream within a state file.
+// Fields may be added to it without changing the version of the format
+// provided that their zero values, which older files lack, retain the
+// behaviour of streams persisted before them. Durations are written as strings
+// in the form accepted by time.ParseDuration, such as "1h30m".
+type persistedStream struct {
+	Config       any               `json:"config"`
+	MetricsLabel string            `json:"metrics_label,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	RateLimit    float64           `json:"rate_limit,omitempty"`
+	ConfigFormat string            `json:"config_format,omitempty"`
+	DependsOn    []string          `json:"depends_on,omitempty"`
+
+	MaxUptime             string `json:"max_uptime,omitempty"`
+	StallTimeout          string `json:"stall_timeout,omitempty"`
+	StallWhileInputActive bool   `json:"stall_while_input_active,omitempty"`
+	SkipGlobalProcessors  bool   `json:"skip_global_processors,omitempty"`
+	Origin                string `json:"origin,omitempty"`
+	OriginPath            string `json:"origin_path,omitempty"`
+}
+
+func formatPersistedDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func parsePersistedDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+type persistedState struct {
+	Streams map[string]persistedStream `json:"streams"`
+}
+
+func encodeState(s persistedState) ([]byte, error) {
+	docBytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := gzipCompress(docBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, stateFileHeaderLen, stateFileHeaderLen+len(compressed))
+	copy(b, stateFileMagic)
+	binary.BigEndian.PutUint16(b[len(stateFileMagic):], stateFileVersion)
+	binary.BigEndian.PutUint32(b[len(stateFileMagic)+2:], crc32.ChecksumIEEE(compressed))
+	return append(b, compressed...), nil
+}
+
+func decodeState(b []byte) (s persistedState, err error) {
+	if len(b) < stateFileHeaderLen || string(b[:len(stateFileMagic)]) != stateFileMagic {
+		return s, fmt.Errorf("%w: missing header", ErrStateCorrupt)
+	}
+
+	// Future versions of the format should be migrated here.
+	if version := binary.BigEndian.Uint16(b[len(stateFileMagic):]); version != stateFileVersion {
+		return s, fmt.Errorf("state format version %v is not supported", version)
+	}
+
+	payload := b[stateFileHeaderLen:]
+	if sum := binary.BigEndian.Uint32(b[len(stateFileMagic)+2:]); sum != crc32.ChecksumIEEE(payload) {
+		return s, fmt.Errorf("%w: checksum mismatch", ErrStateCorrupt)
+	}
+
+	_, gr, err := gzipDecompress(bytes.NewReader(payload), 0)
+	if err != nil {
+		return s, fmt.Errorf("%w: %v", ErrStateCorrupt, err)
+	}
+	docBytes, err := io.ReadAll(gr)
+	if err != nil {
+		return s, fmt.Errorf("%w: %v", ErrStateCorrupt, err)
+	}
+	if err := json.Unmarshal(docBytes, &s); err != nil {
+		return s, fmt.Errorf("%w: %v", ErrStateCorrupt, err)
+	}
+	return s, nil
+}
+
+//------------------------------------------------------------------------------
+
+// StoredStream is the config of a stream along with the options it was created
+// with, as persisted by a StateStore. Each field corresponds to a StreamOpt,
+// where the zero value of a field is the default of its option, such that
+// streams saved before a field was added are restored as they were created.
+// Implementations of StateStore must therefore persist every field, including
+// any that are added in future, in order for streams to be restored faithfully.
+//
+// The runtime state of a stream is not persisted, and streams are restored
+// unpaused, without any override of their log level, without the trace parent
+// of the request that created them, and with no record of restart failures or
+// quarantine.
+type StoredStream struct {
+	Config       stream.Config
+	MetricsLabel string
+	Labels       map[string]string
+	Description  string
+	RateLimit    float64
+	DependsOn    []string
+	MaxUptime    time.Duration
+
+	StallTimeout          time.Duration
+	StallWhileInputActive bool
+
+	// Whether the stream opted out of the global processors of the manager.
+	SkipGlobalProcessors bool
+
+	// Where the config of the stream was defined, as returned by
+	// StreamStatus.Origin.
+	Origin     string
+	OriginPath string
+
+	// The format that the config was submitted in, which is either json or
+	// yaml, or empty when unknown.
+	ConfigFormat string
+}
+
+// StateStore is a backend that persists the streams of a manager, allowing
+// them to be restored with RestoreState after a restart, along with the
+// options of each stream as described by StoredStream. The manager calls Save
+// whenever a stream is created or updated and Delete whenever a stream is
+// removed. Calls are made in the order of the changes they represent from a
+// single goroutine, and therefore implementations that write to shared storage
+// such as etcd or S3 do not block changes to streams.
+type StateStore interface {
+	// Load returns all persisted streams keyed by their ids. Streams that
+	// could not be loaded should result in an error, in which case the
+	// returned streams are still restored.
+	Load() (map[string]StoredStream, error)
+
+	// Save persists a stream, replacing any existing stream of the same id.
+	Save(id string, s StoredStream) error
+
+	// Delete removes a persisted stream.
+	Delete(id string) error
+}
+
+// OptSetStateStoreBackend sets a backend to which the configs and options of
+// all streams are persisted whenever a stream is created, updated or deleted,
+// allowing the streams to be restored with RestoreState after a restart. Any
+// changes not yet persisted when the manager is stopped are persisted before
+// Stop returns.
+func OptSetStateStoreBackend(store StateStore) func(*Type) {
+	return func(t *Type) {
+		t.stateStore = store
+	}
+}
+
+// OptSetStateFile sets a path at which the configs and options of all streams
+// are persisted whenever a stream is created, updated or deleted, allowing the
+// streams to be restored with RestoreState after a restart. This is the default
+// StateStore, and the state is written in the background.
+func OptSetStateFile(path string) func(*Type) {
+	return func(t *Type) {
+		t.statePath = path
+	}
+}
+
+type stateOp struct {
+	id     string
+	stream *StoredStream
+}
+
+// stateSync feeds changes to the streams of a manager to a StateStore. It
+// tracks streams from lifecycle events rather than reading them from the
+// manager, as events may be emitted whilst the manager is locked, and the
+// store is called in the background so that hooks never block on storage.
+type stateSync struct {
+	store StateStore
+	log   log.Modular
+
+	mut   sync.Mutex
+	queue []stateOp
+	dirty chan struct{}
+
+	flushMut sync.Mutex
+}
+
+func newStateSync(store StateStore, logger log.Modular) *stateSync {
+	return &stateSync{
+		store: store,
+		log:   logger,
+		dirty: make(chan struct{}, 1),
+	}
+}
+
+func (s *stateSync) add(e LifecycleEvent) {
+	op := stateOp{id: e.ID}
+	switch e.Type {
+	case LifecycleEventCreated, LifecycleEventUpdated:
+		// Registered streams have no config from which they could be
+		// restored.
+		if e.Status.origin == StreamOriginRegistered {
+			return
+		}
+		stallTimeout, stallWhileInputActive := e.Status.StallTimeout()
+		origin, originPath := e.Status.Origin()
+		op.stream = &StoredStream{
+			Config:                e.Status.Config(),
+			MetricsLabel:          e.Status.MetricsLabel(),
+			Labels:                e.Status.Labels(),
+			Description:           e.Status.Description(),
+			RateLimit:             e.Status.RateLimit(),
+			DependsOn:             e.Status.DependsOn(),
+			MaxUptime:             e.Status.MaxUptime(),
+			StallTimeout:          stallTimeout,
+			StallWhileInputActive: stallWhileInputActive,
+			SkipGlobalProcessors:  e.Status.skipGlobalProcessors,
+			Origin:                origin,
+			OriginPath:            originPath,
+			ConfigFormat:          e.Status.configFormat,
+		}
+	case LifecycleEventDeleted:
+	default:
+		return
+	}
+
+	s.mut.Lock()
+	s.queue = append(s.queue, op)
+	s.mut.Unlock()
+
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (s *stateSync) loop(shutSig <-chan struct{}) {
+	for {
+		select {
+		case <-s.dirty:
+			if err := s.flush(); err != nil {
+				s.log.Error("Failed to persist stream manager state: %v\n", err)
+			}
+		case <-shutSig:
+			return
+		}
+	}
+}
+
+// flush applies all queued changes to the store in order.
+func (s *stateSync) flush() error {
+	s.flushMut.Lock()
+	defer s.flushMut.Unlock()
+
+	s.mut.Lock()
+	queue := s.queue
+	s.queue = nil
+	s.mut.Unlock()
+
+	var errs []error
+	for _, op := range queue {
+		var err error
+		if op.stream != nil {
+			err = s.store.Save(op.id, *op.stream)
+		} else {
+			err = s.store.Delete(op.id)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stream '%v': %w", op.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+//------------------------------------------------------------------------------
+
+// fileStateStore is a StateStore that persists streams to a file on disk,
+// which is rewritten in full on each change.
+type fileStateStore struct {
+	path string
+	env  docs.Provider
+	log  log.Modular
+
+	mut     sync.Mutex
+	streams map[string]persistedStream
+}
+
+func newFileStateStore(path string, env docs.Provider, logger log.Modular) *fileStateStore {
+	return &fileStateStore{
+		path:    path,
+		env:     env,
+		log:     logger,
+		streams: map[string]persistedStream{},
+	}
+}
+
+// Load reads the streams persisted to the file, and returns no streams when
+// nothing has been persisted yet. A file that is corrupt, including one that
+// fails its checksum, is logged and moved aside with a `.corrupt` suffix.
+func (f *fileStateStore) Load() (map[string]StoredStream, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := decodeState(b)
+	if errors.Is(err, ErrStateCorrupt) {
+		corruptPath := f.path + ".corrupt"
+		f.log.Error("Stream manager state file '%v' is corrupt and will be moved to '%v', starting without any persisted streams: %v\n", f.path, corruptPath, err)
+		return nil, os.Rename(f.path, corruptPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	streams := make(map[string]StoredStream, len(s.Streams))
+	for id, ps := range s.Streams {
+		conf, err := streamConfigFromAny(f.env, ps.Config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load stream '%v': %w", id, err))
+			continue
+		}
+		maxUptime, err := parsePersistedDuration(ps.MaxUptime)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load stream '%v': max_uptime: %w", id, err))
+			continue
+		}
+		stallTimeout, err := parsePersistedDuration(ps.StallTimeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load stream '%v': stall_timeout: %w", id, err))
+			continue
+		}
+		f.streams[id] = ps
+		streams[id] = StoredStream{
+			Config:                conf,
+			MetricsLabel:          ps.MetricsLabel,
+			Labels:                ps.Labels,
+			Description:           ps.Description,
+			RateLimit:             ps.RateLimit,
+			DependsOn:             ps.DependsOn,
+			MaxUptime:             maxUptime,
+			StallTimeout:          stallTimeout,
+			StallWhileInputActive: ps.StallWhileInputActive,
+			SkipGlobalProcessors:  ps.SkipGlobalProcessors,
+			Origin:                ps.Origin,
+			OriginPath:            ps.OriginPath,
+			ConfigFormat:          ps.ConfigFormat,
+		}
+	}
+	return streams, errors.Join(errs...)
+}
+
+func (f *fileStateStore) Save(id string, s StoredStream) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.streams[id] = persistedStream{
+		Config:                s.Config.GetRawSource(),
+		MetricsLabel:          s.MetricsLabel,
+		Labels:                s.Labels,
+		Description:           s.Description,
+		RateLimit:             s.RateLimit,
+		ConfigFormat:          s.ConfigFormat,
+		DependsOn:             s.DependsOn,
+		MaxUptime:             formatPersistedDuration(s.MaxUptime),
+		StallTimeout:          formatPersistedDuration(s.StallTimeout),
+		StallWhileInputActive: s.StallWhileInputActive,
+		SkipGlobalProcessors:  s.SkipGlobalProcessors,
+		Origin:                s.Origin,
+		OriginPath:            s.OriginPath,
+	}
+	return f.write()
+}
+
+func (f *fileStateStore) Delete(id string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	delete(f.streams, id)
+	return f.write()
+}
+
+func (f *fileStateStore) write() error {
+	b, err := encodeState(persistedState{Streams: f.streams})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash mid-write can't leave a
+	// partially written state behind.
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+//------------------------------------------------------------------------------
+
+// RestoreState creates all streams persisted to the StateStore configured with
+// OptSetStateStoreBackend or OptSetStateFile, and does nothing when no state
+// has been persisted yet.
+//
+// A state file that is corrupt, including one that fails its checksum, is
+// logged and moved aside with a `.corrupt` suffix, and the manager starts with
+// no streams. An error is returned if the state cannot be loaded, or if any of
+// the persisted streams fail to be created, in which case the remaining streams
+// are still created. Streams registered with RegisterStream are never
+// persisted, as they have no config from which they could be restored.
+func (m *Type) RestoreState() error {
+	if m.state == nil {
+		return errors.New("a state store has not been configured")
+	}
+
+	streams, err := m.state.store.Load()
+	var errs []error
+	if err != nil {
+		if len(streams) == 0 {
+			return err
+		}
+		errs = append(errs, err)
+	}
+
+	ids := make([]string, 0, len(streams))
+	for id := range streams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		s := streams[id]
+		if s.Origin == StreamOriginRegistered {
+			continue
+		}
+		opts := []StreamOpt{
+			StreamOptMetricsLabel(s.MetricsLabel),
+			StreamOptLabels(s.Labels),
+			StreamOptDescription(s.Description),
+			StreamOptRateLimit(s.RateLimit),
+			streamOptConfigFormat(s.ConfigFormat),
+			StreamOptDependsOn(s.DependsOn...),
+			StreamOptMaxUptime(s.MaxUptime),
+			StreamOptStallTimeout(s.StallTimeout, s.StallWhileInputActive),
+			StreamOptGlobalProcessors(!s.SkipGlobalProcessors),
+		}
+		if s.Origin == StreamOriginDirectory {
+			opts = append(opts, StreamOptOriginDirectory(s.OriginPath))
+		}
+		if err := m.Create(id, s.Config, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore stream '%v': %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Type) streamConfigFromAny(v any) (conf stream.Config, err error) {
+	return streamConfigFromAny(m.manager.Environment(), v)
+}
+
+func streamConfigFromAny(env docs.Provider, v any) (conf stream.Config, err error) {
+	var node yaml.Node
+	if err = node.Encode(v); err != nil {
+		return
+	}
+
+	var migrated bool
+	if migrated, err = stream.MigrateYAML(&node); err != nil {
+		return
+	}
+	if migrated {
+		// The raw source of a migrated config is of the current layout.
+		v = nil
+		if err = node.Decode(&v); err != nil {
+			return
+		}
+	}
+
+	var pConf *docs.ParsedConfig
+	if pConf, err = stream.Spec().ParsedConfigFromAny(&node); err != nil {
+		return
+	}
+	return stream.FromParsed(env, pConf, v)
+}
@@ -0,0 +1,335 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/log"
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+func TestStateEncoding(t *testing.T) {
+	s := persistedState{
+		Streams: map[string]persistedStream{
+			"foo": {
+				Config:       map[string]any{"input": map[string]any{"generate": map[string]any{"mapping": "root = 1"}}},
+				MetricsLabel: "bar",
+				Labels:       map[string]string{"team": "a"},
+				Description:  "Generates numbers",
+			},
+		},
+	}
+
+	b, err := encodeState(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, stateFileMagic, string(b[:len(stateFileMagic)]))
+	// The document following the header is gzip compressed.
+	assert.Equal(t, []byte{0x1f, 0x8b}, b[stateFileHeaderLen:stateFileHeaderLen+2])
+
+	decoded, err := decodeState(b)
+	require.NoError(t, err)
+	assert.Equal(t, s, decoded)
+
+	corrupted := append([]byte(nil), b...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	_, err = decodeState(corrupted)
+	assert.ErrorIs(t, err, ErrStateCorrupt)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	_, err = decodeState(b[:stateFileHeaderLen-1])
+	assert.ErrorIs(t, err, ErrStateCorrupt)
+
+	futureVersion := append([]byte(nil), b...)
+	futureVersion[len(stateFileMagic)+1]++
+	_, err = decodeState(futureVersion)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrStateCorrupt)
+	assert.Contains(t, err.Error(), "not supported")
+}
+
+func TestStateSchemaMigration(t *testing.T) {
+	conf, err := streamConfigFromAny(bundle.GlobalEnvironment, map[string]any{
+		"schema_version": 1,
+		"processors": []any{
+			map[string]any{"mapping": "root = content()"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, conf.Pipeline.Processors, 1)
+	assert.Equal(t, "mapping", conf.Pipeline.Processors[0].Type)
+	assert.NotContains(t, conf.GetRawSource(), "schema_version")
+
+	_, err = streamConfigFromAny(bundle.GlobalEnvironment, map[string]any{
+		"schema_version": 99,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99 is newer")
+}
+
+func TestStateFilePersistence(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+
+	// Restoring before any state exists is a no-op.
+	require.NoError(t, mgr.RestoreState())
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t), StreamOptLabels(map[string]string{"team": "a"}), StreamOptDescription("Feeds the a team")))
+	require.NoError(t, mgr.Create("bar", harmlessConf(t), StreamOptMetricsLabel("shared")))
+	require.NoError(t, mgr.Create("baz", harmlessConf(t)))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, mgr.Delete(ctx, "baz"))
+	require.NoError(t, mgr.Stop(ctx))
+
+	res, err = bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr = New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+	require.NoError(t, mgr.RestoreState())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "a"}, info.Labels())
+	assert.Equal(t, "Feeds the a team", info.Description())
+	assert.Equal(t, "generate", info.Config().Input.Type)
+
+	info, err = mgr.Read("bar")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", info.MetricsLabel())
+
+	_, err = mgr.Read("baz")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestStateFileRoundTrip(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+	require.NoError(t, mgr.Create("foo", harmlessConf(t),
+		StreamOptMetricsLabel("shared"),
+		StreamOptLabels(map[string]string{"team": "a"}),
+		StreamOptDescription("Feeds the a team"),
+		StreamOptRateLimit(10),
+		streamOptConfigFormat("yaml"),
+		StreamOptDependsOn("bar"),
+		StreamOptMaxUptime(time.Hour),
+		StreamOptStallTimeout(time.Minute*5, true),
+		StreamOptGlobalProcessors(false),
+		StreamOptOriginDirectory("/etc/bento/streams/foo.yaml"),
+	))
+	require.NoError(t, mgr.Stop(ctx))
+
+	res, err = bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr = New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+	require.NoError(t, mgr.RestoreState())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", info.MetricsLabel())
+	assert.Equal(t, map[string]string{"team": "a"}, info.Labels())
+	assert.Equal(t, "Feeds the a team", info.Description())
+	assert.Equal(t, 10.0, info.RateLimit())
+	assert.Equal(t, "yaml", info.configFormat)
+	assert.Equal(t, []string{"bar"}, info.DependsOn())
+	assert.Equal(t, time.Hour, info.MaxUptime())
+
+	stallTimeout, whileInputActive := info.StallTimeout()
+	assert.Equal(t, time.Minute*5, stallTimeout)
+	assert.True(t, whileInputActive)
+	assert.True(t, info.skipGlobalProcessors)
+
+	origin, originPath := info.Origin()
+	assert.Equal(t, StreamOriginDirectory, origin)
+	assert.Equal(t, "/etc/bento/streams/foo.yaml", originPath)
+
+	// Streams created without options are restored with the defaults.
+	info, err = mgr.Read("bar")
+	require.NoError(t, err)
+	assert.Zero(t, info.MaxUptime())
+	stallTimeout, whileInputActive = info.StallTimeout()
+	assert.Zero(t, stallTimeout)
+	assert.False(t, whileInputActive)
+	assert.False(t, info.skipGlobalProcessors)
+	origin, originPath = info.Origin()
+	assert.Equal(t, StreamOriginAPI, origin)
+	assert.Empty(t, originPath)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestStateFileCorrupt(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Stop(ctx))
+
+	b, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	b[len(b)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(statePath, b, 0o644))
+
+	logConf := log.NewConfig()
+	logConf.AddTimeStamp = false
+	logConf.Format = "logfmt"
+
+	var logBuf bytes.Buffer
+	logger, err := log.New(&logBuf, ifs.OS(), logConf)
+	require.NoError(t, err)
+
+	res, err = bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetLogger(logger))
+	require.NoError(t, err)
+
+	mgr = New(res, OptAPIEnabled(false), OptSetStateFile(statePath))
+	require.NoError(t, mgr.RestoreState())
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	assert.Contains(t, logBuf.String(), "checksum mismatch")
+
+	_, err = os.Stat(statePath)
+	assert.True(t, os.IsNotExist(err))
+
+	corruptBytes, err := os.ReadFile(statePath + ".corrupt")
+	require.NoError(t, err)
+	assert.Equal(t, b, corruptBytes)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+type fakeStateOp struct {
+	op, id string
+	label  string
+}
+
+// fakeStateStore is an in-memory StateStore that records the calls made to it.
+type fakeStateStore struct {
+	mut     sync.Mutex
+	streams map[string]StoredStream
+	ops     []fakeStateOp
+}
+
+func (f *fakeStateStore) Load() (map[string]StoredStream, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	return maps.Clone(f.streams), nil
+}
+
+func (f *fakeStateStore) Save(id string, s StoredStream) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.streams[id] = s
+	f.ops = append(f.ops, fakeStateOp{op: "save", id: id, label: s.MetricsLabel})
+	return nil
+}
+
+func (f *fakeStateStore) Delete(id string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	delete(f.streams, id)
+	f.ops = append(f.ops, fakeStateOp{op: "delete", id: id})
+	return nil
+}
+
+func (f *fakeStateStore) getOps() []fakeStateOp {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	return slices.Clone(f.ops)
+}
+
+func TestStateStoreBackend(t *testing.T) {
+	store := &fakeStateStore{streams: map[string]StoredStream{}}
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetStateStoreBackend(store))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t), StreamOptMetricsLabel("first"), StreamOptMaxUptime(time.Hour)))
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+
+	// Registered streams have no config and are therefore not persisted.
+	strm, err := stream.NewFromComponents(res.ForStream("baz"), &mock.Input{TChan: make(chan message.Transaction)}, nil, &mock.OutputChanneled{})
+	require.NoError(t, err)
+	require.NoError(t, mgr.RegisterStream("baz", strm))
+	require.NoError(t, mgr.Update(ctx, "foo", harmlessConf(t), StreamOptMetricsLabel("second"), StreamOptStallTimeout(time.Minute, false)))
+	require.NoError(t, mgr.Delete(ctx, "bar"))
+
+	assert.Eventually(t, func() bool {
+		return len(store.getOps()) == 4
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, []fakeStateOp{
+		{op: "save", id: "foo", label: "first"},
+		{op: "save", id: "bar"},
+		{op: "save", id: "foo", label: "second"},
+		{op: "delete", id: "bar"},
+	}, store.getOps())
+
+	// Stopping the manager does not remove the persisted streams.
+	require.NoError(t, mgr.Stop(ctx))
+	assert.Len(t, store.getOps(), 4)
+
+	res, err = bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr = New(res, OptAPIEnabled(false), OptSetStateStoreBackend(store))
+	require.NoError(t, mgr.RestoreState())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "second", info.MetricsLabel())
+	assert.Equal(t, time.Hour, info.MaxUptime())
+	stallTimeout, _ := info.StallTimeout()
+	assert.Equal(t, time.Minute, stallTimeout)
+
+	_, err = mgr.Read("bar")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	_, err = mgr.Read("baz")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
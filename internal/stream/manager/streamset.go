@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+var errStreamSetTooLarge = errors.New("stream set exceeds the maximum number of streams")
+
+// decodeStreamSet reads a set of stream configs keyed by stream ids from the
+// body of a request, calling fn with each stream as it is decoded, without
+// buffering the body as a whole. JSON bodies are decoded incrementally, where
+// only the raw form of the stream currently being decoded is held in memory,
+// and therefore fn is called before the remainder of the body has been read.
+// YAML bodies are decoded as a whole document, with each stream released once
+// fn returns.
+func decodeStreamSet(r *http.Request, fn func(id string, node *yaml.Node) error) error {
+	body := bufio.NewReader(r.Body)
+	if isJSONStreamSet(r, body) {
+		return decodeJSONStreamSet(body, fn)
+	}
+	return decodeYAMLStreamSet(body, fn)
+}
+
+// isJSONStreamSet returns whether a stream set is JSON according to the
+// Content-Type header of the request, falling back to peeking at the first
+// significant character of the body.
+func isJSONStreamSet(r *http.Request, body *bufio.Reader) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		if mediaType == "application/json" {
+			return true
+		}
+		if _, isYAML := yamlMediaTypes[mediaType]; isYAML {
+			return false
+		}
+	}
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		_ = body.UnreadByte()
+		return b == '{'
+	}
+}
+
+func decodeJSONStreamSet(body io.Reader, fn func(id string, node *yaml.Node) error) error {
+	lines := &lineCountingReader{r: body}
+	dec := json.NewDecoder(lines)
+
+	tok, err := dec.Token()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("expected an object of stream configs keyed by stream ids")
+	}
+
+	seen := map[string]struct{}{}
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+		id, _ := tok.(string)
+		if _, exists := seen[id]; exists {
+			return fmt.Errorf("stream '%v' is defined more than once", id)
+		}
+		seen[id] = struct{}{}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("stream '%v': %w", id, err)
+		}
+
+		// JSON is parsed as YAML in order for lint errors to reference the
+		// lines of the request body, as they would when the body is parsed as
+		// a whole.
+		var node yaml.Node
+		if err := yaml.Unmarshal(raw, &node); err != nil {
+			return fmt.Errorf("stream '%v': %w", id, err)
+		}
+		shiftNodeLines(&node, lines.lineAt(dec.InputOffset()-int64(len(raw)))-1)
+
+		if err := fn(id, &node); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// lineCountingReader tracks the line numbers of a reader by offset, where
+// offsets must be queried in ascending order. Only the offsets of line breaks
+// that have been read but not yet queried past are retained, and therefore the
+// memory used is bounded by how far the consumer reads ahead.
+type lineCountingReader struct {
+	r      io.Reader
+	read   int64
+	breaks []int64
+	passed int
+}
+
+func (l *lineCountingReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			l.breaks = append(l.breaks, l.read+int64(i))
+		}
+	}
+	l.read += int64(n)
+	return n, err
+}
+
+// lineAt returns the line number, starting from one, of a byte offset.
+func (l *lineCountingReader) lineAt(offset int64) int {
+	i := 0
+	for i < len(l.breaks) && l.breaks[i] < offset {
+		i++
+	}
+	l.passed += i
+	l.breaks = l.breaks[i:]
+	return l.passed + 1
+}
+
+func shiftNodeLines(node *yaml.Node, by int) {
+	node.Line += by
+	for _, c := range node.Content {
+		shiftNodeLines(c, by)
+	}
+}
+
+func decodeYAMLStreamSet(body io.Reader, fn func(id string, node *yaml.Node) error) error {
+	var root yaml.Node
+	if err := yaml.NewDecoder(body).Decode(&root); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	set := &root
+	if set.Kind == yaml.DocumentNode && len(set.Content) > 0 {
+		set = set.Content[0]
+	}
+	if set.Kind == yaml.ScalarNode && set.Tag == "!!null" {
+		return nil
+	}
+	if set.Kind != yaml.MappingNode {
+		return errors.New("expected a map of stream configs keyed by stream ids")
+	}
+
+	seen := map[string]struct{}{}
+	for i := 0; i < len(set.Content)-1; i += 2 {
+		id := set.Content[i].Value
+		if _, exists := seen[id]; exists {
+			return fmt.Errorf("stream '%v' is defined more than once", id)
+		}
+		seen[id] = struct{}{}
+
+		if err := fn(id, set.Content[i+1]); err != nil {
+			return err
+		}
+		set.Content[i], set.Content[i+1] = nil, nil
+	}
+	return nil
+}
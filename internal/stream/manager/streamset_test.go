@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+)
+
+// generatedStreamSet is a reader of a JSON stream set that is generated as it
+// is read, recording the number of bytes consumed and the offset at which each
+// stream ends.
+type generatedStreamSet struct {
+	n, next  int
+	pending  []byte
+	consumed int
+	ends     []int
+	closed   bool
+}
+
+func (g *generatedStreamSet) Read(p []byte) (int, error) {
+	for len(g.pending) == 0 {
+		switch {
+		case g.closed:
+			return 0, io.EOF
+		case g.next == g.n:
+			g.pending, g.closed = []byte("}"), true
+		default:
+			prefix := ","
+			if g.next == 0 {
+				prefix = "{"
+			}
+			g.pending = []byte(fmt.Sprintf(`%v"stream_%v":{"input":{"generate":{"mapping":"root = %v","interval":"1s"}},"output":{"drop":{}}}`, prefix, g.next, g.next))
+			g.ends = append(g.ends, g.consumed+len(g.pending))
+			g.next++
+		}
+	}
+	n := copy(p, g.pending)
+	g.pending = g.pending[n:]
+	g.consumed += n
+	return n, nil
+}
+
+func TestDecodeStreamSetIncremental(t *testing.T) {
+	body := &generatedStreamSet{n: 10000}
+	req := httptest.NewRequest("POST", "/streams", body)
+	req.Header.Set("Content-Type", "application/json")
+
+	var ids int
+	maxConsumedAhead := 0
+	require.NoError(t, decodeStreamSet(req, func(id string, node *yaml.Node) error {
+		assert.Equal(t, fmt.Sprintf("stream_%v", ids), id)
+
+		// The body consumed beyond the streams decoded so far is bounded by
+		// the read buffers rather than growing with the size of the set.
+		if ahead := body.consumed - body.ends[ids]; ahead > maxConsumedAhead {
+			maxConsumedAhead = ahead
+		}
+		ids++
+		return nil
+	}))
+	assert.Equal(t, 10000, ids)
+	assert.Greater(t, body.consumed, 900_000)
+	assert.Less(t, maxConsumedAhead, 64*1024)
+}
+
+func TestDecodeStreamSetFormats(t *testing.T) {
+	for name, test := range map[string]struct {
+		contentType string
+		body        string
+		ids         []string
+		errContains string
+	}{
+		"yaml": {
+			body: "foo:\n  input:\n    generate:\n      mapping: 'root = 1'\nbar: {}\n",
+			ids:  []string{"foo", "bar"},
+		},
+		"json sniffed": {
+			body: ` {"foo":{},"bar":{}}`,
+			ids:  []string{"foo", "bar"},
+		},
+		"empty": {
+			body: "",
+		},
+		"json duplicate": {
+			contentType: "application/json",
+			body:        `{"foo":{},"foo":{}}`,
+			ids:         []string{"foo"},
+			errContains: "more than once",
+		},
+		"yaml duplicate": {
+			body:        "foo: {}\nfoo: {}\n",
+			ids:         []string{"foo"},
+			errContains: "more than once",
+		},
+		"not a map": {
+			body:        "- foo\n",
+			errContains: "expected a map",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/streams", strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			var ids []string
+			err := decodeStreamSet(req, func(id string, node *yaml.Node) error {
+				ids = append(ids, id)
+				return nil
+			})
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+}
+
+func TestSetStreamsLargeSet(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	// Streams are registered without being run in order to keep the test
+	// light.
+	mgr := New(res, OptSetManualStart(true))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	body := &generatedStreamSet{n: 1000}
+	req := httptest.NewRequest("POST", "/streams", body)
+	req.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	mgr.HandleStreamsCRUD(response, req)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	assert.Len(t, mgr.snapshotStreams(), 1000)
+	info, err := mgr.Read("stream_999")
+	require.NoError(t, err)
+	assert.Equal(t, "generate", info.Config().Input.Type)
+}
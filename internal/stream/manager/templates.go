@@ -0,0 +1,218 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// streamTemplate is a skeleton stream config intended as a starting point for
+// new streams, where placeholder values are expected to be overridden.
+type streamTemplate struct {
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+	config  string
+}
+
+var streamTemplates = []streamTemplate{
+	{
+		Name:    "generate-to-stdout",
+		Summary: "Generates a message every second and writes it to stdout, useful for experimenting with processors.",
+		config: `
+input:
+  generate:
+    interval: 1s
+    mapping: 'root = { "id": uuid_v4(), "created_at": now() }'
+pipeline:
+  processors:
+    - mutation: root.processed_at = now()
+output:
+  stdout: {}
+`,
+	},
+	{
+		Name:    "http-to-stdout",
+		Summary: "Receives messages sent as HTTP POST requests and writes them to stdout.",
+		config: `
+input:
+  http_server:
+    path: /post
+    allowed_verbs: [ POST ]
+output:
+  stdout: {}
+`,
+	},
+	{
+		Name:    "kafka-to-s3",
+		Summary: "Consumes messages from Kafka topics and archives them in batches as objects within an S3 bucket.",
+		config: `
+input:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topics: [ example_topic ]
+    consumer_group: example_group
+output:
+  aws_s3:
+    bucket: example-bucket
+    path: '${! meta("kafka_topic") }/${! timestamp_unix_nano() }.jsonl'
+    batching:
+      count: 100
+      period: 10s
+      processors:
+        - archive:
+            format: lines
+`,
+	},
+}
+
+func getStreamTemplate(name string) (streamTemplate, bool) {
+	for _, t := range streamTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return streamTemplate{}, false
+}
+
+// applyStreamTemplate returns the config of a named template with a partial
+// config merged on top of it. The partial config may be nil, in which case the
+// template is returned as is.
+func (m *Type) applyStreamTemplate(name string, partial *yaml.Node) (*yaml.Node, error) {
+	tmpl, exists := getStreamTemplate(name)
+	if !exists {
+		return nil, fmt.Errorf("stream template '%v' does not exist", name)
+	}
+
+	node, err := docs.UnmarshalYAML([]byte(tmpl.config))
+	if err != nil {
+		return nil, err
+	}
+	if partial == nil {
+		return node, nil
+	}
+
+	var tRoot, pRoot any
+	if err := node.Decode(&tRoot); err != nil {
+		return nil, err
+	}
+	if err := partial.Decode(&pRoot); err != nil {
+		return nil, err
+	}
+
+	var merged yaml.Node
+	if err := merged.Encode(m.mergeTemplateValue(tRoot, pRoot)); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// mergeTemplateValue merges a value of a partial config on top of the
+// corresponding value of a template. Objects are merged recursively and all
+// other values are replaced, except that an object naming a different type of
+// component than the template replaces the component config of the template
+// whilst retaining common fields such as labels.
+func (m *Type) mergeTemplateValue(tmpl, partial any) any {
+	tMap, tIsMap := tmpl.(map[string]any)
+	pMap, pIsMap := partial.(map[string]any)
+	if !tIsMap || !pIsMap {
+		return partial
+	}
+
+	replacesType := false
+	for k := range pMap {
+		if _, exists := tMap[k]; !exists && m.isComponentName(k) {
+			replacesType = true
+		}
+	}
+
+	merged := make(map[string]any, len(tMap)+len(pMap))
+	for k, v := range tMap {
+		if _, exists := pMap[k]; !exists && replacesType && m.isComponentName(k) {
+			continue
+		}
+		merged[k] = v
+	}
+	for k, v := range pMap {
+		if t, exists := merged[k]; exists {
+			merged[k] = m.mergeTemplateValue(t, v)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func (m *Type) isComponentName(name string) bool {
+	env := m.manager.Environment()
+	for _, t := range docs.Types() {
+		if _, exists := env.GetDocs(name, t); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleStreamTemplates is an http.HandleFunc for listing the built-in stream
+// templates.
+func (m *Type) HandleStreamTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	templates := append([]streamTemplate(nil), streamTemplates...)
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	resBytes, err := json.Marshal(templates)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+// HandleStreamTemplate is an http.HandleFunc for obtaining the skeleton config
+// of a built-in stream template, which is YAML unless JSON is requested via
+// the Accept header.
+func (m *Type) HandleStreamTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	tmpl, exists := getStreamTemplate(mux.Vars(r)["name"])
+	if !exists {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	format, contentType := responseConfigFormat(r, configFormatYAML)
+	if format == configFormatYAML {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(strings.TrimPrefix(tmpl.config, "\n")))
+		return
+	}
+
+	var conf any
+	if err := yaml.Unmarshal([]byte(tmpl.config), &conf); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	resBytes, err := json.Marshal(conf)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(resBytes)
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+const defaultThroughputWindow = 30 * time.Second
+
+type throughputSample struct {
+	at       time.Time
+	received int64
+	sent     int64
+}
+
+// throughputTracker retains periodic samples of the message counters of a
+// stream, from which rates are calculated over a sliding window. Each version
+// of a stream has its own metrics and therefore its own tracker.
+type throughputTracker struct {
+	mut     sync.Mutex
+	samples []throughputSample
+
+	progressAt   time.Time
+	pendingSince time.Time
+}
+
+// OptSetThroughputWindow sets the length of the sliding window over which the
+// input and output message rates of each stream are calculated. Samples are
+// taken at the health check interval. The default is thirty seconds.
+func OptSetThroughputWindow(d time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.throughputWindow = d
+	}
+}
+
+// sampleThroughput records the current message counters of the stream and
+// discards samples that have fallen out of the window, retaining the most
+// recent of those as the baseline for calculating rates.
+func (s *StreamStatus) sampleThroughput(now time.Time, window time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+
+	sample := throughputSample{at: now}
+	for k, v := range s.metrics.GetCounters() {
+		switch name, _, _ := metrics.ReverseLabelledPath(k); name {
+		case "input_received":
+			sample.received += v
+		case "output_sent":
+			sample.sent += v
+		}
+	}
+
+	t := &s.throughput
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.trackProgress(sample)
+	t.samples = append(t.samples, sample)
+
+	cutoff := now.Add(-window)
+	drop := 0
+	for drop < len(t.samples)-1 && !t.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	t.samples = t.samples[drop:]
+}
+
+// Throughput returns the rates, in messages per second, at which the stream has
+// consumed messages from its input and delivered messages to its output over
+// the throughput window. An input rate that exceeds the output rate indicates
+// that messages are building up within the stream.
+func (s *StreamStatus) Throughput() (inputRate, outputRate float64) {
+	t := &s.throughput
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if len(t.samples) < 2 {
+		return 0, 0
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return float64(last.received-first.received) / elapsed, float64(last.sent-first.sent) / elapsed
+}
+
+type streamRate struct {
+	Input  float64 `json:"input" yaml:"input"`
+	Output float64 `json:"output" yaml:"output"`
+}
+
+func (s *StreamStatus) rate() streamRate {
+	inputRate, outputRate := s.Throughput()
+	return streamRate{
+		Input:  inputRate,
+		Output: outputRate,
+	}
+}
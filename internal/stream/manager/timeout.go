@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var errAPITimeoutInvalid = errors.New("api timeout must be positive")
+
+// APITimeout returns the default timeout for HTTP API requests that modify
+// streams, as set with OptSetAPITimeout or SetAPITimeout.
+func (m *Type) APITimeout() time.Duration {
+	m.apiTimeoutMut.Lock()
+	defer m.apiTimeoutMut.Unlock()
+	return m.apiTimeout
+}
+
+// SetAPITimeout changes the default timeout for HTTP API requests that modify
+// streams whilst the manager is running, such as when the timeout set with
+// OptSetAPITimeout turns out to be too short for slow shutdowns. The timeout
+// applies to requests made after the change, and requests already in flight
+// keep the timeout that they began with. Returns an error if the timeout is
+// not positive.
+func (m *Type) SetAPITimeout(tout time.Duration) error {
+	if tout <= 0 {
+		return errAPITimeoutInvalid
+	}
+	m.apiTimeoutMut.Lock()
+	m.apiTimeout = tout
+	m.apiTimeoutMut.Unlock()
+	m.manager.Logger().Info("Stream manager API timeout set to %v\n", tout)
+	return nil
+}
+
+type apiTimeoutBody struct {
+	Timeout string `json:"timeout"`
+}
+
+// HandleAPITimeout is an http.HandleFunc for reading (GET) or changing (PUT)
+// the default timeout of API requests that modify streams, as an object of the
+// form {"timeout":"30s"}.
+func (m *Type) HandleAPITimeout(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Error("API timeout Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("API timeout request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	switch r.Method {
+	case "GET":
+		var resBytes []byte
+		if resBytes, serverErr = json.Marshal(apiTimeoutBody{
+			Timeout: m.APITimeout().String(),
+		}); serverErr != nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+	case "PUT":
+		var reqBytes []byte
+		if reqBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		var body apiTimeoutBody
+		if requestErr = json.Unmarshal(reqBytes, &body); requestErr != nil {
+			return
+		}
+		var tout time.Duration
+		if tout, requestErr = time.ParseDuration(body.Timeout); requestErr != nil {
+			requestErr = fmt.Errorf("failed to parse timeout: %w", requestErr)
+			return
+		}
+		requestErr = m.SetAPITimeout(tout)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+}
@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var errServerTLSCertRequired = errors.New("a server certificate must be provided for TLS, either as certificate and key files or within the TLS config")
+
+// ServerOptSetTLSConfig sets a TLS config from which the TLS config of the
+// server is derived. The provided config is not modified.
+func ServerOptSetTLSConfig(conf *tls.Config) ServerOpt {
+	return func(c *httpServerConfig) {
+		c.tlsConf = conf
+	}
+}
+
+// ServerOptSetTLSCertFiles sets the paths of a PEM encoded certificate and key
+// that the server presents to clients. The files are read again whenever either
+// of them is modified, which is checked at most once per second, allowing
+// certificates to be rotated without a restart.
+func ServerOptSetTLSCertFiles(certFile, keyFile string) ServerOpt {
+	return func(c *httpServerConfig) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// ServerOptRequireClientCerts enables mutual TLS, where clients must present a
+// certificate signed by one of the PEM encoded certificate authorities within
+// the provided file.
+func ServerOptRequireClientCerts(caFile string) ServerOpt {
+	return func(c *httpServerConfig) {
+		c.clientCAFile = caFile
+	}
+}
+
+// serverTLSConfig returns the TLS config of a server, which is nil when
+// neither a TLS config nor certificate files are provided.
+func (m *Type) serverTLSConfig(conf httpServerConfig) (*tls.Config, error) {
+	if conf.tlsConf == nil && conf.certFile == "" && conf.keyFile == "" && conf.clientCAFile == "" {
+		return nil, nil
+	}
+
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf.tlsConf != nil {
+		tlsConf = conf.tlsConf.Clone()
+	}
+
+	if conf.clientCAFile != "" {
+		caBytes, err := os.ReadFile(conf.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no certificates found within client CA file '%v'", conf.clientCAFile)
+		}
+		tlsConf.ClientCAs = pool
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if conf.certFile != "" || conf.keyFile != "" {
+		reloader, err := newCertReloader(conf.certFile, conf.keyFile, m.manager.Logger())
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.Certificates = nil
+		tlsConf.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return reloader.certificate(), nil
+		}
+	} else if len(tlsConf.Certificates) == 0 && tlsConf.GetCertificate == nil && tlsConf.GetConfigForClient == nil {
+		return nil, errServerTLSCertRequired
+	}
+	return tlsConf, nil
+}
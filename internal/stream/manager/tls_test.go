@@ -0,0 +1,238 @@
+package manager_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream/manager"
+)
+
+// selfSignedCert generates a PEM encoded certificate and key that is valid for
+// localhost, both as a server and a client, and acts as its own authority.
+func selfSignedCert(serial int64) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+func writeSelfSignedCert(t testing.TB, dir, name string, serial int64) (certPath, keyPath string, certPEM []byte) {
+	t.Helper()
+
+	certPEM, keyPEM, err := selfSignedCert(serial)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0o600))
+	return
+}
+
+func tlsTestClient(t testing.TB, rootPEM []byte, clientCerts ...tls.Certificate) *http.Client {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(rootPEM))
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs:      pool,
+				ServerName:   "localhost",
+				Certificates: clientCerts,
+			},
+		},
+	}
+}
+
+// startTestAPIServer serves an APIServer until the test ends, returning the
+// URL that it is served at.
+func startTestAPIServer(t testing.TB, srv *manager.APIServer, scheme string) string {
+	t.Helper()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve()
+	}()
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, srv.Shutdown(ctx))
+		assert.NoError(t, <-serveErr)
+	})
+	return scheme + "://" + srv.Addr().String()
+}
+
+func TestAPIServerTLS(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptAPIEnabled(false))
+
+	dir := t.TempDir()
+	certPath, keyPath, firstPEM := writeSelfSignedCert(t, dir, "server", 1)
+
+	srv, err := mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetTLSCertFiles(certPath, keyPath),
+	)
+	require.NoError(t, err)
+
+	url := startTestAPIServer(t, srv, "https")
+
+	client := tlsTestClient(t, firstPEM)
+	res2, err := client.Post(url+"/streams/foo", "application/yaml", strings.NewReader(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  drop: {}
+`))
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+
+	res2, err = client.Get(url + "/streams/foo")
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+
+	// Replacing the certificate files results in the new certificate being
+	// served to new connections once the files are next checked.
+	_, _, secondPEM := writeSelfSignedCert(t, dir, "server", 2)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certPath, future, future))
+	require.NoError(t, os.Chtimes(keyPath, future, future))
+
+	secondClient := tlsTestClient(t, secondPEM)
+	assert.Eventually(t, func() bool {
+		res2, err := secondClient.Get(url + "/ready")
+		if err != nil {
+			return false
+		}
+		res2.Body.Close()
+		return true
+	}, time.Second*5, time.Millisecond*50)
+
+	_, err = client.Get(url + "/ready")
+	require.Error(t, err)
+}
+
+func TestAPIServerMutualTLS(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptAPIEnabled(false))
+
+	dir := t.TempDir()
+	certPath, keyPath, serverPEM := writeSelfSignedCert(t, dir, "server", 1)
+	clientCertPath, clientKeyPath, _ := writeSelfSignedCert(t, dir, "client", 2)
+
+	srv, err := mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetTLSCertFiles(certPath, keyPath),
+		manager.ServerOptRequireClientCerts(clientCertPath),
+	)
+	require.NoError(t, err)
+
+	url := startTestAPIServer(t, srv, "https")
+
+	_, err = tlsTestClient(t, serverPEM).Get(url + "/streams")
+	require.Error(t, err)
+
+	clientCert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+	require.NoError(t, err)
+
+	res2, err := tlsTestClient(t, serverPEM, clientCert).Get(url + "/streams")
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+}
+
+func TestAPIServerTLSConfig(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptAPIEnabled(false))
+
+	_, err = mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetTLSConfig(&tls.Config{}),
+	)
+	require.Error(t, err)
+
+	certPEM, keyPEM, err := selfSignedCert(1)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	tlsConf := &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv, err := mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetTLSConfig(tlsConf),
+	)
+	require.NoError(t, err)
+	assert.Zero(t, tlsConf.MinVersion)
+
+	url := startTestAPIServer(t, srv, "https")
+
+	res2, err := tlsTestClient(t, certPEM).Get(url + "/streams")
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+
+	// Without a TLS config connections are served in plain text.
+	srv, err = mgr.NewAPIServer(manager.ServerOptSetAddress("127.0.0.1:0"))
+	require.NoError(t, err)
+
+	url = startTestAPIServer(t, srv, "http")
+
+	res2, err = http.Get(url + "/streams")
+	require.NoError(t, err)
+	res2.Body.Close()
+	assert.Equal(t, http.StatusOK, res2.StatusCode)
+}
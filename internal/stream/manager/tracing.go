@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+)
+
+// wrapTracing extracts the trace context of a request from its headers, such
+// as a W3C traceparent header, and starts a span for the request as a child of
+// it. The span is carried by the context of the request, where it is picked up
+// by the streams that are created or updated by the request.
+func (m *Type) wrapTracing(path string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := m.manager.Tracer().Tracer("bento").Start(ctx, r.Method+" "+path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", path),
+			),
+		)
+		defer span.End()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// streamOptTraceParent sets the span under which the messages consumed by a
+// stream are traced, which is the span of the request that created or updated
+// the stream.
+func streamOptTraceParent(ctx context.Context) StreamOpt {
+	return func(s *StreamStatus) {
+		s.traceParent = trace.SpanContextFromContext(ctx)
+	}
+}
+
+// TraceParent returns the span context of the request that created or updated
+// the stream, which is invalid if the request was not traced.
+func (s *StreamStatus) TraceParent() trace.SpanContext {
+	return s.traceParent
+}
+
+// streamTracerSetter is implemented by managers capable of replacing the
+// tracer provider used by the components of a stream.
+type streamTracerSetter interface {
+	WithTracer(tracer trace.TracerProvider) bundle.NewManagement
+}
+
+// parentedTracerProvider is a tracer provider for the components of a stream
+// that was created or updated by a traced request, and ties the messages of
+// the stream into the trace of the request. Spans that are started without a
+// parent, such as those started by inputs for each message consumed, become
+// children of the span of the request.
+type parentedTracerProvider struct {
+	trace.TracerProvider
+	parent trace.SpanContext
+}
+
+func (p parentedTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return parentedTracer{
+		Tracer: p.TracerProvider.Tracer(name, opts...),
+		parent: p.parent,
+	}
+}
+
+type parentedTracer struct {
+	trace.Tracer
+	parent trace.SpanContext
+}
+
+func (p parentedTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, p.parent)
+	}
+	return p.Tracer.Start(ctx, spanName, opts...)
+}
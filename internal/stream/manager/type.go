@@ -4,73 +4,369 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/stream"
 )
 
+// Possible states of a managed stream.
+const (
+	StreamStatePending = "pending"
+	StreamStateRunning = "running"
+	StreamStateClosed  = "closed"
+	StreamStateFailed  = "failed"
+
+	// StreamStateReconnecting is the state of a running stream that has lost
+	// its connection within the grace period set with
+	// OptSetUnhealthyGracePeriod.
+	StreamStateReconnecting = "reconnecting"
+
+	// StreamStateStalled is the state of a running stream that has made no
+	// progress within its stall timeout, and is being restarted.
+	StreamStateStalled = "stalled"
+
+	// StreamStateQuarantined is the state of a stream that repeatedly failed
+	// to be constructed, and is not run until it is released from quarantine.
+	StreamStateQuarantined = "quarantined"
+)
+
 // StreamStatus tracks a stream along with information regarding its internals.
 type StreamStatus struct {
-	stoppedAfter int64
-	config       stream.Config
+	config     stream.Config
+	metrics    *metrics.Local
+	createdAt  time.Time
+	modifiedAt time.Time
+
+	metricsLabel string
+	labels       map[string]string
+	description  string
+	throttle     *streamThrottle
+	logs         *logRing
+	logLevel     *streamLogLevel
+	configFormat string
+	breaker      restartBreaker
+	throughput   throughputTracker
+	dependsOn    []string
+	origin       string
+	originPath   string
+	traceParent  trace.SpanContext
+	maxUptime    time.Duration
+
+	stallTimeout          time.Duration
+	stallWhileInputActive bool
+
+	skipGlobalProcessors bool
+
+	// Set whilst the stream awaits a delayed start, guarded by the manager
+	// lock rather than mut.
+	pendingStart chan struct{}
+
+	mut          sync.Mutex
 	strm         *stream.Type
-	metrics      *metrics.Local
-	createdAt    time.Time
+	strmGen      uint64
+	divert       *divertOutput
+	startedAt    time.Time
+	restartAt    time.Time
+	restartCount int
+
+	stalled        bool
+	connectedOnce  bool
+	disconnectedAt time.Time
+	reconnecting   bool
+
+	quarantine streamQuarantine
+
+	closed       bool
+	stoppedAfter time.Duration
 }
 
-func newStreamStatus(conf stream.Config, stats *metrics.Local) *StreamStatus {
-	return &StreamStatus{
-		config:    conf,
-		metrics:   stats,
-		createdAt: time.Now(),
+// newStreamStatus creates a status for a new stream, or for a new version of an
+// existing stream when prev is non-nil, in which case any metadata of the
+// previous version is carried over unless overridden by the provided options.
+func newStreamStatus(conf stream.Config, stats *metrics.Local, prev *StreamStatus, opts ...StreamOpt) *StreamStatus {
+	now := time.Now()
+
+	s := &StreamStatus{
+		config:     conf,
+		metrics:    stats,
+		createdAt:  now,
+		modifiedAt: now.Truncate(time.Second),
+	}
+	if prev != nil {
+		// Modification times are exposed via HTTP headers with second
+		// precision, and therefore we ensure that each modification of a
+		// stream moves the timestamp forward by at least a second.
+		if !s.modifiedAt.After(prev.modifiedAt) {
+			s.modifiedAt = prev.modifiedAt.Add(time.Second)
+		}
+		s.metricsLabel = prev.metricsLabel
+		s.labels = prev.labels
+		s.description = prev.description
+		s.throttle = prev.throttle
+		s.logs = prev.logs
+		s.logLevel = prev.logLevel
+		s.configFormat = prev.configFormat
+		s.dependsOn = prev.dependsOn
+		s.maxUptime = prev.maxUptime
+		s.stallTimeout = prev.stallTimeout
+		s.stallWhileInputActive = prev.stallWhileInputActive
+		s.skipGlobalProcessors = prev.skipGlobalProcessors
+		if prev.origin != StreamOriginRegistered {
+			s.origin = prev.origin
+			s.originPath = prev.originPath
+		}
+	} else {
+		s.throttle = &streamThrottle{}
+		s.logLevel = newStreamLogLevel()
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// StreamOpt is an option to be applied to an individual stream when it is
+// created or updated.
+type StreamOpt func(s *StreamStatus)
+
+// StreamOptMetricsLabel overrides the value of the `stream` label attached to
+// the metrics of a stream, which is the stream identifier by default. Streams
+// that share the same metrics label have their metrics merged. An empty label
+// restores the default.
+func StreamOptMetricsLabel(label string) StreamOpt {
+	return func(s *StreamStatus) {
+		s.metricsLabel = label
+	}
+}
+
+// StreamOptLabels sets key-value labels on a stream, replacing any labels of a
+// previous version of the stream. Labels are used in order to organise and
+// filter streams and have no effect on their behaviour.
+func StreamOptLabels(labels map[string]string) StreamOpt {
+	return func(s *StreamStatus) {
+		s.labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			s.labels[k] = v
+		}
+	}
+}
+
+// StreamOptDescription sets a human readable description of the purpose of a
+// stream, replacing the description of a previous version of the stream. An
+// empty description removes it. Descriptions have no effect on the behaviour
+// of a stream.
+func StreamOptDescription(description string) StreamOpt {
+	return func(s *StreamStatus) {
+		s.description = description
+	}
+}
+
+// setStarting resets the status ahead of a new stream being started, and
+// returns a closure to be called once that stream closes. Closures belonging to
+// a previous stream have no effect.
+//
+// Note we reset the status before the stream pointer is set, this is okay as
+// long as we do not consider the stream started until setStream is called, and
+// we can't rule out a race condition between the stream terminating and the
+// stream pointer being set.
+func (s *StreamStatus) setStarting() func() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.strmGen++
+	gen := s.strmGen
+	s.startedAt = time.Now()
+	s.restartAt = scheduleRestart(s.startedAt, s.maxUptime)
+	s.stalled = false
+	s.connectedOnce = false
+	s.disconnectedAt = time.Time{}
+	s.reconnecting = false
+	s.closed = false
+	s.stoppedAfter = 0
+	return func() {
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		if s.strmGen == gen {
+			s.closed = true
+			s.stoppedAfter = time.Since(s.startedAt)
+		}
 	}
 }
 
 func (s *StreamStatus) setStream(strm *stream.Type) {
+	s.mut.Lock()
 	s.strm = strm
+	s.mut.Unlock()
+}
+
+func (s *StreamStatus) getStream() *stream.Type {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.strm
 }
 
 // IsRunning returns a boolean indicating whether the stream is currently
 // running.
 func (s *StreamStatus) IsRunning() bool {
-	return atomic.LoadInt64(&s.stoppedAfter) == 0
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.strm != nil && !s.closed
 }
 
 // IsReady returns a boolean indicating whether the stream is connected at both
 // the input and output level.
 func (s *StreamStatus) IsReady() bool {
-	return s.strm.IsReady()
+	strm := s.getStream()
+	return strm != nil && strm.IsReady()
+}
+
+// ConnectionAddresses returns the addresses or endpoints reported by the input
+// and output components of the stream, if they expose them.
+func (s *StreamStatus) ConnectionAddresses() (inputAddrs, outputAddrs []string) {
+	if strm := s.getStream(); strm != nil {
+		inputAddrs, outputAddrs = strm.ConnectionAddresses()
+	}
+	return
+}
+
+// BufferDepth returns how full the buffer of the stream is. The bool returned
+// is false when the stream is not running, has no buffer, or has a buffer that
+// is unable to report its depth.
+func (s *StreamStatus) BufferDepth() (buffer.Depth, bool) {
+	if strm := s.getStream(); strm != nil {
+		return strm.BufferDepth()
+	}
+	return buffer.Depth{}, false
+}
+
+// State returns the current state of the stream, which is either pending (it
+// has not yet been started), running, reconnecting (it is running but lost its
+// connection within the unhealthy grace period), stalled (it made no progress
+// within its stall timeout and is being restarted), closed, failed (it was
+// stopped after repeatedly failing to become ready following automatic
+// restarts), or quarantined (it repeatedly failed to be constructed).
+func (s *StreamStatus) State() string {
+	if s.IsQuarantined() {
+		return StreamStateQuarantined
+	}
+	if s.IsFailed() {
+		return StreamStateFailed
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.closed {
+		return StreamStateClosed
+	}
+	if s.strm == nil {
+		return StreamStatePending
+	}
+	if s.stalled {
+		return StreamStateStalled
+	}
+	if s.reconnecting {
+		return StreamStateReconnecting
+	}
+	return StreamStateRunning
 }
 
 // Uptime returns a time.Duration indicating the current uptime of the stream.
 func (s *StreamStatus) Uptime() time.Duration {
-	if stoppedAfter := atomic.LoadInt64(&s.stoppedAfter); stoppedAfter > 0 {
-		return time.Duration(stoppedAfter)
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.closed {
+		return s.stoppedAfter
+	}
+	if s.strm == nil {
+		return 0
+	}
+	return time.Since(s.startedAt)
+}
+
+// LastModified returns the time at which the config of the stream was last
+// created or updated, truncated to the second. Successive modifications of a
+// stream always result in a later timestamp.
+func (s *StreamStatus) LastModified() time.Time {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.modifiedAt
+}
+
+// Labels returns a copy of the key-value labels attached to the stream.
+func (s *StreamStatus) Labels() map[string]string {
+	if len(s.labels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(s.labels))
+	for k, v := range s.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// HasLabels returns true if the stream has every one of the provided labels.
+func (s *StreamStatus) HasLabels(labels map[string]string) bool {
+	for k, v := range labels {
+		if actual, exists := s.labels[k]; !exists || actual != v {
+			return false
+		}
 	}
-	return time.Since(s.createdAt)
+	return true
+}
+
+// Description returns the human readable description of the stream, or an
+// empty string if it has none.
+func (s *StreamStatus) Description() string {
+	return s.description
+}
+
+// MetricsLabel returns the value of the `stream` label attached to the metrics
+// of the stream, or an empty string if this has not been overridden.
+func (s *StreamStatus) MetricsLabel() string {
+	return s.metricsLabel
 }
 
 // Config returns the configuration of the stream.
 func (s *StreamStatus) Config() stream.Config {
+	s.mut.Lock()
+	defer s.mut.Unlock()
 	return s.config
 }
 
+// setConfig replaces the config of a stream that was modified whilst running,
+// moving the modification time of the stream forward.
+func (s *StreamStatus) setConfig(conf stream.Config) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.config = conf
+	modifiedAt := time.Now().Truncate(time.Second)
+	if !modifiedAt.After(s.modifiedAt) {
+		modifiedAt = s.modifiedAt.Add(time.Second)
+	}
+	s.modifiedAt = modifiedAt
+}
+
 // Metrics returns a metrics aggregator of the stream.
 func (s *StreamStatus) Metrics() *metrics.Local {
 	return s.metrics
 }
 
-// setClosed sets the flag indicating that the stream is closed.
-func (s *StreamStatus) setClosed() {
-	atomic.SwapInt64(&s.stoppedAfter, int64(time.Since(s.createdAt)))
-}
-
 //------------------------------------------------------------------------------
 
 // StreamProcConstructorFunc is a closure type that constructs a processor type
@@ -85,8 +381,82 @@ type Type struct {
 	closed  bool
 	streams map[string]*StreamStatus
 
-	manager    bundle.NewManagement
-	apiEnabled bool
+	manager     bundle.NewManagement
+	apiEnabled  bool
+	corsOrigins []string
+	corsHeaders []string
+
+	streamReaderOpts []config.OptFunc
+	maxStreams       int
+	manualStart      bool
+	apiAccessLog     bool
+	outputDiversion  bool
+
+	// Guarded by its own mutex as it can be changed at runtime.
+	apiTimeoutMut sync.Mutex
+	apiTimeout    time.Duration
+
+	apiMiddleware   []func(http.Handler) http.Handler
+	responseHeaders func(id string) http.Header
+
+	configSanitizer func(stream.Config) (any, error)
+	allowRawConfig  bool
+
+	maxDecompressedBodySize int64
+
+	shutdownTimeout  time.Duration
+	strictDuplicates bool
+
+	hooks               []LifecycleHook
+	events              *eventFeed
+	healthCheckInterval time.Duration
+	unhealthyGrace      time.Duration
+	healthLoopStarted   atomic.Bool
+	shutSig             chan struct{}
+
+	jobs *jobTracker
+
+	idempotency       *idempotencyCache
+	idempotencyWindow time.Duration
+
+	reloads *reloadTracker
+
+	maintenance          maintenanceMode
+	maintenanceQueueSize int
+
+	logBufferSize int
+
+	audit        auditLog
+	auditLogSize int
+
+	restartTimeout     time.Duration
+	restartMaxFailures int
+	restartWindow      time.Duration
+
+	quarantineThreshold int
+
+	throughputWindow time.Duration
+
+	startupJitter time.Duration
+
+	buildVersion string
+	buildDate    string
+
+	statePath  string
+	stateStore StateStore
+	state      *stateSync
+
+	defaultBuffer *buffer.Config
+
+	globalPreProcessors  []processor.Config
+	globalPostProcessors []processor.Config
+
+	changeNotificationConf *output.Config
+	changeNotifier         *changeNotifier
+
+	schemaOnce  sync.Once
+	schemaBytes []byte
+	schemaErr   error
 
 	lock sync.Mutex
 }
@@ -94,14 +464,52 @@ type Type struct {
 // New creates a new stream manager.Type.
 func New(mgr bundle.NewManagement, opts ...func(*Type)) *Type {
 	t := &Type{
-		streams:    map[string]*StreamStatus{},
-		apiEnabled: true,
-		manager:    mgr,
+		streams:              map[string]*StreamStatus{},
+		apiEnabled:           true,
+		apiTimeout:           time.Second * 5,
+		manager:              mgr,
+		events:               newEventFeed(),
+		healthCheckInterval:  time.Second,
+		shutSig:              make(chan struct{}),
+		jobs:                 newJobTracker(),
+		idempotency:          newIdempotencyCache(),
+		idempotencyWindow:    defaultIdempotencyWindow,
+		reloads:              newReloadTracker(mgr.Metrics()),
+		maintenanceQueueSize: defaultMaintenanceQueueSize,
+		logBufferSize:        defaultStreamLogBufferSize,
+		auditLogSize:         defaultAuditLogSize,
+		restartMaxFailures:   defaultRestartMaxFailures,
+		restartWindow:        defaultRestartWindow,
+		quarantineThreshold:  defaultQuarantineThreshold,
+		throughputWindow:     defaultThroughputWindow,
+
+		maxDecompressedBodySize: defaultMaxDecompressedBodySize,
 	}
 	for _, opt := range opts {
 		opt(t)
 	}
+	needsHealthLoop := t.apiEnabled || t.restartTimeout > 0 || t.unhealthyGrace > 0 || len(t.hooks) > 0 || t.changeNotificationConf != nil
+	t.hooks = append(t.hooks, t.events.add)
+	if t.changeNotificationConf != nil {
+		if n, err := newChangeNotifier(mgr, *t.changeNotificationConf); err != nil {
+			mgr.Logger().Error("Failed to create change notification output, change notifications are disabled: %v\n", err)
+		} else {
+			t.changeNotifier = n
+			t.hooks = append(t.hooks, n.add)
+		}
+	}
+	if t.stateStore == nil && t.statePath != "" {
+		t.stateStore = newFileStateStore(t.statePath, mgr.Environment(), mgr.Logger())
+	}
+	if t.stateStore != nil {
+		t.state = newStateSync(t.stateStore, mgr.Logger())
+		t.hooks = append(t.hooks, t.state.add)
+		go t.state.loop(t.shutSig)
+	}
 	t.registerEndpoints(t.apiEnabled)
+	if needsHealthLoop {
+		t.startHealthLoop()
+	}
 	return t
 }
 
@@ -115,19 +523,113 @@ func OptAPIEnabled(b bool) func(*Type) {
 	}
 }
 
+// OptSetAPITimeout sets the default timeout for HTTP API requests that modify
+// streams, which bounds the period of time spent waiting for a stream to shut
+// down when it is updated or deleted. The default is five seconds, and the
+// timeout can be changed whilst the manager is running with SetAPITimeout.
+func OptSetAPITimeout(tout time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.apiTimeout = tout
+	}
+}
+
+// OptSetShutdownTimeout sets a deadline for shutting down all streams when the
+// manager is stopped, which is independent of the API timeout and is typically
+// longer in order to allow buffered data to be flushed. Streams that fail to
+// stop within the deadline are abandoned with a logged warning. A value of zero
+// (the default) means the shutdown is bounded only by the context provided to
+// Stop.
+func OptSetShutdownTimeout(tout time.Duration) func(*Type) {
+	return func(t *Type) {
+		t.shutdownTimeout = tout
+	}
+}
+
+// OptSetStrictDuplicateDetection sets whether a set of streams provided via the
+// API is rejected when it contains streams with functionally identical configs,
+// which would likely result in the same data being consumed more than once.
+// When disabled (the default) such streams are accepted with a warning logged.
+func OptSetStrictDuplicateDetection(b bool) func(*Type) {
+	return func(t *Type) {
+		t.strictDuplicates = b
+	}
+}
+
+// OptSetMaxStreams sets a maximum number of streams that the manager will run
+// at any given time, regardless of whether they were created via the API or
+// otherwise. Attempts to create streams beyond this limit are rejected with
+// ErrStreamLimitReached. A value of zero (the default) means there is no limit.
+func OptSetMaxStreams(n int) func(*Type) {
+	return func(t *Type) {
+		t.maxStreams = n
+	}
+}
+
+// streamMetricsLabeller is implemented by managers capable of labelling the
+// metrics of a stream with a value other than its identifier.
+type streamMetricsLabeller interface {
+	ForStreamWithMetricsLabel(id, metricsLabel string) bundle.NewManagement
+}
+
+// OptSetManualStart sets whether streams created with Create are registered
+// without being started, in which case each stream must later be brought
+// online with an explicit call to Start. This is disabled by default.
+func OptSetManualStart(b bool) func(*Type) {
+	return func(t *Type) {
+		t.manualStart = b
+	}
+}
+
 //------------------------------------------------------------------------------
 
-// Errors specifically returned by a stream manager.
+// Errors specifically returned by a stream manager, which may be wrapped and
+// should therefore be matched with errors.Is.
 var (
 	ErrStreamExists       = errors.New("stream already exists")
 	ErrStreamDoesNotExist = errors.New("stream does not exist")
+	ErrStreamLimitReached = errors.New("maximum number of streams reached")
+	ErrStreamStarted      = errors.New("stream has already been started")
+
+	// ErrStreamConfigInvalid is matched by errors returned when a stream
+	// cannot be constructed from its config.
+	ErrStreamConfigInvalid = errors.New("stream config is invalid")
+
+	// ErrStreamTimeout is matched by errors returned when an operation on a
+	// stream, such as stopping it, does not complete within the time given.
+	ErrStreamTimeout = errors.New("stream operation timed out")
+
+	// ErrStreamRegistered is returned when attempting an operation that
+	// requires the manager to have constructed a stream from its config, such
+	// as pausing it, on a stream that was registered with RegisterStream.
+	ErrStreamRegistered = errors.New("operation is not supported by streams registered without a config")
+
+	// ErrStreamNotQuarantined is returned when attempting to release a stream
+	// from quarantine that is not quarantined.
+	ErrStreamNotQuarantined = errors.New("stream is not quarantined")
 )
 
 //------------------------------------------------------------------------------
 
 // Create attempts to construct and run a new stream under a unique ID. If the
-// ID already exists an error is returned.
-func (m *Type) Create(id string, conf stream.Config) error {
+// ID already exists ErrStreamExists is returned, and if the stream cannot be
+// constructed from its config, including when it references a component type
+// that is not registered, or the ID is reserved by an endpoint of the streams
+// API such as pause, the error matches ErrStreamConfigInvalid. When the
+// manager is configured with OptSetManualStart the stream is registered but
+// not run until Start is called.
+func (m *Type) Create(id string, conf stream.Config, opts ...StreamOpt) error {
+	return m.create(id, conf, nil, !m.manualStart, opts...)
+}
+
+func (m *Type) create(id string, conf stream.Config, prev *StreamStatus, start bool, opts ...StreamOpt) error {
+	if err := checkStreamID(id); err != nil {
+		return err
+	}
+	conf = m.withDefaultBuffer(conf)
+	if err := m.ValidateConfig(conf); err != nil {
+		return err
+	}
+
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
@@ -138,28 +640,225 @@ func (m *Type) Create(id string, conf stream.Config) error {
 	if _, exists := m.streams[id]; exists {
 		return ErrStreamExists
 	}
+	if m.maxStreams > 0 && len(m.streams) >= m.maxStreams {
+		return ErrStreamLimitReached
+	}
+
+	wrapper := newStreamStatus(conf, metrics.NewLocal(), prev, opts...)
+	if wrapper.logs == nil && m.logBufferSize > 0 {
+		wrapper.logs = newLogRing(m.logBufferSize)
+	}
+	if wrapper.metricsLabel != "" {
+		if _, ok := m.manager.(streamMetricsLabeller); !ok {
+			return errors.New("overriding stream metrics labels is not supported by this manager")
+		}
+	}
+	if err := m.checkDependencyCycle(id, wrapper); err != nil {
+		return err
+	}
+	m.warnMetricsLabelCollisions(id, wrapper)
+
+	if start {
+		if delay := m.startupDelay(); delay > 0 && prev == nil {
+			m.startStreamAfter(id, wrapper, delay)
+		} else if err := m.startStream(id, wrapper); err != nil {
+			return err
+		}
+	}
+
+	m.streams[id] = wrapper
+
+	eventType := LifecycleEventCreated
+	if prev != nil {
+		eventType = LifecycleEventUpdated
+	}
+	m.emitEvent(id, eventType, wrapper)
+	return nil
+}
+
+// Apply creates a stream under an ID if it does not already exist, and
+// otherwise updates the existing stream unless its config and options are
+// already identical to those provided, ignoring cosmetic differences in the
+// config such as the ordering of fields. Returns whether the stream was created
+// or updated.
+func (m *Type) Apply(ctx context.Context, id string, conf stream.Config, opts ...StreamOpt) (changed bool, err error) {
+	return m.apply(ctx, id, conf, !m.manualStart, opts...)
+}
 
-	strmFlatMetrics := metrics.NewLocal()
-	sMgr := m.manager.ForStream(id).WithAddedMetrics(strmFlatMetrics)
+func (m *Type) apply(ctx context.Context, id string, conf stream.Config, start bool, opts ...StreamOpt) (changed bool, err error) {
+	m.lock.Lock()
+	existing, exists := m.streams[id]
+	m.lock.Unlock()
+
+	if !exists {
+		return true, m.create(id, conf, nil, start, opts...)
+	}
 
-	// Note we initialise the status without a stream pointer, this is okay as
-	// long as we do not add it to m.streams without one set.
-	//
-	// This seems a bit wonky but we can't rule out a race condition between
-	// the stream terminating and setClosed and actually initialising a status.
-	wrapper := newStreamStatus(conf, strmFlatMetrics)
-	strm, err := stream.New(conf, sMgr, stream.OptOnClose(func() {
-		wrapper.setClosed()
-	}))
+	if identical, err := m.streamsIdentical(existing, newStreamStatus(conf, nil, existing, opts...)); err != nil {
+		return false, err
+	} else if identical {
+		return false, nil
+	}
+	return true, m.Update(ctx, id, conf, opts...)
+}
+
+// streamsIdentical returns whether two versions of a stream have functionally
+// identical configs, the same labels, the same description and the same
+// dependencies.
+func (m *Type) streamsIdentical(a, b *StreamStatus) (bool, error) {
+	if a.metricsLabel != b.metricsLabel || !maps.Equal(a.labels, b.labels) || a.description != b.description || !slices.Equal(a.dependsOn, b.dependsOn) {
+		return false, nil
+	}
+	aConf, bConf := a.Config(), b.Config()
+	aCanonical, err := m.canonicalStreamConfig(aConf.GetRawSource())
 	if err != nil {
-		return err
+		return false, err
+	}
+	bCanonical, err := m.canonicalStreamConfig(bConf.GetRawSource())
+	if err != nil {
+		return false, err
+	}
+	return aCanonical == bCanonical, nil
+}
+
+func metricsLabelOf(id string, wrapper *StreamStatus) string {
+	if wrapper.metricsLabel != "" {
+		return wrapper.metricsLabel
+	}
+	return id
+}
+
+// warnMetricsLabelCollisions logs a warning for each existing stream that
+// would share metrics with a new stream, which must be called whilst holding
+// the manager lock.
+func (m *Type) warnMetricsLabelCollisions(id string, wrapper *StreamStatus) {
+	label := metricsLabelOf(id, wrapper)
+	for otherID, other := range m.streams {
+		if otherID != id && metricsLabelOf(otherID, other) == label {
+			m.manager.Logger().Warn("Metrics of stream '%v' will be merged with those of stream '%v' as they share the metrics label '%v'\n", id, otherID, label)
+		}
+	}
+}
+
+// streamManager returns the manager that the components of a stream are
+// constructed with.
+func (m *Type) streamManager(id string, wrapper *StreamStatus) bundle.NewManagement {
+	var sMgr bundle.NewManagement
+	if l, ok := m.manager.(streamMetricsLabeller); ok && wrapper.metricsLabel != "" {
+		sMgr = l.ForStreamWithMetricsLabel(id, wrapper.metricsLabel)
+	} else {
+		sMgr = m.manager.ForStream(id)
+	}
+	sMgr = sMgr.WithAddedMetrics(wrapper.metrics)
+	if l, ok := sMgr.(streamLogLevelOverrider); ok {
+		sMgr = l.WithLogLevelOverride(wrapper.logLevel.override)
+	}
+	if l, ok := sMgr.(streamLoggerAdder); ok && wrapper.logs != nil {
+		sMgr = l.WithAddedLogger(&ringLogger{ring: wrapper.logs})
+	}
+	if l, ok := sMgr.(streamTracerSetter); ok && wrapper.traceParent.IsValid() {
+		sMgr = l.WithTracer(parentedTracerProvider{
+			TracerProvider: sMgr.Tracer(),
+			parent:         wrapper.traceParent,
+		})
+	}
+	return sMgr
+}
+
+func (m *Type) startStream(id string, wrapper *StreamStatus) error {
+	sMgr := m.streamManager(id, wrapper)
+
+	onClose := wrapper.setStarting()
+	gate := newThrottleGate(wrapper.throttle)
+	strmOpts := []func(*stream.Type){
+		stream.OptOnClose(func() {
+			onClose()
+			m.emitEvent(id, LifecycleEventHealth, wrapper)
+		}),
+		stream.OptOnStop(gate.release),
+		stream.OptAddInputProcessors(gate),
+	}
+	if m.outputDiversion {
+		strmOpts = append(strmOpts, stream.OptWrapOutput(func(o output.Streamed) output.Streamed {
+			d := newDivertOutput(o)
+			wrapper.setDivert(d)
+			return d
+		}))
+	}
+	strm, err := stream.New(m.withGlobalProcessors(wrapper), sMgr, strmOpts...)
+	if err != nil {
+		return withErrorKind(ErrStreamConfigInvalid, err)
+	}
+	if wrapper.stallTimeout > 0 || wrapper.maxUptime > 0 {
+		m.startHealthLoop()
 	}
 
 	wrapper.setStream(strm)
-	m.streams[id] = wrapper
 	return nil
 }
 
+// Start attempts to run a stream that was created without being started,
+// either because the manager was configured with OptSetManualStart or because
+// the stream was created via the API with the `start` parameter set to false.
+// Returns ErrStreamStarted if the stream has already been started.
+func (m *Type) Start(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return component.ErrTypeClosed
+	}
+
+	wrapper, exists := m.streams[id]
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+	if wrapper.getStream() != nil {
+		return ErrStreamStarted
+	}
+	wrapper.cancelPendingStart()
+	if err := m.startStream(id, wrapper); err != nil {
+		return err
+	}
+
+	m.emitEvent(id, LifecycleEventHealth, wrapper)
+	return nil
+}
+
+// snapshotStreams returns a copy of the set of managed streams, allowing the
+// status of each stream to be queried without holding the manager lock.
+func (m *Type) snapshotStreams() map[string]*StreamStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	streams := make(map[string]*StreamStatus, len(m.streams))
+	for k, v := range m.streams {
+		streams[k] = v
+	}
+	return streams
+}
+
+// ForEachStream calls fn with the id and status of each managed stream in
+// lexical order of their ids, stopping early when fn returns false. The
+// streams are snapshot before iterating, and therefore fn is called without
+// holding the manager lock and may freely call other methods of the manager.
+// Streams created or removed during the iteration are not reflected by it.
+func (m *Type) ForEachStream(fn func(id string, status *StreamStatus) bool) {
+	streams := m.snapshotStreams()
+
+	ids := make([]string, 0, len(streams))
+	for id := range streams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if !fn(id, streams[id]) {
+			return
+		}
+	}
+}
+
 // Read attempts to obtain the status of a managed stream. Returns an error if
 // the stream does not exist.
 func (m *Type) Read(id string) (*StreamStatus, error) {
@@ -179,10 +878,11 @@ func (m *Type) Read(id string) (*StreamStatus, error) {
 }
 
 // Update attempts to stop an existing stream and replace it with a new version
-// of the same stream.
-func (m *Type) Update(ctx context.Context, id string, conf stream.Config) error {
+// of the same stream. Options applied to the previous version of the stream
+// are retained unless overridden.
+func (m *Type) Update(ctx context.Context, id string, conf stream.Config, opts ...StreamOpt) error {
 	m.lock.Lock()
-	_, exists := m.streams[id]
+	wrapper, exists := m.streams[id]
 	closed := m.closed
 	m.lock.Unlock()
 
@@ -192,17 +892,173 @@ func (m *Type) Update(ctx context.Context, id string, conf stream.Config) error
 	if !exists {
 		return ErrStreamDoesNotExist
 	}
+	if err := m.ValidateConfig(conf); err != nil {
+		return err
+	}
+	if err := m.checkUpdateDependencies(id, conf, wrapper, opts...); err != nil {
+		return err
+	}
+
+	// A stream that has not yet been started remains that way after an update
+	// when streams are started manually.
+	start := !m.manualStart || wrapper.getStream() != nil
 
-	if err := m.Delete(ctx, id); err != nil {
+	if _, err := m.delete(ctx, id); err != nil {
 		return err
 	}
-	return m.Create(id, conf)
+	return m.create(id, conf, wrapper, start, opts...)
+}
+
+// defaultSwapConnectTimeout is the period of time that Swap waits for a new
+// version of a stream to connect when the context provided has no deadline.
+const defaultSwapConnectTimeout = time.Second * 5
+
+// Swap attempts to replace an existing stream with a new version of the same
+// stream without a window of downtime. The new version is started and, once
+// its inputs and outputs are connected, it replaces the existing version which
+// is then drained and shut down gracefully. This means that for a brief period
+// both versions of the stream run in parallel.
+//
+// If the new version fails to connect within half of the time remaining on the
+// context (or five seconds when the context has no deadline) then it is shut
+// down and the stream is instead replaced in place as with Update.
+func (m *Type) Swap(ctx context.Context, id string, conf stream.Config, opts ...StreamOpt) error {
+	m.lock.Lock()
+	wrapper, exists := m.streams[id]
+	closed := m.closed
+	m.lock.Unlock()
+
+	if closed {
+		return component.ErrTypeClosed
+	}
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+	conf = m.withDefaultBuffer(conf)
+	if err := m.ValidateConfig(conf); err != nil {
+		return err
+	}
+	if err := m.checkUpdateDependencies(id, conf, wrapper, opts...); err != nil {
+		return err
+	}
+
+	oldStrm := wrapper.getStream()
+	if oldStrm == nil {
+		// There is no downtime to avoid for streams that have not been
+		// started.
+		return m.Update(ctx, id, conf, opts...)
+	}
+
+	newWrapper := newStreamStatus(conf, metrics.NewLocal(), wrapper, opts...)
+	if err := m.startStream(id, newWrapper); err != nil {
+		return err
+	}
+
+	connectTimeout := defaultSwapConnectTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		connectTimeout = time.Until(deadline) / 2
+	}
+	if !waitForReady(ctx, newWrapper, connectTimeout) {
+		m.manager.Logger().Warn("New version of stream '%v' failed to connect within %v, falling back to replacing the stream in place\n", id, connectTimeout)
+		if err := newWrapper.getStream().Stop(ctx); err != nil {
+			return stopErr(err)
+		}
+		return m.Update(ctx, id, conf, opts...)
+	}
+
+	m.lock.Lock()
+	if m.closed || m.streams[id] != wrapper {
+		// The stream was modified or removed whilst we were waiting, and
+		// therefore the swap is abandoned.
+		m.lock.Unlock()
+		if err := newWrapper.getStream().Stop(ctx); err != nil {
+			return stopErr(err)
+		}
+		return errors.New("stream was modified during swap")
+	}
+	m.streams[id] = newWrapper
+	m.lock.Unlock()
+
+	m.emitEvent(id, LifecycleEventUpdated, newWrapper)
+	return stopErr(oldStrm.Stop(ctx))
+}
+
+func waitForReady(ctx context.Context, s *StreamStatus, timeout time.Duration) bool {
+	ctx, done := context.WithTimeout(ctx, timeout)
+	defer done()
+
+	ticker := time.NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+
+	for !s.IsReady() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
 }
 
 // Delete attempts to stop and remove a stream by its ID. Returns an error if
 // the stream was not found, or if clean shutdown fails in the specified period
-// of time.
+// of time. The metrics of a removed stream are removed from the metrics
+// exporter when it supports doing so.
 func (m *Type) Delete(ctx context.Context, id string) error {
+	wrapper, err := m.delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	m.removeStreamMetrics(id, wrapper)
+	m.emitEvent(id, LifecycleEventDeleted, nil)
+	return nil
+}
+
+// DeleteAll attempts to stop and remove all streams, which are stopped in
+// parallel. Returns the sorted identifiers of the streams that were removed,
+// along with an aggregate of the errors of any streams that failed to shut down
+// cleanly, which are left in place.
+func (m *Type) DeleteAll(ctx context.Context) (deleted []string, err error) {
+	deleted, errs := m.deleteAll(ctx)
+	return deleted, errors.Join(errs...)
+}
+
+func (m *Type) deleteAll(ctx context.Context) (deleted []string, errs []error) {
+	streams := m.snapshotStreams()
+
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	for id := range streams {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			err := m.Delete(ctx, id)
+
+			mut.Lock()
+			defer mut.Unlock()
+			switch {
+			case err == nil:
+				deleted = append(deleted, id)
+			case errors.Is(err, ErrStreamDoesNotExist):
+				// Removed whilst we were deleting the others.
+			default:
+				errs = append(errs, fmt.Errorf("failed to delete stream '%v': %w", id, err))
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	sort.Strings(deleted)
+	return deleted, errs
+}
+
+// ForceDelete attempts to stop and remove a stream by its ID as with Delete,
+// but if the stream fails to shut down cleanly before the context is cancelled
+// it is removed regardless, in which case resources belonging to the stream may
+// be leaked. Returns an error if the stream was not found.
+func (m *Type) ForceDelete(ctx context.Context, id string) error {
 	m.lock.Lock()
 	if m.closed {
 		m.lock.Unlock()
@@ -210,50 +1066,183 @@ func (m *Type) Delete(ctx context.Context, id string) error {
 	}
 
 	wrapper, exists := m.streams[id]
+	if exists {
+		wrapper.cancelPendingStart()
+	}
 	m.lock.Unlock()
 	if !exists {
 		return ErrStreamDoesNotExist
 	}
 
-	if err := wrapper.strm.Stop(ctx); err != nil {
-		return err
+	if strm := wrapper.getStream(); strm != nil {
+		// Components that ignore context cancellation could block a stop
+		// indefinitely, and therefore we only wait until the context ends.
+		stopChan := make(chan error, 1)
+		go func() {
+			stopChan <- strm.Stop(ctx)
+		}()
+
+		var err error
+		select {
+		case err = <-stopChan:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			m.manager.Logger().Warn("Forcefully removing stream '%v' after it failed to shut down gracefully, resources belonging to the stream may have leaked: %v\n", id, err)
+		}
 	}
 
 	m.lock.Lock()
-	delete(m.streams, id)
+	if m.streams[id] == wrapper {
+		delete(m.streams, id)
+	}
 	m.lock.Unlock()
 
+	m.removeStreamMetrics(id, wrapper)
+	m.emitEvent(id, LifecycleEventDeleted, nil)
 	return nil
 }
 
+func (m *Type) delete(ctx context.Context, id string) (*StreamStatus, error) {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil, component.ErrTypeClosed
+	}
+
+	wrapper, exists := m.streams[id]
+	if exists {
+		wrapper.cancelPendingStart()
+	}
+	m.lock.Unlock()
+	if !exists {
+		return nil, ErrStreamDoesNotExist
+	}
+
+	if strm := wrapper.getStream(); strm != nil {
+		if err := strm.Stop(ctx); err != nil {
+			return nil, stopErr(err)
+		}
+	}
+
+	m.lock.Lock()
+	delete(m.streams, id)
+	m.lock.Unlock()
+
+	return wrapper, nil
+}
+
+// removeStreamMetrics removes the series labelled with the metrics label of a
+// stream that has been deleted, unless the label is shared with a stream that
+// still exists. Metrics exporters that do not support the removal of series
+// are left untouched.
+func (m *Type) removeStreamMetrics(id string, wrapper *StreamStatus) {
+	label := metricsLabelOf(id, wrapper)
+
+	m.lock.Lock()
+	for otherID, other := range m.streams {
+		if metricsLabelOf(otherID, other) == label {
+			m.lock.Unlock()
+			return
+		}
+	}
+	m.lock.Unlock()
+
+	if !metrics.DeleteLabel(m.manager.Metrics(), "stream", label) {
+		m.manager.Logger().Debug("Metrics of deleted stream '%v' were not removed as the metrics exporter does not support it\n", id)
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Stop attempts to gracefully shut down all active streams and close the
-// stream manager.
+// stream manager. When the manager is configured with OptSetShutdownTimeout the
+// shutdown is bounded by that timeout in addition to the provided context, and
+// streams that fail to stop before the shutdown ends are abandoned.
 func (m *Type) Stop(ctx context.Context) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	resultChan := make(chan string)
+	if m.shutdownTimeout > 0 {
+		var done func()
+		ctx, done = context.WithTimeout(ctx, m.shutdownTimeout)
+		defer done()
+	}
 
-	for k, v := range m.streams {
-		go func(id string, strm *StreamStatus) {
-			if err := strm.strm.Stop(ctx); err != nil {
-				resultChan <- id
-			} else {
-				resultChan <- ""
-			}
-		}(k, v)
+	type stopResult struct {
+		id  string
+		err error
 	}
 
+	// Streams are stopped before the streams that they depend on, and streams
+	// that do not depend on each other are stopped in parallel.
+	levels := dependencyLevels(m.streams)
+
 	failedStreams := []string{}
-	for i := 0; i < len(m.streams); i++ {
-		if failedStrm := <-resultChan; failedStrm != "" {
-			failedStreams = append(failedStreams, failedStrm)
+stopLevels:
+	for i := len(levels) - 1; i >= 0; i-- {
+		resultChan := make(chan stopResult, len(levels[i]))
+
+		pending := make(map[string]struct{}, len(levels[i]))
+		for _, k := range levels[i] {
+			pending[k] = struct{}{}
+			go func(id string, strm *StreamStatus) {
+				var err error
+				if s := strm.getStream(); s != nil {
+					err = s.Stop(ctx)
+				}
+				resultChan <- stopResult{id: id, err: err}
+			}(k, m.streams[k])
+		}
+
+		for len(pending) > 0 {
+			select {
+			case res := <-resultChan:
+				delete(pending, res.id)
+				if res.err != nil {
+					failedStreams = append(failedStreams, res.id)
+				}
+				continue
+			case <-ctx.Done():
+			}
+
+			// Components that ignore context cancellation could block a stop
+			// indefinitely, and therefore the remaining streams are abandoned
+			// once the shutdown deadline is reached. Streams of the levels not
+			// yet reached are still signalled to stop.
+			for _, level := range levels[:i] {
+				for _, id := range level {
+					pending[id] = struct{}{}
+					if strm := m.streams[id].getStream(); strm != nil {
+						go func() { _ = strm.Stop(ctx) }()
+					}
+				}
+			}
+			for id := range pending {
+				m.manager.Logger().Warn("Abandoning stream '%v' after it failed to shut down within the shutdown deadline, resources belonging to the stream may have leaked\n", id)
+				failedStreams = append(failedStreams, id)
+			}
+			break stopLevels
 		}
 	}
+	sort.Strings(failedStreams)
 
 	m.streams = map[string]*StreamStatus{}
+	if !m.closed {
+		close(m.shutSig)
+		m.events.close()
+		if m.changeNotifier != nil {
+			if err := m.changeNotifier.close(ctx); err != nil {
+				m.manager.Logger().Error("Failed to close change notification output: %v\n", err)
+			}
+		}
+		if m.state != nil {
+			if err := m.state.flush(); err != nil {
+				m.manager.Logger().Error("Failed to persist stream manager state: %v\n", err)
+			}
+		}
+	}
 	m.closed = true
 
 	if len(failedStreams) > 0 {
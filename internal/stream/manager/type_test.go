@@ -1,16 +1,38 @@
 package manager
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
 	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/internal/stream"
 )
 
@@ -113,3 +135,1450 @@ func TestTypeBasicClose(t *testing.T) {
 		t.Errorf("Unexpected error: %v != %v", act, exp)
 	}
 }
+
+func TestTypeMaxStreams(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptSetMaxStreams(2))
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+	require.ErrorIs(t, mgr.Create("baz", harmlessConf(t)), ErrStreamLimitReached)
+
+	require.NoError(t, mgr.Update(ctx, "bar", harmlessConf(t)))
+
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+	require.NoError(t, mgr.Create("baz", harmlessConf(t)))
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeManualStart(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptSetManualStart(true))
+
+	require.ErrorIs(t, mgr.Start("foo"), ErrStreamDoesNotExist)
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.False(t, info.IsRunning())
+	assert.False(t, info.IsReady())
+	assert.Equal(t, StreamStatePending, info.State())
+	assert.Equal(t, time.Duration(0), info.Uptime())
+
+	newConf := harmlessConf(t)
+	newConf.Buffer.Type = "memory"
+	require.NoError(t, mgr.Update(ctx, "foo", newConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, StreamStatePending, info.State())
+
+	require.NoError(t, mgr.Start("foo"))
+	require.ErrorIs(t, mgr.Start("foo"), ErrStreamStarted)
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, StreamStateRunning, info.State())
+	assert.Equal(t, "memory", info.Config().Buffer.Type)
+
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeStartupJitter(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptSetStartupJitter(time.Second))
+
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	for _, id := range ids {
+		require.NoError(t, mgr.Create(id, harmlessConf(t)))
+	}
+
+	// Deleting a stream before it starts prevents it from ever starting.
+	require.NoError(t, mgr.Delete(ctx, "h"))
+	ids = ids[:len(ids)-1]
+
+	startTimes := func() (times []time.Time, allRunning bool) {
+		for _, id := range ids {
+			info, err := mgr.Read(id)
+			require.NoError(t, err)
+			if !info.IsRunning() {
+				return nil, false
+			}
+			info.mut.Lock()
+			times = append(times, info.startedAt)
+			info.mut.Unlock()
+		}
+		return times, true
+	}
+
+	var times []time.Time
+	require.Eventually(t, func() bool {
+		var allRunning bool
+		times, allRunning = startTimes()
+		return allRunning
+	}, time.Second*5, time.Millisecond*10)
+
+	earliest, latest := times[0], times[0]
+	for _, ts := range times[1:] {
+		if ts.Before(earliest) {
+			earliest = ts
+		}
+		if ts.After(latest) {
+			latest = ts
+		}
+	}
+	assert.Greater(t, latest.Sub(earliest), time.Millisecond*50)
+
+	_, err = mgr.Read("h")
+	require.ErrorIs(t, err, ErrStreamDoesNotExist)
+
+	// Updates to existing streams are not delayed.
+	newConf := harmlessConf(t)
+	newConf.Buffer.Type = "memory"
+	require.NoError(t, mgr.Update(ctx, "a", newConf))
+
+	info, err := mgr.Read("a")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeMetricsLabel(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	stats := metrics.NewLocal()
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    count: 5
+    interval: ""
+    mapping: 'root = "hello world"'
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Create("foo", conf, StreamOptMetricsLabel("shared")))
+	require.NoError(t, mgr.Create("bar", conf, StreamOptMetricsLabel("shared")))
+	require.NoError(t, mgr.Create("baz", conf))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", info.MetricsLabel())
+
+	// Streams sharing a metrics label have their metrics merged.
+	receivedByLabel := func() map[string]int64 {
+		counts := map[string]int64{}
+		for k, v := range stats.GetCounters() {
+			name, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+			if name != "input_received" {
+				continue
+			}
+			for i, tn := range tagNames {
+				if tn == "stream" {
+					counts[tagValues[i]] += v
+				}
+			}
+		}
+		return counts
+	}
+	assert.Eventually(t, func() bool {
+		return reflect.DeepEqual(map[string]int64{"shared": 10, "baz": 5}, receivedByLabel())
+	}, time.Second*10, time.Millisecond*50)
+
+	// The label is retained across updates unless overridden.
+	require.NoError(t, mgr.Update(ctx, "foo", conf))
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", info.MetricsLabel())
+
+	require.NoError(t, mgr.Update(ctx, "foo", conf, StreamOptMetricsLabel("")))
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "", info.MetricsLabel())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeHealthLoopStartedOnDemand(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+	assert.False(t, mgr.healthLoopStarted.Load())
+
+	require.NoError(t, mgr.Create("bar", harmlessConf(t), StreamOptStallTimeout(time.Minute, false)))
+	assert.True(t, mgr.healthLoopStarted.Load())
+
+	apiMgr := New(res)
+	defer func() {
+		assert.NoError(t, apiMgr.Stop(ctx))
+	}()
+	assert.True(t, apiMgr.healthLoopStarted.Load())
+}
+
+func TestTypeOutputDiversionDisabled(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Nil(t, info.getDivert())
+
+	dlConf := output.NewConfig()
+	dlConf.Type = "drop"
+	assert.ErrorIs(t, mgr.DeleteWithDeadLetter(ctx, "foo", dlConf), errOutputDiversionDisabled)
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+}
+
+func TestTypeDeleteRemovesMetrics(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	stats := metrics.NewLocal()
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    count: 5
+    interval: ""
+    mapping: 'root = "hello world"'
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	streamLabels := func() map[string]struct{} {
+		labels := map[string]struct{}{}
+		for k := range stats.GetCounters() {
+			_, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+			for i, tn := range tagNames {
+				if tn == "stream" {
+					labels[tagValues[i]] = struct{}{}
+				}
+			}
+		}
+		return labels
+	}
+
+	require.NoError(t, mgr.Create("foo", conf))
+	require.NoError(t, mgr.Create("bar", conf, StreamOptMetricsLabel("shared")))
+	require.NoError(t, mgr.Create("baz", conf, StreamOptMetricsLabel("shared")))
+	assert.Eventually(t, func() bool {
+		labels := streamLabels()
+		_, fooExists := labels["foo"]
+		_, sharedExists := labels["shared"]
+		return fooExists && sharedExists
+	}, time.Second*10, time.Millisecond*50)
+
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+	assert.NotContains(t, streamLabels(), "foo")
+	assert.Contains(t, streamLabels(), "shared")
+
+	// Metrics shared with a stream that still exists are retained.
+	require.NoError(t, mgr.Delete(ctx, "bar"))
+	assert.Contains(t, streamLabels(), "shared")
+
+	require.NoError(t, mgr.ForceDelete(ctx, "baz"))
+	assert.Empty(t, streamLabels())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+type gatedMockInput struct {
+	*mock.Input
+	connected *atomic.Bool
+}
+
+func (g *gatedMockInput) ConnectionStatus() component.ConnectionStatuses {
+	if !g.connected.Load() {
+		return component.ConnectionStatuses{
+			component.ConnectionFailing(component.NoopObservability(), errors.New("not yet")),
+		}
+	}
+	return g.Input.ConnectionStatus()
+}
+
+func TestTypeSwap(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var connected atomic.Bool
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &gatedMockInput{
+			Input:     &mock.Input{TChan: make(chan message.Transaction)},
+			connected: &connected,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "gated_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	oldInfo, err := mgr.Read("foo")
+	require.NoError(t, err)
+
+	newConf := harmlessConf(t)
+	newConf.Input = input.NewConfig()
+	newConf.Input.Type = "gated_input"
+
+	swapErr := make(chan error, 1)
+	go func() {
+		swapErr <- mgr.Swap(ctx, "foo", newConf)
+	}()
+
+	// The old version keeps running until the new version is connected.
+	time.Sleep(time.Millisecond * 200)
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Same(t, oldInfo, info)
+	assert.True(t, info.IsRunning())
+
+	connected.Store(true)
+	require.NoError(t, <-swapErr)
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.NotSame(t, oldInfo, info)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, "gated_input", info.Config().Input.Type)
+	assert.Eventually(t, func() bool {
+		return !oldInfo.IsRunning()
+	}, time.Second*5, time.Millisecond*10)
+
+	// When the new version fails to connect the stream is replaced in place.
+	connected.Store(false)
+
+	updateCtx, updateDone := context.WithTimeout(ctx, time.Second)
+	defer updateDone()
+	require.NoError(t, mgr.Swap(updateCtx, "foo", newConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.False(t, info.IsReady())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeCreateWithConnectTimeout(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var connected atomic.Bool
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &gatedMockInput{
+			Input:     &mock.Input{TChan: make(chan message.Transaction)},
+			connected: &connected,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "gated_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		require.NoError(t, mgr.Stop(ctx))
+	}()
+
+	conf := harmlessConf(t)
+	conf.Input = input.NewConfig()
+	conf.Input.Type = "gated_input"
+
+	// A stream that never connects is removed once the timeout elapses.
+	err = mgr.CreateWithConnectTimeout(ctx, "foo", conf, time.Millisecond*200)
+	require.ErrorIs(t, err, ErrStreamConnectTimeout)
+	assert.EqualError(t, err, "stream failed to connect within the connection timeout: 200ms")
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	// The same failure is reported by the API with a 504.
+	request := httptest.NewRequest("POST", "/streams/foo?connect_timeout=200ms", strings.NewReader(`
+input:
+  gated_input: {}
+output:
+  drop: {}
+`))
+	response := httptest.NewRecorder()
+	mgr.Router().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusGatewayTimeout, response.Code)
+	assert.Equal(t, "Error: stream failed to connect within the connection timeout: 200ms\n", response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	request = httptest.NewRequest("POST", "/streams/foo?connect_timeout=nope", strings.NewReader(`
+input:
+  gated_input: {}
+output:
+  drop: {}
+`))
+	response = httptest.NewRecorder()
+	mgr.Router().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Streams that connect within the timeout are kept.
+	connected.Store(true)
+	require.NoError(t, mgr.CreateWithConnectTimeout(ctx, "foo", conf, time.Second*5))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsReady())
+}
+
+type slowMockInput struct {
+	*mock.Input
+	drainFor time.Duration
+	release  chan struct{}
+}
+
+func (s *slowMockInput) WaitForClose(ctx context.Context) error {
+	if s.release != nil {
+		// Ignores context cancellation entirely.
+		<-s.release
+		return nil
+	}
+	select {
+	case <-time.After(s.drainFor):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTypeShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &slowMockInput{
+			Input:    &mock.Input{TChan: make(chan message.Transaction)},
+			drainFor: time.Millisecond * 500,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "slow_input",
+	}))
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &slowMockInput{
+			Input:   &mock.Input{TChan: make(chan message.Transaction)},
+			release: release,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "stuck_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	confWithInput := func(inputType string) stream.Config {
+		conf := harmlessConf(t)
+		conf.Input = input.NewConfig()
+		conf.Input.Type = inputType
+		return conf
+	}
+
+	// A generous shutdown timeout allows slow streams to drain regardless of
+	// a short API timeout.
+	mgr := New(res, OptAPIEnabled(false), OptSetAPITimeout(time.Millisecond*50), OptSetShutdownTimeout(time.Second*10))
+	require.NoError(t, mgr.Create("foo", confWithInput("slow_input")))
+
+	start := time.Now()
+	require.NoError(t, mgr.Stop(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*500)
+
+	// Streams that fail to stop within the shutdown timeout are abandoned.
+	mgr = New(res, OptAPIEnabled(false), OptSetShutdownTimeout(time.Millisecond*100))
+	require.NoError(t, mgr.Create("foo", confWithInput("slow_input")))
+	require.NoError(t, mgr.Create("bar", confWithInput("stuck_input")))
+
+	err = mgr.Stop(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bar")
+}
+
+func TestTypeErrorKinds(t *testing.T) {
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &slowMockInput{
+			Input:    &mock.Input{TChan: make(chan message.Transaction)},
+			drainFor: time.Second,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "slow_input",
+	}))
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return nil, errors.New("nope")
+	}, docs.ComponentSpec{
+		Name: "broken_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		require.NoError(t, mgr.Stop(ctx))
+	}()
+
+	confWithInput := func(inputType string) stream.Config {
+		conf := harmlessConf(t)
+		conf.Input = input.NewConfig()
+		conf.Input.Type = inputType
+		return conf
+	}
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	err = mgr.Create("foo", harmlessConf(t))
+	assert.ErrorIs(t, err, ErrStreamExists)
+
+	err = mgr.Delete(context.Background(), "bar")
+	assert.ErrorIs(t, err, ErrStreamDoesNotExist)
+
+	err = mgr.Create("bar", confWithInput("broken_input"))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStreamConfigInvalid)
+	assert.Contains(t, err.Error(), "nope")
+
+	require.NoError(t, mgr.Create("baz", confWithInput("slow_input")))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer done()
+	err = mgr.Delete(ctx, "baz")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStreamTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{err: ErrStreamDoesNotExist, status: http.StatusNotFound},
+		{err: fmt.Errorf("wrapped: %w", ErrStreamExists), status: http.StatusConflict},
+		{err: ErrStreamLimitReached, status: http.StatusTooManyRequests},
+		{err: withErrorKind(ErrStreamConfigInvalid, errors.New("nope")), status: http.StatusBadRequest},
+		{err: withErrorKind(ErrStreamTimeout, context.DeadlineExceeded), status: http.StatusGatewayTimeout},
+		{err: component.ErrTypeClosed, status: http.StatusServiceUnavailable},
+	} {
+		status, _, ok := streamErrorStatus(test.err)
+		assert.True(t, ok, test.err)
+		assert.Equal(t, test.status, status, test.err)
+	}
+
+	_, _, ok := streamErrorStatus(errors.New("nope"))
+	assert.False(t, ok)
+}
+
+func TestTypeValidateConfig(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		require.NoError(t, mgr.Stop(ctx))
+	}()
+
+	require.NoError(t, mgr.ValidateConfig(harmlessConf(t)))
+
+	conf := harmlessConf(t)
+	conf.Output.Type = "nope"
+	err = mgr.Create("foo", conf)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStreamConfigInvalid)
+	assert.EqualError(t, err, "field output: unknown output type 'nope'")
+
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, ErrStreamDoesNotExist)
+
+	nestedConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    mapping: 'root = "hello world"'
+pipeline:
+  processors:
+    - mapping: 'root = content()'
+output:
+  broker:
+    outputs:
+      - drop: {}
+      - drop: {}
+        processors:
+          - type: nope
+`)
+	require.NoError(t, err)
+
+	err = mgr.ValidateConfig(nestedConf)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStreamConfigInvalid)
+	assert.EqualError(t, err, "field output.broker.outputs.1.processors.0: unknown processor type 'nope'")
+
+	// Updates are rejected before the existing stream is removed.
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+	err = mgr.Update(context.Background(), "bar", nestedConf)
+	assert.ErrorIs(t, err, ErrStreamConfigInvalid)
+
+	info, err := mgr.Read("bar")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+}
+
+func TestTypeForEachStream(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	for _, id := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, mgr.Create(id, harmlessConf(t)))
+	}
+
+	var ids []string
+	mgr.ForEachStream(func(id string, status *StreamStatus) bool {
+		assert.True(t, status.IsRunning())
+		ids = append(ids, id)
+		return true
+	})
+	assert.Equal(t, []string{"bar", "baz", "foo"}, ids)
+
+	ids = nil
+	mgr.ForEachStream(func(id string, status *StreamStatus) bool {
+		ids = append(ids, id)
+		return false
+	})
+	assert.Equal(t, []string{"bar"}, ids)
+
+	// The manager lock is not held during the callback.
+	ids = nil
+	mgr.ForEachStream(func(id string, status *StreamStatus) bool {
+		ids = append(ids, id)
+		require.NoError(t, mgr.Delete(ctx, id))
+		return true
+	})
+	assert.Equal(t, []string{"bar", "baz", "foo"}, ids)
+
+	var count int
+	mgr.ForEachStream(func(id string, status *StreamStatus) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 0, count)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeRateLimit(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    count: 200
+    interval: ""
+    mapping: 'root = "hello world"'
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Create("foo", conf, StreamOptRateLimit(20)))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, float64(20), info.RateLimit())
+
+	sent := func() (n int64) {
+		for k, v := range info.Metrics().GetCounters() {
+			if name, _, _ := metrics.ReverseLabelledPath(k); name == "output_sent" {
+				n += v
+			}
+		}
+		return
+	}
+
+	// At 20 messages per second roughly ten messages are consumed within half
+	// a second, with some leeway for slow test environments.
+	time.Sleep(time.Millisecond * 500)
+	assert.Greater(t, sent(), int64(0))
+	assert.LessOrEqual(t, sent(), int64(15))
+
+	// Removing the limit takes effect without restarting the stream.
+	require.NoError(t, mgr.SetRateLimit("foo", 0))
+	assert.Eventually(t, func() bool {
+		return sent() == 200
+	}, time.Second*10, time.Millisecond*50)
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), info.RateLimit())
+
+	assert.Equal(t, ErrStreamDoesNotExist, mgr.SetRateLimit("bar", 10))
+	assert.Error(t, mgr.SetRateLimit("foo", -1))
+}
+
+func TestTypeReconcile(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	for _, id := range []string{"foo", "bar", "baz", "qux"} {
+		require.NoError(t, mgr.Create(id, harmlessConf(t)))
+	}
+
+	before := map[string]*StreamStatus{}
+	for _, id := range []string{"foo", "bar", "baz"} {
+		before[id], err = mgr.Read(id)
+		require.NoError(t, err)
+	}
+
+	// Differs from the running config only by an explicit default value.
+	cosmeticConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+    interval: 1s
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	changedConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    mapping: 'root = "changed"'
+output:
+  drop: {}
+`)
+	require.NoError(t, err)
+
+	result, err := mgr.Reconcile(ctx, map[string]stream.Config{
+		"foo":  harmlessConf(t),
+		"bar":  changedConf,
+		"baz":  cosmeticConf,
+		"quux": harmlessConf(t),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileResult{
+		Created:   []string{"quux"},
+		Updated:   []string{"bar"},
+		Deleted:   []string{"qux"},
+		Unchanged: []string{"baz", "foo"},
+	}, result)
+
+	for _, id := range []string{"foo", "baz"} {
+		info, err := mgr.Read(id)
+		require.NoError(t, err)
+		assert.Same(t, before[id], info, id)
+		assert.True(t, info.IsRunning(), id)
+	}
+
+	info, err := mgr.Read("bar")
+	require.NoError(t, err)
+	assert.NotSame(t, before["bar"], info)
+	assert.True(t, info.IsRunning())
+	assert.Eventually(t, func() bool {
+		return !before["bar"].IsRunning()
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = mgr.Read("qux")
+	assert.Equal(t, ErrStreamDoesNotExist, err)
+
+	_, err = mgr.Read("quux")
+	require.NoError(t, err)
+}
+
+func TestTypeReloadFromDirectory(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	dir := t.TempDir()
+	writeConf := func(name, mapping string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+input:
+  generate:
+    mapping: '`+mapping+`'
+output:
+  drop: {}
+`), 0o644))
+	}
+
+	writeConf("foo.yaml", `root = deleted()`)
+	writeConf("bar.yaml", `root = deleted()`)
+
+	result, err := mgr.ReloadFromDirectory(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileResult{
+		Created: []string{"bar", "foo"},
+	}, result)
+
+	// Streams that were not loaded from the directory are left untouched.
+	require.NoError(t, mgr.Create("api", harmlessConf(t)))
+
+	writeConf("foo.yaml", `root = "changed"`)
+	writeConf("baz.yaml", `root = deleted()`)
+	require.NoError(t, os.Remove(filepath.Join(dir, "bar.yaml")))
+
+	result, err = mgr.ReloadFromDirectory(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileResult{
+		Created: []string{"baz"},
+		Updated: []string{"foo"},
+		Deleted: []string{"bar"},
+	}, result)
+
+	_, err = mgr.Read("api")
+	require.NoError(t, err)
+
+	// A config that fails to parse leaves the running streams untouched.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "qux.yaml"), []byte(`input: [ nope`), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "baz.yaml")))
+
+	_, err = mgr.ReloadFromDirectory(ctx, dir)
+	require.Error(t, err)
+
+	for _, id := range []string{"foo", "baz"} {
+		info, err := mgr.Read(id)
+		require.NoError(t, err, id)
+		assert.True(t, info.IsRunning(), id)
+	}
+}
+
+func TestTypeReloadFromDirectoryReaderOpts(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetStreamReaderOpts(
+		config.OptSetStreamIDSeparator("."),
+	))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0o755))
+
+	fooPath := filepath.Join(dir, "foo", "bar.yaml")
+	require.NoError(t, os.WriteFile(fooPath, []byte(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  drop: {}
+`), 0o644))
+
+	result, err := mgr.ReloadFromDirectory(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileResult{
+		Created: []string{"foo.bar"},
+	}, result)
+
+	// Reloading a single stream resolves the same id from its file.
+	require.NoError(t, mgr.ReloadStream(ctx, "foo.bar"))
+}
+
+func TestTypeBoot(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+	defer func() {
+		assert.NoError(t, mgr.Stop(ctx))
+	}()
+
+	dir := t.TempDir()
+	writeConf := func(name, mapping string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`
+input:
+  generate:
+    mapping: '`+mapping+`'
+output:
+  drop: {}
+`), 0o644))
+	}
+
+	boot := func(mode BootMode) error {
+		rdr := config.NewReader("", nil, config.OptSetStreamPaths(dir))
+		confs := map[string]stream.Config{}
+		_, loadErr := rdr.ReadStreamsLenient(confs)
+		paths := rdr.StreamConfigPaths()
+		return mgr.Boot(ctx, mode, confs, loadErr, func(id string) []StreamOpt {
+			return []StreamOpt{StreamOptOriginDirectory(paths[id])}
+		})
+	}
+
+	assertStreams := func(ids ...string) {
+		t.Helper()
+		var actual []string
+		mgr.ForEachStream(func(id string, _ *StreamStatus) bool {
+			actual = append(actual, id)
+			return true
+		})
+		assert.Equal(t, ids, actual)
+	}
+
+	writeConf("foo.yaml", `root = deleted()`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bar.yaml"), []byte(`input: [ nope`), 0o644))
+
+	// A config that fails to parse aborts a strict boot before any streams
+	// are created.
+	err = boot(BootModeStrict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "bar.yaml"))
+	assertStreams()
+
+	// A config that fails to construct aborts a strict boot, removing the
+	// streams created before it.
+	require.NoError(t, os.Remove(filepath.Join(dir, "bar.yaml")))
+	writeConf("zed.yaml", `root = this.`)
+
+	err = boot(BootModeStrict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream 'zed'")
+	assertStreams()
+
+	// The same configs are booted leniently, where the failure is reported
+	// and the remaining streams run.
+	err = boot(BootModeLenient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream 'zed'")
+	assertStreams("foo")
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+	require.NoError(t, os.Remove(filepath.Join(dir, "zed.yaml")))
+	writeConf("bar.yaml", `root = deleted()`)
+
+	require.NoError(t, boot(BootModeStrict))
+	assertStreams("bar", "foo")
+
+	_, err = ParseBootMode("nope")
+	require.Error(t, err)
+}
+
+func TestTypeDependencies(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	var eventsMut sync.Mutex
+	var started, stopped []string
+	var bStatus *StreamStatus
+	bReadyOnStartA := false
+
+	mgr := New(res, OptAPIEnabled(false), OptSetManualStart(true), OptAddLifecycleHook(func(e LifecycleEvent) {
+		if e.Type != LifecycleEventHealth {
+			return
+		}
+		eventsMut.Lock()
+		defer eventsMut.Unlock()
+		if !e.Status.IsRunning() {
+			stopped = append(stopped, e.ID)
+			return
+		}
+		if slices.Contains(started, e.ID) {
+			return
+		}
+		if e.ID == "a" {
+			bReadyOnStartA = bStatus.IsReady()
+		}
+		started = append(started, e.ID)
+	}))
+
+	// The stream a depends on b, and therefore b must be started first and
+	// stopped last despite the ordering of their ids.
+	require.NoError(t, mgr.Create("a", harmlessConf(t), StreamOptDependsOn("b")))
+	require.NoError(t, mgr.Create("b", harmlessConf(t)))
+
+	bStatus, err = mgr.Read("b")
+	require.NoError(t, err)
+
+	info, err := mgr.Read("a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, info.DependsOn())
+
+	require.NoError(t, mgr.StartAll(ctx))
+
+	eventsMut.Lock()
+	assert.Equal(t, []string{"b", "a"}, started)
+	assert.True(t, bReadyOnStartA)
+	eventsMut.Unlock()
+
+	// Dependency cycles are rejected, whether direct or via an update, and an
+	// update rejected in this way leaves the existing stream running.
+	err = mgr.Create("c", harmlessConf(t), StreamOptDependsOn("c"))
+	assert.ErrorIs(t, err, ErrStreamDependencyCycle)
+
+	err = mgr.Update(ctx, "b", harmlessConf(t), StreamOptDependsOn("a"))
+	assert.ErrorIs(t, err, ErrStreamDependencyCycle)
+	assert.Contains(t, err.Error(), "b -> a -> b")
+
+	info, err = mgr.Read("b")
+	require.NoError(t, err)
+	assert.Same(t, bStatus, info)
+	assert.True(t, info.IsRunning())
+
+	require.NoError(t, mgr.Stop(ctx))
+
+	assert.Eventually(t, func() bool {
+		eventsMut.Lock()
+		defer eventsMut.Unlock()
+		return len(stopped) == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	eventsMut.Lock()
+	assert.Equal(t, []string{"a", "b"}, stopped)
+	eventsMut.Unlock()
+}
+
+func TestTypeRegisterStream(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false))
+
+	in := &mock.Input{TChan: make(chan message.Transaction)}
+	out := &mock.OutputChanneled{}
+	strm, err := stream.NewFromComponents(res.ForStream("foo"), in, []processor.V1{
+		mock.Processor(func(b message.Batch) ([]message.Batch, error) {
+			for _, p := range b {
+				p.SetBytes(bytes.ToUpper(p.AsBytes()))
+			}
+			return []message.Batch{b}, nil
+		}),
+	}, out)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.RegisterStream("foo", strm, StreamOptLabels(map[string]string{"kind": "custom"})))
+	assert.ErrorIs(t, mgr.RegisterStream("foo", strm), ErrStreamExists)
+	require.NoError(t, mgr.Create("bar", harmlessConf(t)))
+
+	resChan := make(chan error, 1)
+	select {
+	case in.TChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	select {
+	case tran := <-out.TChan:
+		assert.Equal(t, "HELLO", string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(ctx, nil))
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	require.NoError(t, <-resChan)
+
+	listed := map[string]string{}
+	mgr.ForEachStream(func(id string, status *StreamStatus) bool {
+		origin, _ := status.Origin()
+		listed[id] = origin
+		return true
+	})
+	assert.Equal(t, map[string]string{
+		"foo": StreamOriginRegistered,
+		"bar": StreamOriginAPI,
+	}, listed)
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"kind": "custom"}, info.Labels())
+
+	// Registered streams cannot be paused, throttled or rebuilt as the manager
+	// did not construct them.
+	assert.ErrorIs(t, mgr.Pause("foo"), ErrStreamRegistered)
+	assert.ErrorIs(t, mgr.SetRateLimit("foo", 10), ErrStreamRegistered)
+	assert.ErrorIs(t, mgr.Scale(ctx, "foo", 2), ErrStreamRegistered)
+	assert.ErrorIs(t, mgr.SwapOutput(ctx, "foo", map[string]any{"drop": map[string]any{}}), ErrStreamRegistered)
+
+	router := mgr.Router()
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("PUT", "/streams/foo/config/output", strings.NewReader(`drop: {}`)))
+	assert.Equal(t, http.StatusConflict, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/streams/export?format=json", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.NotContains(t, response.Body.String(), `"foo"`)
+	assert.Contains(t, response.Body.String(), `"bar"`)
+
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+	_, err = mgr.Read("foo")
+	assert.ErrorIs(t, err, ErrStreamDoesNotExist)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeUnhealthyGracePeriod(t *testing.T) {
+	var connected atomic.Bool
+	connected.Store(true)
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.InputAdd(func(c input.Config, mgr bundle.NewManagement) (input.Streamed, error) {
+		return &gatedMockInput{
+			Input:     &mock.Input{TChan: make(chan message.Transaction)},
+			connected: &connected,
+		}, nil
+	}, docs.ComponentSpec{
+		Name: "gated_input",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	var healthMut sync.Mutex
+	var unhealthyEvents int
+	mgr := New(res,
+		OptAPIEnabled(false),
+		OptSetHealthCheckInterval(time.Millisecond*10),
+		OptSetUnhealthyGracePeriod(time.Millisecond*500),
+		OptAddLifecycleHook(func(e LifecycleEvent) {
+			if e.Type == LifecycleEventHealth && e.Status != nil && e.Status.IsRunning() && !e.Status.IsReady() {
+				healthMut.Lock()
+				unhealthyEvents++
+				healthMut.Unlock()
+			}
+		}),
+	)
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+	countUnhealthy := func() int {
+		healthMut.Lock()
+		defer healthMut.Unlock()
+		return unhealthyEvents
+	}
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		mgr.HandleStreamReady(rec, httptest.NewRequest("GET", "/ready", nil))
+		return rec.Code
+	}
+
+	conf := harmlessConf(t)
+	conf.Input = input.NewConfig()
+	conf.Input.Type = "gated_input"
+	require.NoError(t, mgr.Create("foo", conf))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+
+	// Allow the health loop to observe the stream as connected.
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, StreamStateRunning, info.State())
+
+	// A brief disconnect within the grace period is reported as reconnecting
+	// without the stream becoming unhealthy.
+	connected.Store(false)
+	require.Eventually(t, func() bool {
+		return info.State() == StreamStateReconnecting
+	}, time.Second*5, time.Millisecond*5)
+	assert.True(t, info.IsReconnecting())
+	assert.Equal(t, http.StatusOK, ready())
+
+	connected.Store(true)
+	require.Eventually(t, func() bool {
+		return info.State() == StreamStateRunning
+	}, time.Second*5, time.Millisecond*5)
+	assert.Equal(t, 0, countUnhealthy())
+
+	// A disconnect that outlasts the grace period makes the stream unhealthy.
+	connected.Store(false)
+	require.Eventually(t, func() bool {
+		return countUnhealthy() == 1
+	}, time.Second*5, time.Millisecond*5)
+	assert.Equal(t, StreamStateRunning, info.State())
+	assert.False(t, info.IsReconnecting())
+	assert.Equal(t, http.StatusServiceUnavailable, ready())
+}
+
+func TestTypeChangeNotificationOutput(t *testing.T) {
+	notifyOut := &mock.OutputChanneled{}
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return notifyOut, nil
+	}, docs.ComponentSpec{
+		Name: "notify_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	notifyConf := output.NewConfig()
+	notifyConf.Type = "notify_output"
+
+	mgr := New(res, OptAPIEnabled(false), OptSetChangeNotificationOutput(notifyConf))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+	require.NotNil(t, notifyOut.TChan)
+
+	readNotification := func() (body map[string]any, eventType string) {
+		t.Helper()
+		select {
+		case tran := <-notifyOut.TChan:
+			require.Len(t, tran.Payload, 1)
+			part := tran.Payload.Get(0)
+			require.NoError(t, json.Unmarshal(part.AsBytes(), &body))
+			assert.Equal(t, "foo", part.MetaGetStr("stream_id"))
+			eventType = part.MetaGetStr("event_type")
+			require.NoError(t, tran.Ack(context.Background(), nil))
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for change notification")
+		}
+		return
+	}
+
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	body, eventType := readNotification()
+	assert.Equal(t, LifecycleEventCreated, eventType)
+	assert.Equal(t, "foo", body["id"])
+	assert.Equal(t, LifecycleEventCreated, body["type"])
+	assert.NotEmpty(t, body["time"])
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+
+	body, eventType = readNotification()
+	assert.Equal(t, LifecycleEventDeleted, eventType)
+	assert.Equal(t, "foo", body["id"])
+	assert.Equal(t, LifecycleEventDeleted, body["type"])
+}
+
+func TestTypeDefaultBuffer(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	defaultBuffer, err := testutil.BufferFromYAML(`
+memory:
+  limit: 1000
+`)
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetDefaultBuffer(defaultBuffer))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	require.NoError(t, mgr.Create("unbuffered", harmlessConf(t)))
+
+	info, err := mgr.Read("unbuffered")
+	require.NoError(t, err)
+	assert.Equal(t, defaultBuffer, info.Config().Buffer)
+
+	ownBuffer, err := testutil.BufferFromYAML(`
+memory:
+  limit: 2000
+`)
+	require.NoError(t, err)
+
+	conf := harmlessConf(t)
+	conf.Buffer = ownBuffer
+	require.NoError(t, mgr.Create("buffered", conf))
+
+	info, err = mgr.Read("buffered")
+	require.NoError(t, err)
+	assert.Equal(t, ownBuffer, info.Config().Buffer)
+
+	// Updates of a stream without a buffer are also given the default.
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Update(ctx, "buffered", harmlessConf(t)))
+
+	info, err = mgr.Read("buffered")
+	require.NoError(t, err)
+	assert.Equal(t, defaultBuffer, info.Config().Buffer)
+}
+
+// capturingOutput acknowledges each message that it receives, reporting the
+// payloads of messages on a channel.
+type capturingOutput struct {
+	mock.OutputChanneled
+	payloads chan<- string
+}
+
+func (c *capturingOutput) Consume(ts <-chan message.Transaction) error {
+	go func() {
+		for t := range ts {
+			for _, p := range t.Payload {
+				c.payloads <- string(p.AsBytes())
+			}
+			_ = t.Ack(context.Background(), nil)
+		}
+	}()
+	return nil
+}
+
+func TestTypeGlobalProcessors(t *testing.T) {
+	payloads := make(chan string, 10)
+
+	env := bundle.GlobalEnvironment.Clone()
+	require.NoError(t, env.OutputAdd(func(c output.Config, mgr bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+		return &capturingOutput{payloads: payloads}, nil
+	}, docs.ComponentSpec{
+		Name: "capturing_output",
+	}))
+
+	res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetEnvironment(env))
+	require.NoError(t, err)
+
+	pre, err := testutil.ProcessorFromYAML(`mapping: 'root = content().string() + " pre"'`)
+	require.NoError(t, err)
+	post, err := testutil.ProcessorFromYAML(`mapping: 'root = content().string() + " post"'`)
+	require.NoError(t, err)
+
+	mgr := New(res, OptAPIEnabled(false), OptSetGlobalProcessors([]processor.Config{pre}, []processor.Config{post}))
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+		assert.NoError(t, mgr.Stop(ctx))
+	})
+
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = "hello"'
+pipeline:
+  processors:
+    - mapping: 'root = content().string() + " own"'
+`)
+	require.NoError(t, err)
+	conf.Output = output.NewConfig()
+	conf.Output.Type = "capturing_output"
+
+	readPayload := func() string {
+		t.Helper()
+		select {
+		case p := <-payloads:
+			return p
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for message")
+		}
+		return ""
+	}
+
+	require.NoError(t, mgr.Create("foo", conf))
+	assert.Equal(t, "hello pre own post", readPayload())
+
+	// The global processors are not reflected within the config of the stream.
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Len(t, info.Config().Pipeline.Processors, 1)
+
+	require.NoError(t, mgr.Create("bar", conf, StreamOptGlobalProcessors(false)))
+	assert.Equal(t, "hello own", readPayload())
+}
@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// ServerOptSetUnixSocket sets the path of a Unix domain socket that the server
+// listens on, created with the provided file permissions, which allows local
+// agents to interact with the manager without the API being exposed over a
+// network port. A stale socket left at the path, for example by a process that
+// did not shut down cleanly, is removed, but any other type of file at the path
+// results in an error.
+func ServerOptSetUnixSocket(path string, perm os.FileMode) ServerOpt {
+	return func(c *httpServerConfig) {
+		c.socketPath = path
+		c.socketPerm = perm
+	}
+}
+
+func listenUnixSocket(path string, perm os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("path '%v' already exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
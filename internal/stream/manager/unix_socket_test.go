@@ -0,0 +1,110 @@
+package manager_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bmanager "github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream/manager"
+)
+
+func unixSocketClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+func TestAPIServerUnixSocket(t *testing.T) {
+	// Socket paths have a short length limit and therefore we avoid the
+	// lengthy paths of t.TempDir.
+	dir, err := os.MkdirTemp("", "bento")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	sockPath := filepath.Join(dir, "api.sock")
+
+	// A stale socket is replaced.
+	stale, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+
+	srv, err := mgr.NewAPIServer(manager.ServerOptSetUnixSocket(sockPath, 0o600))
+	require.NoError(t, err)
+
+	info, err := os.Stat(sockPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve()
+	}()
+
+	client := unixSocketClient(sockPath)
+
+	res2, err := client.Post("http://unix/streams/foo", "application/yaml", strings.NewReader(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  drop: {}
+`))
+	require.NoError(t, err)
+	res2.Body.Close()
+	require.Equal(t, http.StatusOK, res2.StatusCode)
+
+	res2, err = client.Get("http://unix/streams")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res2.Body)
+	res2.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"foo"`)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, srv.Shutdown(ctx))
+	require.NoError(t, <-serveErr)
+	require.NoError(t, mgr.Stop(ctx))
+
+	_, err = os.Stat(sockPath)
+	assert.True(t, os.IsNotExist(err), err)
+
+	// Paths occupied by other files are not removed.
+	filePath := filepath.Join(dir, "notasocket")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello"), 0o600))
+
+	_, err = mgr.NewAPIServer(manager.ServerOptSetUnixSocket(filePath, 0o600))
+	require.Error(t, err)
+
+	// A server listens on either an address or a socket.
+	_, err = mgr.NewAPIServer()
+	require.Error(t, err)
+
+	_, err = mgr.NewAPIServer(
+		manager.ServerOptSetAddress("127.0.0.1:0"),
+		manager.ServerOptSetUnixSocket(filepath.Join(dir, "other.sock"), 0o600),
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"math/rand"
+	"time"
+)
+
+// maxUptimeJitter is the fraction of the max uptime of a stream by which its
+// scheduled restarts are brought forward at random, such that streams created
+// together do not all restart at once.
+const maxUptimeJitter = 0.1
+
+// StreamOptMaxUptime sets a duration after which a running stream is restarted
+// gracefully, as a mitigation for components that leak resources over long
+// runtimes. Each restart is scheduled up to a tenth of the duration early at
+// random in order to stagger the restarts of streams started together. A
+// duration of zero disables scheduled restarts, and as with other options the
+// max uptime of a previous version of the stream is retained unless
+// overridden.
+func StreamOptMaxUptime(d time.Duration) StreamOpt {
+	return func(s *StreamStatus) {
+		s.maxUptime = d
+	}
+}
+
+// scheduleRestart returns the time at which a stream started at the provided
+// time is due to be restarted, or the zero time when the stream has no max
+// uptime.
+func scheduleRestart(startedAt time.Time, maxUptime time.Duration) time.Time {
+	if maxUptime <= 0 {
+		return time.Time{}
+	}
+	jitter := time.Duration(rand.Int63n(int64(float64(maxUptime)*maxUptimeJitter) + 1))
+	return startedAt.Add(maxUptime - jitter)
+}
+
+// MaxUptime returns the duration after which the stream is restarted, which is
+// zero when scheduled restarts are disabled.
+func (s *StreamStatus) MaxUptime() time.Duration {
+	return s.maxUptime
+}
+
+// NextRestart returns the time at which the stream is next scheduled to be
+// restarted, the bool returned is false when the stream is not running or has
+// no max uptime.
+func (s *StreamStatus) NextRestart() (time.Time, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.strm == nil || s.closed || s.restartAt.IsZero() {
+		return time.Time{}, false
+	}
+	return s.restartAt, true
+}
+
+// RestartCount returns the number of times that the stream has been restarted
+// by the manager, either because it exceeded its max uptime or because it
+// failed to become ready.
+func (s *StreamStatus) RestartCount() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.restartCount
+}
+
+// checkScheduledRestart is called from the health loop, and gracefully
+// restarts a stream once it has been running for longer than its max uptime.
+func (m *Type) checkScheduledRestart(id string, status *StreamStatus, now time.Time) {
+	if status.origin == StreamOriginRegistered {
+		return
+	}
+	if restartAt, ok := status.NextRestart(); !ok || now.Before(restartAt) {
+		return
+	}
+
+	b := &status.breaker
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if b.open || b.restarting {
+		return
+	}
+	b.restarting = true
+	m.manager.Logger().Info("Restarting stream '%v' as it has exceeded its max uptime of %v\n", id, status.maxUptime)
+	go m.restartStream(id, status, status.getStream())
+}
@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// streamValidation is the outcome of validating an individual stream config.
+type streamValidation struct {
+	Valid bool     `json:"valid"`
+	Lints []string `json:"lints,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// streamConfigFromNode parses a stream config from its YAML form, retaining
+// the generic form of the node as the raw source of the config.
+func (m *Type) streamConfigFromNode(node *yaml.Node) (stream.Config, error) {
+	var rawSource any
+	_ = node.Decode(&rawSource)
+
+	pConf, err := stream.Spec().ParsedConfigFromAny(node)
+	if err != nil {
+		return stream.Config{}, err
+	}
+	return stream.FromParsed(m.manager.Environment(), pConf, rawSource)
+}
+
+// validateStreamConfigNode validates a stream config in the same way as a
+// dry run of creating a stream, where the config is linted unless chilled.
+func (m *Type) validateStreamConfigNode(node *yaml.Node, chilled bool) streamValidation {
+	if _, err := stream.MigrateYAML(node); err != nil {
+		return streamValidation{Error: err.Error()}
+	}
+	if !chilled {
+		if lints := m.lintStreamConfigNode(node); len(lints) > 0 {
+			return streamValidation{Lints: lints}
+		}
+	}
+	conf, err := m.streamConfigFromNode(node)
+	if err == nil {
+		err = m.ValidateConfig(conf)
+	}
+	if err != nil {
+		return streamValidation{Error: err.Error()}
+	}
+	return streamValidation{Valid: true}
+}
+
+// decodeValidationSet reads the stream configs of a validation request, which
+// is either an object of stream configs keyed by stream ids, as accepted when
+// setting streams, or a YAML stream of several documents that are each a
+// stream config, identified by their position within the stream.
+func decodeValidationSet(r *http.Request, body []byte, fn func(id string, node *yaml.Node) error) error {
+	var nodes []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(body))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// Bodies that are not YAML are decoded as a stream set, which
+			// reports the error.
+			nodes = nil
+			break
+		}
+		nodes = append(nodes, &doc)
+	}
+	if len(nodes) <= 1 {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		return decodeStreamSet(r, fn)
+	}
+	for i, doc := range nodes {
+		if err := fn(strconv.Itoa(i), doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleStreamsValidate is an http.HandleFunc for validating a set of stream
+// configs with a POST without creating any streams, such as for checking a
+// directory of configs before it is deployed. Each config is validated as with
+// a dry run of creating a stream, and the response body maps each stream id to
+// whether its config is valid, along with any lint errors or the reason that
+// it is invalid. The status is 400 when any config is invalid.
+func (m *Type) HandleStreamsValidate(w http.ResponseWriter, r *http.Request) {
+	var requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debug("Streams validate request Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	var body []byte
+	if body, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+
+	chilled := r.URL.Query().Get("chilled") == "true"
+	if body, requestErr = config.ReplaceEnvVariables(body, os.LookupEnv); requestErr != nil {
+		var errEnvMissing *config.ErrMissingEnvVars
+		if !chilled || !errors.As(requestErr, &errEnvMissing) {
+			return
+		}
+		body, requestErr = errEnvMissing.BestAttempt, nil
+	}
+
+	report := map[string]streamValidation{}
+	valid := true
+	if requestErr = decodeValidationSet(r, body, func(id string, node *yaml.Node) error {
+		if m.maxStreams > 0 && len(report) >= m.maxStreams {
+			return errStreamSetTooLarge
+		}
+		v := m.validateStreamConfigNode(node, chilled)
+		valid = valid && v.Valid
+		report[id] = v
+		return nil
+	}); requestErr != nil {
+		return
+	}
+
+	resBytes, err := json.Marshal(report)
+	if err != nil {
+		requestErr = err
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !valid {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	_, _ = w.Write(resBytes)
+}
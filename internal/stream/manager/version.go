@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// apiFeatures are identifiers of the optional features supported by the
+// stream manager API, allowing automation that targets managers of differing
+// versions to check for a feature before relying on it.
+var apiFeatures = []string{
+	"async_jobs",
+	"audit",
+	"compression",
+	"component_filters",
+	"config_overrides",
+	"config_sections",
+	"dependencies",
+	"diff",
+	"dry_run",
+	"events",
+	"export_import",
+	"export_tar",
+	"global_processors",
+	"groups",
+	"idempotency",
+	"labels",
+	"list_shapes",
+	"list_sort",
+	"maintenance",
+	"max_uptime",
+	"output_swap",
+	"partial_set",
+	"pause",
+	"quarantine",
+	"rate_limits",
+	"selftest",
+	"stall_detection",
+	"templates",
+	"timeout_config",
+	"validate",
+	"zero_downtime_updates",
+}
+
+// OptSetBuildInfo sets the version and build date of the service, which are
+// typically stamped at build time and are reported by the /version endpoint.
+// When unset the version reported is the engine version of the manager, or
+// otherwise the version found within the build info of the binary.
+func OptSetBuildInfo(version, dateBuilt string) func(*Type) {
+	return func(t *Type) {
+		t.buildVersion = version
+		t.buildDate = dateBuilt
+	}
+}
+
+type versionInfo struct {
+	Version   string   `json:"version"`
+	Built     string   `json:"built"`
+	Commit    string   `json:"commit"`
+	GoVersion string   `json:"go_version"`
+	Features  []string `json:"features"`
+}
+
+func (m *Type) versionInfo() versionInfo {
+	info := versionInfo{
+		Version:   m.buildVersion,
+		Built:     m.buildDate,
+		Commit:    "unknown",
+		GoVersion: runtime.Version(),
+		Features:  apiFeatures,
+	}
+	if info.Version == "" {
+		info.Version = m.manager.EngineVersion()
+	}
+
+	if bInfo, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bInfo.Main.Version != "" && bInfo.Main.Version != "(devel)" {
+			info.Version = bInfo.Main.Version
+		}
+		for _, s := range bInfo.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				if info.Built == "" {
+					info.Built = s.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "unknown"
+	}
+	if info.Built == "" {
+		info.Built = "unknown"
+	}
+	return info
+}
+
+// HandleVersion is an http.HandleFunc for obtaining the version and build info
+// of the service, along with the features supported by the stream manager API.
+func (m *Type) HandleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "verb not supported: "+r.Method, http.StatusBadRequest)
+		return
+	}
+
+	resBytes, err := json.Marshal(m.versionInfo())
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
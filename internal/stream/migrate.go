@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersionField is the name of the optional field of a stream config that
+// declares the version of the layout that the config is written in.
+const SchemaVersionField = "schema_version"
+
+// SchemaVersion is the version of the current layout of stream configs.
+// Configs that do not declare a version are assumed to be of this version.
+const SchemaVersion = 2
+
+// schemaMigrations upgrade stream configs from the layout of a version to the
+// layout of the version that follows it, and are keyed by the version that
+// they upgrade from.
+var schemaMigrations = map[int]func(node *yaml.Node) error{
+	1: migrateFromV1,
+}
+
+// MigrateYAML upgrades a stream config that declares a schema version older
+// than SchemaVersion to the current layout, modifying the node in place. The
+// schema version field is removed, such that the migrated config is of the
+// current layout, and true is returned when the field was present. Configs
+// without a schema version are left untouched, and an error is returned for
+// configs of a version that is not recognised, including those of a version
+// newer than this build supports.
+func MigrateYAML(node *yaml.Node) (bool, error) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return false, nil
+	}
+
+	version := -1
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value != SchemaVersionField {
+			continue
+		}
+		if err := node.Content[i+1].Decode(&version); err != nil {
+			return false, fmt.Errorf("field %v: expected an integer: %w", SchemaVersionField, err)
+		}
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		break
+	}
+	if version == -1 {
+		return false, nil
+	}
+
+	if version > SchemaVersion {
+		return false, fmt.Errorf("stream config schema version %v is newer than the latest version supported by this build (%v)", version, SchemaVersion)
+	}
+	if version < 1 {
+		return false, fmt.Errorf("stream config schema version %v is not recognised", version)
+	}
+	for ; version < SchemaVersion; version++ {
+		if err := schemaMigrations[version](node); err != nil {
+			return false, fmt.Errorf("failed to migrate stream config from schema version %v: %w", version, err)
+		}
+	}
+	return true, nil
+}
+
+// migrateFromV1 upgrades configs of the first schema version, where processors
+// could be listed under a top-level processors field, by moving them ahead of
+// any processors of the pipeline.
+func migrateFromV1(node *yaml.Node) error {
+	var procs *yaml.Node
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == "processors" {
+			procs = node.Content[i+1]
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			break
+		}
+	}
+	if procs == nil {
+		return nil
+	}
+	if procs.Kind != yaml.SequenceNode {
+		return fmt.Errorf("field processors: expected array value, got %v", procs.ShortTag())
+	}
+
+	var pipeline *yaml.Node
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == fieldPipeline {
+			if pipeline = node.Content[i+1]; pipeline.Tag == "!!null" {
+				pipeline = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				node.Content[i+1] = pipeline
+			}
+			break
+		}
+	}
+	if pipeline == nil {
+		pipeline = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fieldPipeline},
+			pipeline,
+		)
+	}
+	if pipeline.Kind != yaml.MappingNode {
+		return fmt.Errorf("field %v: expected object value, got %v", fieldPipeline, pipeline.ShortTag())
+	}
+
+	for i := 0; i < len(pipeline.Content)-1; i += 2 {
+		if pipeline.Content[i].Value != "processors" {
+			continue
+		}
+		existing := pipeline.Content[i+1]
+		if existing.Tag == "!!null" {
+			pipeline.Content[i+1] = procs
+			return nil
+		}
+		if existing.Kind != yaml.SequenceNode {
+			return fmt.Errorf("field %v.processors: expected array value, got %v", fieldPipeline, existing.ShortTag())
+		}
+		existing.Content = append(procs.Content, existing.Content...)
+		return nil
+	}
+	pipeline.Content = append(pipeline.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "processors"},
+		procs,
+	)
+	return nil
+}
@@ -0,0 +1,128 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+func TestMigrateYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		output      string
+		migrated    bool
+		errContains string
+	}{
+		{
+			name: "no version",
+			input: `
+input:
+  stdin: {}
+`,
+			output: `input:
+  stdin: {}
+`,
+		},
+		{
+			name: "current version",
+			input: `
+schema_version: 2
+input:
+  stdin: {}
+`,
+			output: `input:
+  stdin: {}
+`,
+			migrated: true,
+		},
+		{
+			name: "v1 top level processors",
+			input: `
+schema_version: 1
+input:
+  stdin: {}
+processors:
+  - mapping: 'root = content().uppercase()'
+output:
+  stdout: {}
+`,
+			output: `input:
+  stdin: {}
+output:
+  stdout: {}
+pipeline:
+  processors:
+    - mapping: 'root = content().uppercase()'
+`,
+			migrated: true,
+		},
+		{
+			name: "v1 top level processors with pipeline",
+			input: `
+schema_version: 1
+processors:
+  - mapping: 'root = "first"'
+pipeline:
+  threads: 2
+  processors:
+    - mapping: 'root = "second"'
+`,
+			output: `pipeline:
+  threads: 2
+  processors:
+    - mapping: 'root = "first"'
+    - mapping: 'root = "second"'
+`,
+			migrated: true,
+		},
+		{
+			name: "too new",
+			input: `
+schema_version: 3
+input:
+  stdin: {}
+`,
+			errContains: "schema version 3 is newer than the latest version supported by this build (2)",
+		},
+		{
+			name: "not recognised",
+			input: `
+schema_version: 0
+`,
+			errContains: "schema version 0 is not recognised",
+		},
+		{
+			name: "not an integer",
+			input: `
+schema_version: nope
+`,
+			errContains: "field schema_version: expected an integer",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			node, err := docs.UnmarshalYAML([]byte(test.input))
+			require.NoError(t, err)
+
+			migrated, err := stream.MigrateYAML(node)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.migrated, migrated)
+
+			outBytes, err := docs.MarshalYAML(*node)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(outBytes))
+		})
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"net/http"
 	"runtime/pprof"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/input"
 	"github.com/warpstreamlabs/bento/internal/component/output"
@@ -23,14 +25,19 @@ type Type struct {
 	conf Config
 
 	inputLayer    input.Streamed
+	inputProcs    []processor.V1
+	outputWrap    func(output.Streamed) output.Streamed
+	inputStage    processor.Pipeline
 	bufferLayer   buffer.Streamed
 	pipelineLayer processor.Pipeline
 	outputLayer   output.Streamed
 
 	manager bundle.NewManagement
 
-	onClose func()
-	closed  uint32
+	onClose  func()
+	onStop   func()
+	stopOnce sync.Once
+	closed   uint32
 }
 
 // New creates a new stream.Type.
@@ -39,6 +46,7 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 		conf:    conf,
 		manager: mgr,
 		onClose: func() {},
+		onStop:  func() {},
 		closed:  0,
 	}
 	for _, opt := range opts {
@@ -48,6 +56,45 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 		return nil, err
 	}
 
+	t.registerReadyEndpoint()
+	return t, nil
+}
+
+// NewFromComponents creates a new stream.Type from an input and output, along
+// with any processors to apply to messages between them, that have already
+// been constructed rather than from a config. This allows programmatic
+// pipelines and components that cannot be expressed as a config to be run as
+// a stream. The stream takes ownership of the components, which must not have
+// begun consuming already, and the config of the stream is left empty.
+func NewFromComponents(mgr bundle.NewManagement, in input.Streamed, procs []processor.V1, out output.Streamed, opts ...func(*Type)) (*Type, error) {
+	if in == nil || out == nil {
+		return nil, errors.New("a stream requires both an input and an output")
+	}
+	t := &Type{
+		inputLayer:  in,
+		outputLayer: out,
+		manager:     mgr,
+		onClose:     func() {},
+		onStop:      func() {},
+		closed:      0,
+	}
+	if len(procs) > 0 {
+		t.pipelineLayer = pipeline.NewProcessor(procs...)
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.outputWrap != nil {
+		t.outputLayer = t.outputWrap(t.outputLayer)
+	}
+	if err := t.chain(); err != nil {
+		return nil, err
+	}
+	t.registerReadyEndpoint()
+	return t, nil
+}
+
+func (t *Type) registerReadyEndpoint() {
 	healthCheck := func(w http.ResponseWriter, r *http.Request) {
 		inputStatuses := t.inputLayer.ConnectionStatus()
 		inputConnected := inputStatuses.AllActive()
@@ -78,7 +125,6 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 		"Returns 200 OK if all inputs and outputs are connected, otherwise a 503 is returned.",
 		healthCheck,
 	)
-	return t, nil
 }
 
 //------------------------------------------------------------------------------
@@ -90,6 +136,36 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptOnStop sets a closure to be called once when the stream begins to stop,
+// before waiting for any of its components to close. This allows components
+// that hold messages indefinitely, such as whilst a stream is paused, to
+// release them so that the stream can shut down.
+func OptOnStop(onStop func()) func(*Type) {
+	return func(t *Type) {
+		t.onStop = onStop
+	}
+}
+
+// OptAddInputProcessors adds processors that are applied to messages as soon as
+// they are consumed from the input, ahead of any buffer and the processors
+// configured for the stream. This allows the behaviour of a stream to be
+// controlled without modifying its config.
+func OptAddInputProcessors(procs ...processor.V1) func(*Type) {
+	return func(t *Type) {
+		t.inputProcs = append(t.inputProcs, procs...)
+	}
+}
+
+// OptWrapOutput sets a function that wraps the output layer of the stream once
+// it has been constructed, where the wrapper is responsible for delivering
+// messages to the output layer. This allows the delivery of messages to be
+// controlled without modifying the config of the stream.
+func OptWrapOutput(fn func(output.Streamed) output.Streamed) func(*Type) {
+	return func(t *Type) {
+		t.outputWrap = fn
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // IsReady returns a boolean indicating whether both the input and output layers
@@ -98,6 +174,23 @@ func (t *Type) IsReady() bool {
 	return t.inputLayer.ConnectionStatus().AllActive() && t.outputLayer.ConnectionStatus().AllActive()
 }
 
+// ConnectionAddresses returns the addresses or endpoints reported by the input
+// and output layers of the stream, which is only possible for components that
+// implement component.ConnectionAddresser.
+func (t *Type) ConnectionAddresses() (inputAddrs, outputAddrs []string) {
+	return component.ConnectionAddressesOf(t.inputLayer), component.ConnectionAddressesOf(t.outputLayer)
+}
+
+// BufferDepth returns how full the buffer of the stream is. The bool returned
+// is false when the stream has no buffer or the buffer is unable to report its
+// depth.
+func (t *Type) BufferDepth() (buffer.Depth, bool) {
+	if dr, ok := t.bufferLayer.(buffer.DepthReporter); ok {
+		return dr.BufferDepth()
+	}
+	return buffer.Depth{}, false
+}
+
 func (t *Type) start() (err error) {
 	// Constructors
 	iMgr := t.manager.IntoPath("input")
@@ -120,11 +213,25 @@ func (t *Type) start() (err error) {
 	if t.outputLayer, err = oMgr.NewOutput(t.conf.Output); err != nil {
 		return
 	}
+	if t.outputWrap != nil {
+		t.outputLayer = t.outputWrap(t.outputLayer)
+	}
+	return t.chain()
+}
 
-	// Start chaining components
+// chain connects the layers of the stream, which must have been constructed,
+// and starts the flow of messages through them.
+func (t *Type) chain() (err error) {
 	var nextTranChan <-chan message.Transaction
 
 	nextTranChan = t.inputLayer.TransactionChan()
+	if len(t.inputProcs) > 0 {
+		t.inputStage = pipeline.NewProcessor(t.inputProcs...)
+		if err = t.inputStage.Consume(nextTranChan); err != nil {
+			return
+		}
+		nextTranChan = t.inputStage.TransactionChan()
+	}
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
@@ -159,10 +266,16 @@ func (t *Type) start() (err error) {
 // proxy. This should guarantee that all in-flight and buffered data is resolved
 // before shutting down.
 func (t *Type) StopGracefully(ctx context.Context) (err error) {
+	t.stopOnce.Do(t.onStop)
 	t.inputLayer.TriggerStopConsuming()
 	if err = t.inputLayer.WaitForClose(ctx); err != nil {
 		return
 	}
+	if t.inputStage != nil {
+		if err = t.inputStage.WaitForClose(ctx); err != nil {
+			return
+		}
+	}
 
 	// If we have a buffer then wait right here. We want to try and allow the
 	// buffer to empty out before prompting the other layers to shut down.
@@ -190,7 +303,11 @@ func (t *Type) StopGracefully(ctx context.Context) (err error) {
 // the stream to gracefully wind down in the order of component layers. This
 // should only be attempted if both stopGracefully and stopOrdered failed.
 func (t *Type) StopUnordered(ctx context.Context) (err error) {
+	t.stopOnce.Do(t.onStop)
 	t.inputLayer.TriggerCloseNow()
+	if t.inputStage != nil {
+		t.inputStage.TriggerCloseNow()
+	}
 	if t.bufferLayer != nil {
 		t.bufferLayer.TriggerCloseNow()
 	}
@@ -203,6 +320,12 @@ func (t *Type) StopUnordered(ctx context.Context) (err error) {
 		return
 	}
 
+	if t.inputStage != nil {
+		if err = t.inputStage.WaitForClose(ctx); err != nil {
+			return
+		}
+	}
+
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.WaitForClose(ctx); err != nil {
 			return
@@ -104,6 +104,13 @@ func (a *airGapBatchBuffer) Read(ctx context.Context) (message.Batch, buffer.Ack
 	}, nil
 }
 
+func (a *airGapBatchBuffer) BufferDepth() (buffer.Depth, bool) {
+	if dr, ok := a.b.(buffer.DepthReporter); ok {
+		return dr.BufferDepth()
+	}
+	return buffer.Depth{}, false
+}
+
 func (a *airGapBatchBuffer) EndOfInput() {
 	a.b.EndOfInput()
 }
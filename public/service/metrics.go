@@ -315,6 +315,14 @@ func (m *airGapMetrics) HandlerFunc() http.HandlerFunc {
 	return nil
 }
 
+func (m *airGapMetrics) DeleteLabel(name, value string) {
+	if ld, ok := m.airGapped.(interface {
+		DeleteLabel(name, value string)
+	}); ok {
+		ld.DeleteLabel(name, value)
+	}
+}
+
 func (m *airGapMetrics) Close() error {
 	return m.airGapped.Close(context.Background())
 }